@@ -0,0 +1,8 @@
+package openapi
+
+import _ "embed"
+
+// Spec - OpenAPI 3.1 спецификация HTTP API цитат
+//
+//go:embed openapi.json
+var Spec []byte
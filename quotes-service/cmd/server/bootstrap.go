@@ -0,0 +1,38 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"time"
+
+	"quotes-service/internal/infrastructure/database"
+	"quotes-service/internal/infrastructure/logger"
+	"quotes-service/internal/repository/postgres"
+	"quotes-service/internal/service"
+)
+
+// bootstrapAdminKey выпускает первый admin-ключ и печатает его в stdout.
+// Если активный admin-ключ уже есть, команда завершается ошибкой: дальнейшие
+// ключи выпускаются через POST /v1/admin/api-keys
+func bootstrapAdminKey(db *database.Cluster, logger *logger.Logger, args []string) error {
+	flags := flag.NewFlagSet("bootstrap-admin-key", flag.ContinueOnError)
+	name := flags.String("name", "bootstrap-admin", "name of the admin key")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	keys := service.NewAPIKeyService(postgres.NewAPIKeyRepository(db, logger), logger)
+	key, raw, err := keys.BootstrapAdminKey(ctx, *name)
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(os.Stderr, "Admin API key %q (id %d) issued; it will not be shown again:\n", key.Name, key.ID)
+	fmt.Fprintln(os.Stdout, raw)
+	return nil
+}
@@ -2,33 +2,115 @@ package main
 
 import (
 	"context"
+	"errors"
+	"flag"
+	"fmt"
 	"log"
+	"net"
 	"net/http"
 	"os"
 	"os/signal"
+	"strings"
+	"sync"
 	"syscall"
 	"time"
 
+	"quotes-service/internal/auth"
+	"quotes-service/internal/buildinfo"
 	"quotes-service/internal/config"
+	"quotes-service/internal/domain"
+	"quotes-service/internal/graph"
 	"quotes-service/internal/handler"
+	"quotes-service/internal/health"
 	"quotes-service/internal/infrastructure/database"
 	"quotes-service/internal/infrastructure/logger"
+	"quotes-service/internal/metrics"
+	"quotes-service/internal/outbox"
+	"quotes-service/internal/ratelimit"
+	"quotes-service/internal/repository/cache"
 	"quotes-service/internal/repository/postgres"
+	"quotes-service/internal/rpc"
 	"quotes-service/internal/service"
+	"quotes-service/internal/stream"
+	"quotes-service/internal/tlsconfig"
+	"quotes-service/internal/tracing"
+	"quotes-service/internal/webhook"
 
 	"github.com/gorilla/mux"
+	"google.golang.org/grpc"
 )
 
 func main() {
-	// Загрузка конфигурации
-	cfg := config.Load()
+	// Команды: quotes-service [флаги], quotes-service config print [флаги],
+	// quotes-service bootstrap-admin-key [-name имя]
+	command, args := "", os.Args[1:]
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		command, args = args[0], args[1:]
+	}
+	configArgs := args
+	switch command {
+	case "":
+	case "config":
+		if len(args) == 0 || args[0] != "print" {
+			fmt.Fprintln(os.Stderr, "usage: quotes-service config print [flags]")
+			os.Exit(2)
+		}
+		configArgs = args[1:]
+	case "bootstrap-admin-key":
+		// Флаги команды разбирает bootstrapAdminKey, конфигурация - из окружения и CONFIG_FILE
+		configArgs = nil
+	default:
+		fmt.Fprintf(os.Stderr, "unknown command %q\n", command)
+		os.Exit(2)
+	}
+
+	// Загрузка конфигурации: флаги > окружение > файл > значения по умолчанию
+	cfg, err := config.Load(configArgs)
+	if errors.Is(err, flag.ErrHelp) {
+		return
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Invalid configuration:\n%v\n", err)
+		os.Exit(2)
+	}
+	if command == "config" {
+		if err := cfg.Print(os.Stdout); err != nil {
+			log.Fatalf("Failed to print configuration: %v", err)
+		}
+		return
+	}
 
 	// Иниициализация логгера
-	logger := logger.New(cfg.LogLevel)
-	logger.Info("Starting quotes service", "version", "1.0.0")
+	logger, err := logger.NewWithConfig(cfg.Logging)
+	if err != nil {
+		log.Fatalf("Failed to configure logger: %v", err)
+	}
+	defer logger.Close()
+	build := buildinfo.Get()
+	logger.Info("Starting quotes service",
+		"version", build.Version,
+		"commit", build.Commit,
+		"build_time", build.BuildTime,
+	)
+
+	// Трассировка OpenTelemetry; traceparent принимается при любом экспортере
+	shutdownTracing, err := tracing.Setup(context.Background(), cfg.Tracing, build.Version)
+	if err != nil {
+		log.Fatalf("Failed to configure tracing: %v", err)
+	}
+	defer func() {
+		ctx, cancel := context.WithTimeout(context.Background(), cfg.Server.ShutdownTimeout)
+		defer cancel()
+		if err := shutdownTracing(ctx); err != nil {
+			logger.Error("Error flushing traces", "error", err)
+		}
+	}()
+	if cfg.Tracing.Exporter != tracing.ExporterNone {
+		logger.Info("Tracing enabled", "exporter", cfg.Tracing.Exporter, "sample_ratio", cfg.Tracing.SampleRatio)
+	}
 
 	// Инициализация базы данных с connection pool
-	db, err := database.NewPostgresConnection(cfg.DatabaseConfig)
+	db, err := database.NewPostgresCluster(cfg.DatabaseConfig, logger)
 	if err != nil {
 		log.Fatalf("Failed to connect to database: %v", err)
 	}
@@ -38,54 +120,427 @@ func main() {
 		}
 	}()
 
-	logger.Info("Database connection established")
+	logger.Info("Database connection established",
+		"replicas", len(cfg.DatabaseConfig.ReplicaURLs),
+		"healthy_replicas", db.HealthyReplicas(),
+	)
+
+	// Первый admin-ключ: quotes-service bootstrap-admin-key [-name имя]
+	if command == "bootstrap-admin-key" {
+		if err := bootstrapAdminKey(db, logger, args); err != nil {
+			logger.Error("Failed to bootstrap admin key", "error", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	// Фоновые задачи останавливаются до закрытия БД
+	bgCtx, stopBackground := context.WithCancel(context.Background())
+	var background sync.WaitGroup
+	defer func() {
+		stopBackground()
+		background.Wait()
+	}()
+
+	// Фоновая проверка реплик
+	background.Add(1)
+	go func() {
+		defer background.Done()
+		db.RunHealthChecks(bgCtx, cfg.DatabaseConfig.ReplicaCheckInterval)
+	}()
 
 	// Инициализация репозитория
-	quoteRepo := postgres.NewQuoteRepository(db, logger)
+	var quoteRepo domain.QuoteRepository = postgres.NewQuoteRepository(db, logger)
+	if cfg.CacheConfig.Enabled {
+		store := cache.NewLRUStore(cfg.CacheConfig.Size)
+		quoteRepo = cache.NewQuoteRepository(quoteRepo, store, cfg.CacheConfig.TTL, cfg.CacheConfig.LoadTimeout, logger)
+		logger.Info("Quote cache enabled", "size", cfg.CacheConfig.Size, "ttl", cfg.CacheConfig.TTL.String())
+	}
+
+	// Outbox и релей доменных событий
+	outboxRepo := postgres.NewOutboxRepository(db, logger)
+	sinks, err := newOutboxSinks(cfg.OutboxConfig, logger)
+	if err != nil {
+		log.Fatalf("Failed to configure outbox: %v", err)
+	}
+
+	// Вебхуки получают события через релей, то есть только после коммита
+	webhookRepo := postgres.NewWebhookRepository(db, logger)
+	dispatcher := webhook.NewDispatcher(webhookRepo, cfg.WebhookConfig, logger)
+	sinks = append(sinks, dispatcher)
+
+	background.Add(1)
+	go func() {
+		defer background.Done()
+		dispatcher.Run(bgCtx)
+	}()
+
+	relay := outbox.NewRelay(outboxRepo, sinks, outbox.RelayConfig{
+		Interval:   cfg.OutboxConfig.RelayInterval,
+		BatchSize:  cfg.OutboxConfig.BatchSize,
+		MaxBackoff: cfg.OutboxConfig.MaxBackoff,
+		Lease:      cfg.OutboxConfig.Lease,
+	}, logger)
+
+	background.Add(1)
+	go func() {
+		defer background.Done()
+		relay.Run(bgCtx)
+	}()
+
+	// Брокер событий для потоковых клиентов получает события из outbox,
+	// поэтому видит изменения, сделанные через любой инстанс
+	broker := stream.NewBroker(cfg.StreamConfig.ReplaySize, cfg.StreamConfig.ClientBuffer)
+	follower := outbox.NewFollower(outboxRepo, []domain.EventListener{broker}, outbox.FollowerConfig{
+		Interval:   cfg.StreamConfig.PollInterval,
+		BatchSize:  cfg.OutboxConfig.BatchSize,
+		GapTimeout: cfg.StreamConfig.GapTimeout,
+	}, logger)
+
+	background.Add(1)
+	go func() {
+		defer background.Done()
+		follower.Run(bgCtx)
+	}()
 
 	// Инициализация сервиса
-	quoteService := service.NewQuoteService(quoteRepo, logger)
+	serviceOptions := []service.Option{
+		service.WithOutbox(postgres.NewTransactor(db), outboxRepo),
+		service.WithTimeouts(cfg.QuoteTimeouts),
+	}
+
+	// Метрики Prometheus: HTTP, бизнес-операции и пулы соединений с БД
+	var metricsRegistry *metrics.Registry
+	if cfg.Metrics.Enabled {
+		metricsRegistry = metrics.NewRegistry()
+		if err := metricsRegistry.RegisterDBPools(db.Pools()); err != nil {
+			log.Fatalf("Failed to register database metrics: %v", err)
+		}
+		serviceOptions = append(serviceOptions, service.WithMetrics(metricsRegistry))
+	}
+
+	quoteService := service.NewQuoteService(quoteRepo, logger, serviceOptions...)
+
+	// Аутентификация: API-ключи и, если задан JWKS, JWT корпоративного SSO
+	apiKeyService := service.NewAPIKeyService(postgres.NewAPIKeyRepository(db, logger), logger)
+	apiKeyService.SetTimeouts(cfg.QuoteTimeouts)
+	var tokenVerifier *auth.TokenVerifier
+	if cfg.JWTConfig.JWKS != "" {
+		keySet, err := auth.LoadKeySet(bgCtx, cfg.JWTConfig.JWKS, logger)
+		if err != nil {
+			log.Fatalf("Failed to load JWKS: %v", err)
+		}
+		tokenVerifier = auth.NewTokenVerifier(keySet, cfg.JWTConfig)
+
+		background.Add(1)
+		go func() {
+			defer background.Done()
+			keySet.Run(bgCtx, cfg.JWTConfig.RefreshInterval)
+		}()
+		logger.Info("JWT authentication enabled", "jwks", cfg.JWTConfig.JWKS, "issuer", cfg.JWTConfig.Issuer)
+	}
+	authenticator := auth.NewAuthenticator(apiKeyService, tokenVerifier, auth.WithCertificates(cfg.TLS.Clients))
+
+	// Лимиты работают после аутентификации, чтобы клиентом был ключ или
+	// пользователь; до нее ограничиваются только неудачные попытки с одного IP.
+	// Лимитер подключен всегда, чтобы RATE_LIMIT_ENABLED можно было
+	// переключить без перезапуска
+	rateLimiter := handler.NewRateLimiter(ratelimit.NewLimiter(cfg.RateLimit.Limits.MaxClients), rateLimitConfig(cfg), logger)
+	if cfg.RateLimit.Enabled {
+		logger.Info("Rate limiting enabled", "rules", len(cfg.RateLimit.Limits.Rules))
+	}
+
+	authMiddleware := handler.NewAuthenticator(authenticator, handler.AuthConfig{
+		Enabled:      cfg.AuthConfig.Enabled,
+		ProtectReads: cfg.AuthConfig.ProtectReads,
+	}, logger, handler.WithFailureLimit(rateLimiter))
+	if !cfg.AuthConfig.Enabled {
+		logger.Warn("Authentication is disabled")
+	}
 
 	// Инициализация хендлера
 	quoteHandler := handler.NewQuoteHandler(quoteService, logger)
+	apiKeyHandler := handler.NewAPIKeyHandler(apiKeyService, authMiddleware, logger)
+	webhookHandler := handler.NewWebhookHandler(service.NewWebhookService(webhookRepo, logger), authMiddleware, logger)
+	streamHandler := handler.NewStreamHandler(broker, cfg.StreamConfig.HeartbeatInterval, logger)
+	socketHandler := handler.NewSocketHandler(quoteService, broker, handler.SocketConfig{
+		MaxConnections:    cfg.SocketConfig.MaxConnections,
+		SendBuffer:        cfg.SocketConfig.SendBuffer,
+		PingInterval:      cfg.SocketConfig.PingInterval,
+		PongWait:          2 * cfg.SocketConfig.PingInterval,
+		MinRandomInterval: cfg.SocketConfig.MinRandomInterval,
+	}, logger)
+
+	graphExecutor, err := graph.NewExecutor(quoteService, cfg.GraphQLConfig, logger)
+	if err != nil {
+		log.Fatalf("Failed to build GraphQL schema: %v", err)
+	}
+	graphqlHandler := handler.NewGraphQLHandler(graphExecutor, authMiddleware, rateLimiter, logger)
+	docsHandler := handler.NewDocsHandler(logger)
 
 	// Настройки маршрутизатора
 	router := mux.NewRouter()
-	quoteHandler.RegisterRoutes(router)
+	quoteHandler.RegisterMiddleware(router)
+	quoteHandler.RegisterHealthRoutes(router)
+
+	// Проверки для /livez, /readyz и /startupz; liveness не зависит от БД,
+	// чтобы сбой базы не приводил к перезапуску инстансов
+	healthRegistry := health.NewRegistry(cfg.Health.CheckTimeout)
+	healthRegistry.Register("database", health.Database(db), health.Readiness, health.Startup)
+	healthRegistry.Register("migrations", health.Migrations(func(ctx context.Context) ([]string, error) {
+		return postgres.MissingMigrations(ctx, db)
+	}), health.Readiness, health.Startup)
+	healthRegistry.Register("cache", health.Cache(quoteService.CacheStats), health.Readiness)
+	healthRegistry.RegisterOptional("outbox", health.OutboxLag(outboxRepo, cfg.Health.OutboxMaxLag), health.Readiness)
+	handler.NewProbeHandler(healthRegistry, logger).RegisterRoutes(router)
+
+	idempotencyService := service.NewIdempotencyService(postgres.NewIdempotencyRepository(db, logger), service.IdempotencyConfig{
+		Window:      cfg.Idempotency.Window,
+		LockTimeout: cfg.Idempotency.LockTimeout,
+	}, logger)
+	idempotencyService.SetTimeouts(cfg.QuoteTimeouts)
+	background.Add(1)
+	go func() {
+		defer background.Done()
+		idempotencyService.RunCleanup(bgCtx, cfg.Idempotency.CleanupInterval)
+	}()
+
+	// Ключи идемпотентности, как и лимиты, привязаны к автору запроса
+	apiMiddleware := []mux.MiddlewareFunc{
+		authMiddleware.Middleware,
+		rateLimiter.Middleware,
+		handler.NewIdempotency(idempotencyService, rateLimiter, logger).Middleware,
+	}
+
+	// Дедлайн запроса ставится до аутентификации, чтобы в него попал и поиск
+	// ключа в БД. SSE-поток живет, пока подключен клиент, и монтируется без него
+	requestTimeouts := handler.NewTimeouts(cfg.Timeouts)
+
+	// REST API монтируется под /v1; новая версия добавляется отдельным APIVersion
+	v1 := handler.APIVersion{
+		Name:       "v1",
+		Handlers:   []handler.RouteRegistrar{quoteHandler, webhookHandler},
+		Middleware: append([]mux.MiddlewareFunc{requestTimeouts.Middleware}, apiMiddleware...),
+	}
+	v1Stream := handler.APIVersion{
+		Name:       "v1",
+		Handlers:   []handler.RouteRegistrar{streamHandler},
+		Middleware: apiMiddleware,
+	}
+	handler.MountVersions(router, v1, v1Stream)
+	if cfg.APIConfig.LegacyRoutes {
+		deprecation := handler.Deprecation{
+			Since:  cfg.APIConfig.DeprecatedSince,
+			Sunset: cfg.APIConfig.Sunset,
+		}
+		handler.MountDeprecatedAliases(router, v1, deprecation)
+		handler.MountDeprecatedAliases(router, v1Stream, deprecation)
+	}
+
+	// Админские маршруты появились уже с версией, алиасов без /v1 у них нет
+	handler.MountVersions(router, handler.APIVersion{
+		Name:       "v1",
+		Handlers:   []handler.RouteRegistrar{apiKeyHandler, handler.NewLogLevelHandler(authMiddleware, logger)},
+		Middleware: []mux.MiddlewareFunc{requestTimeouts.Middleware},
+	})
+
+	handler.Mount(router, "", []handler.RouteRegistrar{socketHandler}, authMiddleware.Middleware)
+	handler.Mount(router, "", []handler.RouteRegistrar{graphqlHandler}, requestTimeouts.Middleware)
+	docsHandler.RegisterRoutes(router)
+
+	var rootHandler http.Handler = router
+	if metricsRegistry != nil {
+		router.Handle(cfg.Metrics.Path, metricsRegistry.Handler()).Methods("GET")
+		rootHandler = handler.InstrumentRouter(router, metricsRegistry)
+		logger.Info("Prometheus metrics enabled", "path", cfg.Metrics.Path)
+	}
+
+	// Span запроса открывается снаружи, чтобы в него попали и метрики, и 404
+	httpTracing := handler.NewTracing()
+	router.Use(httpTracing.RouteMiddleware)
+	rootHandler = httpTracing.Handler(rootHandler)
+	rootHandler = handler.RequestID(rootHandler)
 
 	// Настройка сервера с тайм-аутами
 	server := &http.Server{
-		Addr:         cfg.ServerAddress,
-		Handler:      router,
-		ReadTimeout:  15 * time.Second,
-		WriteTimeout: 15 * time.Second,
-		IdleTimeout:  60 * time.Second,
+		Addr:              cfg.ServerAddress,
+		Handler:           rootHandler,
+		ReadTimeout:       cfg.Server.ReadTimeout,
+		ReadHeaderTimeout: cfg.Server.ReadHeaderTimeout,
+		WriteTimeout:      cfg.Server.WriteTimeout,
+		IdleTimeout:       cfg.Server.IdleTimeout,
+		MaxHeaderBytes:    cfg.Server.MaxHeaderBytes,
+	}
+	server.RegisterOnShutdown(socketHandler.Close)
+
+	// TLS: сертификаты перечитываются с диска без перезапуска; HTTP/2
+	// согласуется через ALPN, WebSocket остается на HTTP/1.1
+	if cfg.TLS.Files.Enabled() {
+		certificates, err := tlsconfig.Load(cfg.TLS.Files, logger)
+		if err != nil {
+			log.Fatalf("Failed to load TLS certificates: %v", err)
+		}
+		server.TLSConfig = certificates.TLSConfig()
+
+		background.Add(1)
+		go func() {
+			defer background.Done()
+			certificates.Run(bgCtx, cfg.TLS.ReloadInterval)
+		}()
 	}
 
 	// Запуск сервера в отдельной горутине
-	serverError := make(chan error, 1)
+	serverError := make(chan error, 4)
 	go func() {
+		if server.TLSConfig != nil {
+			logger.Info("HTTPS server starting", "address", cfg.ServerAddress, "client_auth", cfg.TLS.Files.ClientAuth)
+			serverError <- server.ListenAndServeTLS("", "")
+			return
+		}
 		logger.Info("HTTP server starting", "address", cfg.ServerAddress)
 		serverError <- server.ListenAndServe()
 	}()
 
+	// Листенер HTTP только перенаправляет на HTTPS
+	var redirectServer *http.Server
+	if cfg.TLS.RedirectAddress != "" {
+		redirectServer = &http.Server{
+			Addr:              cfg.TLS.RedirectAddress,
+			Handler:           handler.HTTPSRedirect(cfg.ServerAddress),
+			ReadHeaderTimeout: cfg.Server.ReadHeaderTimeout,
+			IdleTimeout:       cfg.Server.IdleTimeout,
+		}
+
+		go func() {
+			logger.Info("HTTPS redirect server starting", "address", cfg.TLS.RedirectAddress)
+			if err := redirectServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+				serverError <- fmt.Errorf("redirect server: %w", err)
+			}
+		}()
+	}
+
+	// SIGHUP перечитывает конфигурацию: уровень логов, лимиты и тайм-ауты
+	// применяются на лету, остальные ключи требуют перезапуска
+	reloader := config.NewReloader(configArgs, cfg, logger)
+	reloader.OnChange([]string{"LOG_LEVEL"}, func(next *config.Config) {
+		_ = logger.SetLevel(next.Logging.Level)
+	})
+	reloader.OnChange([]string{"RATE_LIMIT_ENABLED", "RATE_LIMIT_RULES", "RATE_LIMIT_TRUSTED_PROXIES", "RATE_LIMIT_AUTH_FAILURES"}, func(next *config.Config) {
+		rateLimiter.Update(rateLimitConfig(next))
+	})
+	reloader.OnChange([]string{"HEALTH_CHECK_TIMEOUT"}, func(next *config.Config) {
+		healthRegistry.SetTimeout(next.Health.CheckTimeout)
+	})
+	reloader.OnChange([]string{"HTTP_REQUEST_TIMEOUT", "HTTP_ROUTE_TIMEOUTS"}, func(next *config.Config) {
+		requestTimeouts.Update(next.Timeouts)
+	})
+	reloader.OnChange([]string{
+		"QUOTE_TIMEOUT_CREATE", "QUOTE_TIMEOUT_GET", "QUOTE_TIMEOUT_LIST", "QUOTE_TIMEOUT_RANDOM",
+		"QUOTE_TIMEOUT_UPDATE", "QUOTE_TIMEOUT_DELETE", "QUOTE_TIMEOUT_HEALTH",
+		"APIKEY_TIMEOUT", "IDEMPOTENCY_TIMEOUT",
+	}, func(next *config.Config) {
+		quoteService.SetTimeouts(next.QuoteTimeouts)
+		apiKeyService.SetTimeouts(next.QuoteTimeouts)
+		idempotencyService.SetTimeouts(next.QuoteTimeouts)
+	})
+	// Пауза перед остановкой и ее тайм-аут читаются из reloader.Current()
+	reloader.OnChange([]string{"HEALTH_SHUTDOWN_DELAY", "SERVER_SHUTDOWN_TIMEOUT"}, func(*config.Config) {})
+
+	// Отладочный листенер: pprof, runtime, пулы БД и конфигурация без секретов
+	var debugServer *http.Server
+	if cfg.Debug.Enabled {
+		debugRouter := mux.NewRouter()
+		handler.NewDebugHandler(db.Pools(), func() interface{} { return reloader.Current().Redacted() }, logger).RegisterRoutes(debugRouter)
+		// WriteTimeout не задан: профиль CPU и trace пишутся дольше обычного ответа
+		debugServer = &http.Server{
+			Addr:              cfg.Debug.Address,
+			Handler:           debugRouter,
+			ReadHeaderTimeout: cfg.Server.ReadHeaderTimeout,
+		}
+
+		go func() {
+			logger.Warn("Debug server starting", "address", cfg.Debug.Address)
+			if err := debugServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+				serverError <- fmt.Errorf("debug server: %w", err)
+			}
+		}()
+	}
+
+	// gRPC API на отдельном порту; пустой GRPC_ADDRESS отключает его
+	var grpcServer *rpc.Server
+	if cfg.GRPCAddress != "" {
+		listener, err := net.Listen("tcp", cfg.GRPCAddress)
+		if err != nil {
+			log.Fatalf("Failed to listen gRPC address: %v", err)
+		}
+
+		var grpcOptions []grpc.ServerOption
+		if cfg.AuthConfig.Enabled {
+			grpcOptions = append(grpcOptions, grpc.ChainUnaryInterceptor(
+				rpc.AuthUnaryInterceptor(authenticator, cfg.AuthConfig.ProtectReads),
+			))
+		}
+		grpcServer = rpc.NewServer(quoteService, logger, grpcOptions...)
+
+		background.Add(1)
+		go func() {
+			defer background.Done()
+			grpcServer.RunHealthChecks(bgCtx, cfg.DatabaseConfig.ReplicaCheckInterval)
+		}()
+
+		go func() {
+			logger.Info("gRPC server starting", "address", cfg.GRPCAddress)
+			if err := grpcServer.Serve(listener); err != nil {
+				serverError <- fmt.Errorf("gRPC server: %w", err)
+			}
+		}()
+	}
+
 	// Ожидание сигнала прерывания или ошибки сервера
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
+	reload := make(chan os.Signal, 1)
+	signal.Notify(reload, syscall.SIGHUP)
 
-	select {
-	case err := <-serverError:
-		logger.Error("Server error", "error", err)
-	case sig := <-quit:
-		logger.Info("Received shutdown signal", "signal", sig.String())
+wait:
+	for {
+		select {
+		case <-reload:
+			// Ошибка уже залогирована, сервис продолжает работать со старой конфигурацией
+			_ = reloader.Reload()
+		case err := <-serverError:
+			logger.Error("Server error", "error", err)
+			break wait
+		case sig := <-quit:
+			logger.Info("Received shutdown signal", "signal", sig.String())
+			break wait
+		}
 	}
 
-	// Graceful shutdown
+	// Graceful shutdown: сначала readiness перестает проходить
 	logger.Info("Shutting down server...")
+	healthRegistry.SetShuttingDown()
+	if delay := reloader.Current().Health.ShutdownDelay; delay > 0 {
+		time.Sleep(delay)
+	}
 
-	shutdownCtx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), reloader.Current().Server.ShutdownTimeout)
 	defer cancel()
 
+	if grpcServer != nil {
+		grpcServer.Shutdown(shutdownCtx)
+	}
+	if debugServer != nil {
+		if err := debugServer.Shutdown(shutdownCtx); err != nil {
+			logger.Error("Debug server shutdown error", "error", err)
+		}
+	}
+	if redirectServer != nil {
+		if err := redirectServer.Shutdown(shutdownCtx); err != nil {
+			logger.Error("Redirect server shutdown error", "error", err)
+		}
+	}
+
 	if err := server.Shutdown(shutdownCtx); err != nil {
 		logger.Error("Server shutdown error", "error", err)
 		os.Exit(1)
@@ -93,3 +548,42 @@ func main() {
 
 	logger.Info("Server shutdown completed")
 }
+
+// rateLimitConfig: выключенный лимитер - лимитер без правил
+func rateLimitConfig(cfg *config.Config) handler.RateLimitConfig {
+	if !cfg.RateLimit.Enabled {
+		return handler.RateLimitConfig{}
+	}
+	return handler.RateLimitConfig{
+		Rules:          cfg.RateLimit.Limits.Rules,
+		TrustedProxies: cfg.RateLimit.TrustedProxies,
+		AuthFailures:   cfg.RateLimit.AuthFailures,
+	}
+}
+
+func newOutboxSinks(cfg config.OutboxConfig, logger *logger.Logger) ([]outbox.Sink, error) {
+	var sinks []outbox.Sink
+	for _, name := range cfg.Sinks {
+		switch name {
+		case "log":
+			sinks = append(sinks, outbox.NewLogSink(logger))
+		case "webhook":
+			if cfg.WebhookURL == "" {
+				return nil, fmt.Errorf("OUTBOX_WEBHOOK_URL is required for webhook sink")
+			}
+			sinks = append(sinks, outbox.NewWebhookSink(cfg.WebhookURL, cfg.PublishTimeout))
+		case "nats":
+			if cfg.NATSURL == "" {
+				return nil, fmt.Errorf("OUTBOX_NATS_URL is required for nats sink")
+			}
+			sink, err := outbox.NewNATSSink(cfg.NATSURL, cfg.NATSSubjectPrefix, cfg.PublishTimeout)
+			if err != nil {
+				return nil, err
+			}
+			sinks = append(sinks, sink)
+		default:
+			return nil, fmt.Errorf("unknown outbox sink %q", name)
+		}
+	}
+	return sinks, nil
+}
@@ -0,0 +1,69 @@
+package auth
+
+import (
+	"context"
+	"crypto/x509"
+	"strings"
+
+	"quotes-service/internal/domain"
+	"quotes-service/internal/service"
+)
+
+// Authenticator сводит API-ключи, JWT и клиентские сертификаты к
+// domain.Principal; транспорты (HTTP, GraphQL, gRPC) только извлекают
+// учетные данные из запроса
+type Authenticator struct {
+	keys         *service.APIKeyService
+	tokens       *TokenVerifier
+	certificates CertificateConfig
+}
+
+type Option func(*Authenticator)
+
+// WithCertificates задает роли клиентов, аутентифицированных сертификатом
+func WithCertificates(config CertificateConfig) Option {
+	return func(a *Authenticator) {
+		a.certificates = config
+	}
+}
+
+// NewAuthenticator: tokens может быть nil, если SSO не настроен
+func NewAuthenticator(keys *service.APIKeyService, tokens *TokenVerifier, opts ...Option) *Authenticator {
+	a := &Authenticator{
+		keys:   keys,
+		tokens: tokens,
+	}
+	for _, opt := range opts {
+		opt(a)
+	}
+	return a
+}
+
+func (a *Authenticator) Authenticate(ctx context.Context, credential string) (*domain.Principal, error) {
+	if credential == "" {
+		return nil, domain.ErrUnauthenticated
+	}
+
+	// JWT состоит из трех частей через точку, в API-ключах точек нет
+	if strings.Count(credential, ".") == 2 {
+		if a.tokens == nil {
+			return nil, domain.ErrUnauthenticated
+		}
+		return a.tokens.Verify(ctx, credential)
+	}
+
+	key, err := a.keys.Authenticate(ctx, credential)
+	if err != nil {
+		return nil, err
+	}
+	return key.Principal(), nil
+}
+
+// AuthenticateCertificate строит автора по клиентскому сертификату, уже
+// проверенному TLS-рукопожатием по бандлу CA
+func (a *Authenticator) AuthenticateCertificate(certificate *x509.Certificate) (*domain.Principal, error) {
+	if certificate == nil {
+		return nil, domain.ErrUnauthenticated
+	}
+	return a.certificates.principal(certificate)
+}
@@ -0,0 +1,49 @@
+package auth
+
+import (
+	"crypto/x509"
+
+	"quotes-service/internal/domain"
+)
+
+// CertificateConfig - роли клиентов, прошедших проверку сертификата (mTLS).
+// Ключ RoleMapping - идентификатор клиента (см. CertificateIdentity).
+// Клиент без отображения получает DefaultRole; пустая роль - никаких прав
+type CertificateConfig struct {
+	RoleMapping map[string]domain.Role
+	DefaultRole domain.Role
+}
+
+// CertificateIdentity - идентификатор клиента: Common Name, а без него
+// первый URI (SPIFFE ID) или DNS из SAN
+func CertificateIdentity(certificate *x509.Certificate) string {
+	if certificate.Subject.CommonName != "" {
+		return certificate.Subject.CommonName
+	}
+	if len(certificate.URIs) > 0 {
+		return certificate.URIs[0].String()
+	}
+	if len(certificate.DNSNames) > 0 {
+		return certificate.DNSNames[0]
+	}
+	return ""
+}
+
+func (c CertificateConfig) principal(certificate *x509.Certificate) (*domain.Principal, error) {
+	identity := CertificateIdentity(certificate)
+	if identity == "" {
+		return nil, domain.ErrUnauthenticated
+	}
+
+	principal := &domain.Principal{
+		Subject: "cert:" + identity,
+		Name:    identity,
+		Method:  domain.AuthMethodCertificate,
+	}
+	if role, ok := c.RoleMapping[identity]; ok {
+		principal.Roles = []domain.Role{role}
+	} else if c.DefaultRole != "" {
+		principal.Roles = []domain.Role{c.DefaultRole}
+	}
+	return principal, nil
+}
@@ -0,0 +1,256 @@
+package auth
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"quotes-service/internal/infrastructure/logger"
+)
+
+// Неизвестный kid вызывает внеочередную загрузку не чаще этого интервала,
+// чтобы поддельные токены не превращались в поток запросов к IdP
+const minRefreshInterval = time.Minute
+
+var ErrUnknownKey = errors.New("unknown signing key")
+
+// KeySet - публичные ключи подписи из JWKS. Источник - URL (http/https)
+// или путь к файлу, например с локальными тестовыми ключами
+type KeySet struct {
+	source string
+	client *http.Client
+	logger *logger.Logger
+
+	mu          sync.RWMutex
+	keys        map[string]crypto.PublicKey
+	lastRefresh time.Time
+
+	refreshMu sync.Mutex
+}
+
+// LoadKeySet загружает JWKS сразу, чтобы ошибка конфигурации была видна при старте
+func LoadKeySet(ctx context.Context, source string, logger *logger.Logger) (*KeySet, error) {
+	s := &KeySet{
+		source: source,
+		client: &http.Client{Timeout: 10 * time.Second},
+		logger: logger,
+	}
+	if err := s.Refresh(ctx); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+func (s *KeySet) Refresh(ctx context.Context) error {
+	data, err := s.fetch(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to load JWKS from %s: %w", s.source, err)
+	}
+
+	keys, err := ParseJWKS(data)
+	if err != nil {
+		return fmt.Errorf("failed to parse JWKS from %s: %w", s.source, err)
+	}
+
+	s.mu.Lock()
+	s.keys = keys
+	s.lastRefresh = time.Now()
+	s.mu.Unlock()
+
+	s.logger.Debug("JWKS loaded", "source", s.source, "keys", len(keys))
+	return nil
+}
+
+// Run периодически перечитывает JWKS, чтобы подхватывать ротацию ключей IdP
+func (s *KeySet) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := s.Refresh(ctx); err != nil && ctx.Err() == nil {
+				s.logger.Warn("JWKS refresh failed, keeping previous keys", "error", err)
+			}
+		}
+	}
+}
+
+// Key возвращает ключ по kid; пустой kid допустим, если ключ в наборе один
+func (s *KeySet) Key(ctx context.Context, kid string) (crypto.PublicKey, error) {
+	if key, ok := s.lookup(kid); ok {
+		return key, nil
+	}
+
+	s.refreshMu.Lock()
+	defer s.refreshMu.Unlock()
+
+	// Пока ждали блокировку, набор мог обновить другой запрос
+	if key, ok := s.lookup(kid); ok {
+		return key, nil
+	}
+
+	s.mu.RLock()
+	recent := time.Since(s.lastRefresh) < minRefreshInterval
+	s.mu.RUnlock()
+	if !recent {
+		if err := s.Refresh(ctx); err != nil {
+			s.logger.Warn("JWKS refresh failed", "error", err)
+		}
+		if key, ok := s.lookup(kid); ok {
+			return key, nil
+		}
+	}
+
+	return nil, fmt.Errorf("%w: kid %q", ErrUnknownKey, kid)
+}
+
+func (s *KeySet) lookup(kid string) (crypto.PublicKey, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if kid == "" && len(s.keys) == 1 {
+		for _, key := range s.keys {
+			return key, true
+		}
+	}
+	key, ok := s.keys[kid]
+	return key, ok
+}
+
+func (s *KeySet) fetch(ctx context.Context) ([]byte, error) {
+	if !strings.HasPrefix(s.source, "http://") && !strings.HasPrefix(s.source, "https://") {
+		return os.ReadFile(strings.TrimPrefix(s.source, "file://"))
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.source, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+	return io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+}
+
+type jwk struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+// ParseJWKS разбирает RSA и EC ключи подписи; ключи шифрования и
+// неподдерживаемые типы пропускаются
+func ParseJWKS(data []byte) (map[string]crypto.PublicKey, error) {
+	var set struct {
+		Keys []jwk `json:"keys"`
+	}
+	if err := json.Unmarshal(data, &set); err != nil {
+		return nil, err
+	}
+
+	keys := make(map[string]crypto.PublicKey, len(set.Keys))
+	for _, k := range set.Keys {
+		if k.Use != "" && k.Use != "sig" {
+			continue
+		}
+
+		var (
+			key crypto.PublicKey
+			err error
+		)
+		switch k.Kty {
+		case "RSA":
+			key, err = k.rsa()
+		case "EC":
+			key, err = k.ecdsa()
+		default:
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("key %q: %w", k.Kid, err)
+		}
+		keys[k.Kid] = key
+	}
+
+	if len(keys) == 0 {
+		return nil, errors.New("no signing keys")
+	}
+	return keys, nil
+}
+
+func (k jwk) rsa() (*rsa.PublicKey, error) {
+	n, err := decodeBigInt(k.N)
+	if err != nil {
+		return nil, fmt.Errorf("invalid modulus: %w", err)
+	}
+	e, err := decodeBigInt(k.E)
+	if err != nil || !e.IsInt64() {
+		return nil, errors.New("invalid exponent")
+	}
+	return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+}
+
+func (k jwk) ecdsa() (*ecdsa.PublicKey, error) {
+	var curve elliptic.Curve
+	switch k.Crv {
+	case "P-256":
+		curve = elliptic.P256()
+	case "P-384":
+		curve = elliptic.P384()
+	case "P-521":
+		curve = elliptic.P521()
+	default:
+		return nil, fmt.Errorf("unsupported curve %q", k.Crv)
+	}
+
+	x, err := decodeBigInt(k.X)
+	if err != nil {
+		return nil, fmt.Errorf("invalid x: %w", err)
+	}
+	y, err := decodeBigInt(k.Y)
+	if err != nil {
+		return nil, fmt.Errorf("invalid y: %w", err)
+	}
+	if !curve.IsOnCurve(x, y) {
+		return nil, errors.New("point is not on curve")
+	}
+	return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+}
+
+func decodeBigInt(value string) (*big.Int, error) {
+	data, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) == 0 {
+		return nil, errors.New("empty value")
+	}
+	return new(big.Int).SetBytes(data), nil
+}
@@ -0,0 +1,129 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"quotes-service/internal/domain"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// Config - проверка токенов корпоративного SSO. Пустой JWKS отключает JWT
+type Config struct {
+	JWKS            string
+	RefreshInterval time.Duration
+	Issuer          string
+	Audience        string
+	// RolesClaim - путь к claim с ролями через точку, например realm_access.roles
+	RolesClaim string
+	// RoleMapping переводит роли IdP в роли сервиса; совпадающие имена
+	// (viewer, editor, admin) принимаются и без явного отображения
+	RoleMapping map[string]domain.Role
+	Leeway      time.Duration
+}
+
+var signingMethods = []string{"RS256", "RS384", "RS512", "PS256", "PS384", "PS512", "ES256", "ES384", "ES512"}
+
+// TokenVerifier проверяет подпись, срок, издателя и аудиторию JWT
+// и строит по claims domain.Principal
+type TokenVerifier struct {
+	keys   *KeySet
+	config Config
+	parser *jwt.Parser
+}
+
+func NewTokenVerifier(keys *KeySet, config Config) *TokenVerifier {
+	opts := []jwt.ParserOption{
+		jwt.WithValidMethods(signingMethods),
+		jwt.WithExpirationRequired(),
+		jwt.WithLeeway(config.Leeway),
+	}
+	if config.Issuer != "" {
+		opts = append(opts, jwt.WithIssuer(config.Issuer))
+	}
+	if config.Audience != "" {
+		opts = append(opts, jwt.WithAudience(config.Audience))
+	}
+	if config.RolesClaim == "" {
+		config.RolesClaim = "roles"
+	}
+
+	return &TokenVerifier{
+		keys:   keys,
+		config: config,
+		parser: jwt.NewParser(opts...),
+	}
+}
+
+func (v *TokenVerifier) Verify(ctx context.Context, token string) (*domain.Principal, error) {
+	claims := jwt.MapClaims{}
+	_, err := v.parser.ParseWithClaims(token, claims, func(t *jwt.Token) (interface{}, error) {
+		kid, _ := t.Header["kid"].(string)
+		return v.keys.Key(ctx, kid)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", domain.ErrUnauthenticated, err)
+	}
+
+	subject, err := claims.GetSubject()
+	if err != nil || subject == "" {
+		return nil, fmt.Errorf("%w: token has no subject", domain.ErrUnauthenticated)
+	}
+
+	// sub пространства имен IdP не должен совпасть с api-key:<id> или cert:<cn>
+	// в проверке владельца, поэтому получает префикс
+	principal := &domain.Principal{
+		Subject: "jwt:" + subject,
+		Method:  domain.AuthMethodJWT,
+		Roles:   v.roles(claims),
+	}
+	for _, claim := range []string{"email", "preferred_username", "name"} {
+		if name, ok := claims[claim].(string); ok && name != "" {
+			principal.Name = name
+			break
+		}
+	}
+
+	return principal, nil
+}
+
+func (v *TokenVerifier) roles(claims jwt.MapClaims) []domain.Role {
+	var value interface{} = map[string]interface{}(claims)
+	for _, part := range strings.Split(v.config.RolesClaim, ".") {
+		object, ok := value.(map[string]interface{})
+		if !ok {
+			return nil
+		}
+		value = object[part]
+	}
+
+	// Claim бывает списком или строкой через пробел, как scope в OAuth
+	var names []string
+	switch value := value.(type) {
+	case string:
+		names = strings.Fields(value)
+	case []interface{}:
+		for _, item := range value {
+			if name, ok := item.(string); ok {
+				names = append(names, name)
+			}
+		}
+	}
+
+	seen := make(map[domain.Role]bool)
+	var roles []domain.Role
+	for _, name := range names {
+		role, ok := v.config.RoleMapping[name]
+		if !ok {
+			role = domain.Role(name)
+		}
+		if domain.IsRole(role) && !seen[role] {
+			seen[role] = true
+			roles = append(roles, role)
+		}
+	}
+	return roles
+}
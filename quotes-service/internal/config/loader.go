@@ -0,0 +1,377 @@
+package config
+
+import (
+	"flag"
+	"fmt"
+	"net/netip"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"quotes-service/internal/domain"
+	"quotes-service/internal/handler"
+	"quotes-service/internal/ratelimit"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+)
+
+// Источники значения ключа
+const (
+	SourceFlag    = "flag"
+	SourceEnv     = "env"
+	SourceFile    = "file"
+	SourceDefault = "default"
+)
+
+// Setting - итоговое значение ключа конфигурации и его источник
+type Setting struct {
+	Key    string
+	Value  string
+	Source string
+}
+
+// loader ищет ключи (имена переменных окружения) во флагах, окружении и
+// файле, запоминает итоговые значения и копит ошибки разбора
+type loader struct {
+	flags map[string]string
+	file  map[string]string
+	// env == nil - окружение не читается (сбор значений по умолчанию)
+	env func(string) string
+
+	settings []Setting
+	seen     map[string]bool
+	errs     []error
+}
+
+func (l *loader) errorf(key, format string, args ...interface{}) {
+	l.errs = append(l.errs, fmt.Errorf("%s: %s", key, fmt.Sprintf(format, args...)))
+}
+
+// value возвращает строковое значение ключа и признак того, что оно задано
+// явно. Пустая переменная окружения считается незаданной, пустое значение
+// во флаге или файле - заданным
+func (l *loader) value(key, defaultValue string) (string, bool) {
+	if l.seen == nil {
+		l.seen = make(map[string]bool)
+	}
+	l.seen[key] = true
+
+	value, source := defaultValue, SourceDefault
+	if v, ok := l.flags[key]; ok {
+		value, source = v, SourceFlag
+	} else if v := l.getenv(key); v != "" {
+		value, source = v, SourceEnv
+	} else if v, ok := l.file[key]; ok {
+		value, source = v, SourceFile
+	}
+	l.settings = append(l.settings, Setting{Key: key, Value: value, Source: source})
+	return value, source != SourceDefault
+}
+
+func (l *loader) getenv(key string) string {
+	if l.env == nil {
+		return ""
+	}
+	return l.env(key)
+}
+
+// checkUnknownKeys: опечатка в файле иначе молча оставила бы значение по умолчанию
+func (l *loader) checkUnknownKeys() {
+	var unknown []string
+	for key := range l.file {
+		if !l.seen[key] {
+			unknown = append(unknown, key)
+		}
+	}
+	sort.Strings(unknown)
+	for _, key := range unknown {
+		l.errorf(key, "unknown key in config file")
+	}
+}
+
+func (l *loader) string(key, defaultValue string) string {
+	value, _ := l.value(key, defaultValue)
+	return value
+}
+
+func (l *loader) int(key string, defaultValue int) int {
+	value, ok := l.value(key, strconv.Itoa(defaultValue))
+	if !ok {
+		return defaultValue
+	}
+	intValue, err := strconv.Atoi(value)
+	if err != nil {
+		l.errorf(key, "invalid integer %q", value)
+		return defaultValue
+	}
+	return intValue
+}
+
+func (l *loader) float(key string, defaultValue float64) float64 {
+	value, ok := l.value(key, strconv.FormatFloat(defaultValue, 'g', -1, 64))
+	if !ok {
+		return defaultValue
+	}
+	floatValue, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		l.errorf(key, "invalid number %q", value)
+		return defaultValue
+	}
+	return floatValue
+}
+
+func (l *loader) bool(key string, defaultValue bool) bool {
+	value, ok := l.value(key, strconv.FormatBool(defaultValue))
+	if !ok {
+		return defaultValue
+	}
+	boolValue, err := strconv.ParseBool(value)
+	if err != nil {
+		l.errorf(key, "invalid boolean %q", value)
+		return defaultValue
+	}
+	return boolValue
+}
+
+func (l *loader) duration(key string, defaultValue time.Duration) time.Duration {
+	value, ok := l.value(key, defaultValue.String())
+	if !ok {
+		return defaultValue
+	}
+	duration, err := time.ParseDuration(value)
+	if err != nil {
+		l.errorf(key, "invalid duration %q (expected e.g. 30s, 5m)", value)
+		return defaultValue
+	}
+	return duration
+}
+
+// date читает дату в формате YYYY-MM-DD (UTC)
+func (l *loader) date(key string, defaultValue time.Time) time.Time {
+	value, ok := l.value(key, defaultValue.Format(time.DateOnly))
+	if !ok {
+		return defaultValue
+	}
+	date, err := time.Parse(time.DateOnly, value)
+	if err != nil {
+		l.errorf(key, "invalid date %q (expected YYYY-MM-DD)", value)
+		return defaultValue
+	}
+	return date
+}
+
+// list читает значения через запятую; пустой список заменяется defaultValue
+func (l *loader) list(key string, defaultValue []string) []string {
+	value, _ := l.value(key, strings.Join(defaultValue, ","))
+	if values := splitList(value); len(values) > 0 {
+		return values
+	}
+	return defaultValue
+}
+
+// roleMapping читает пары роль_IdP=роль_сервиса через запятую
+func (l *loader) roleMapping(key string) map[string]domain.Role {
+	value, _ := l.value(key, "")
+	mapping := make(map[string]domain.Role)
+	for _, pair := range splitList(value) {
+		from, to, ok := strings.Cut(pair, "=")
+		if !ok {
+			l.errorf(key, "invalid pair %q (expected idp_role=role)", pair)
+			continue
+		}
+		role := domain.Role(strings.TrimSpace(to))
+		if !domain.IsRole(role) {
+			l.errorf(key, "unknown role %q", role)
+			continue
+		}
+		mapping[strings.TrimSpace(from)] = role
+	}
+	return mapping
+}
+
+func (l *loader) rateRules(key, defaultValue string) []ratelimit.Rule {
+	value, _ := l.value(key, defaultValue)
+	rules, err := ratelimit.ParseRules(value)
+	if err != nil {
+		l.errorf(key, "%v", err)
+		rules, _ = ratelimit.ParseRules(defaultValue)
+	}
+	return rules
+}
+
+// rateLimit читает лимит без маршрута вида "20/1m"; 0 - без лимита
+func (l *loader) rateLimit(key, defaultValue string) ratelimit.Rule {
+	value, _ := l.value(key, defaultValue)
+	rule, err := ratelimit.ParseLimit(value)
+	if err != nil {
+		l.errorf(key, "%v", err)
+		rule, _ = ratelimit.ParseLimit(defaultValue)
+	}
+	return rule
+}
+
+func (l *loader) routeTimeouts(key, defaultValue string) map[string]time.Duration {
+	value, _ := l.value(key, defaultValue)
+	routes, err := handler.ParseRouteTimeouts(value)
+	if err != nil {
+		l.errorf(key, "%v", err)
+		routes, _ = handler.ParseRouteTimeouts(defaultValue)
+	}
+	return routes
+}
+
+// prefixes читает подсети (CIDR) или отдельные адреса через запятую
+func (l *loader) prefixes(key string) []netip.Prefix {
+	value, _ := l.value(key, "")
+	var prefixes []netip.Prefix
+	for _, item := range splitList(value) {
+		if prefix, err := netip.ParsePrefix(item); err == nil {
+			prefixes = append(prefixes, prefix.Masked())
+		} else if addr, err := netip.ParseAddr(item); err == nil {
+			prefixes = append(prefixes, netip.PrefixFrom(addr, addr.BitLen()))
+		} else {
+			l.errorf(key, "invalid address or subnet %q", item)
+		}
+	}
+	return prefixes
+}
+
+func splitList(value string) []string {
+	var values []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			values = append(values, item)
+		}
+	}
+	return values
+}
+
+// parseFlags разбирает -config и флаги для каждого ключа: DB_MAX_OPEN_CONNS
+// задается как -db-max-open-conns. Путь к файлу без флага берется из CONFIG_FILE
+func parseFlags(args []string) (map[string]string, string, error) {
+	// Пустой проход собирает ключи и значения по умолчанию для справки
+	defaults := &loader{}
+	defaults.load()
+
+	flags := make(map[string]string)
+	set := flag.NewFlagSet("quotes-service", flag.ContinueOnError)
+	path := set.String("config", os.Getenv("CONFIG_FILE"), "path to a YAML or TOML config file (CONFIG_FILE)")
+	for _, setting := range defaults.settings {
+		key := setting.Key
+		usage := key
+		if setting.Value != "" {
+			usage = fmt.Sprintf("%s (default %q)", key, redactValue(setting.Value))
+		}
+		set.Func(flagName(key), usage, func(value string) error {
+			flags[key] = value
+			return nil
+		})
+	}
+
+	if err := set.Parse(args); err != nil {
+		return nil, "", err
+	}
+	if set.NArg() > 0 {
+		return nil, "", fmt.Errorf("unexpected arguments: %s", strings.Join(set.Args(), " "))
+	}
+	return flags, *path, nil
+}
+
+func flagName(key string) string {
+	return strings.ToLower(strings.ReplaceAll(key, "_", "-"))
+}
+
+// readFile читает YAML или TOML. Ключи - имена переменных окружения в любом
+// регистре; вложенные секции склеиваются через "_", поэтому
+// "db: {max_open_conns: 50}" равносильно "DB_MAX_OPEN_CONNS: 50"
+func readFile(path string) (map[string]string, error) {
+	if path == "" {
+		return nil, nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read config file: %w", err)
+	}
+
+	var document map[string]interface{}
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(data, &document)
+	case ".toml":
+		err = toml.Unmarshal(data, &document)
+	default:
+		return nil, fmt.Errorf("config file %s: unsupported format, expected .yaml, .yml or .toml", path)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("parse config file %s: %w", path, err)
+	}
+
+	values := make(map[string]string)
+	if err := flatten("", document, values); err != nil {
+		return nil, fmt.Errorf("config file %s: %w", path, err)
+	}
+	return values, nil
+}
+
+func flatten(prefix string, document map[string]interface{}, values map[string]string) error {
+	for name, value := range document {
+		key := strings.ToUpper(strings.NewReplacer("-", "_", ".", "_").Replace(name))
+		if prefix != "" {
+			key = prefix + "_" + key
+		}
+
+		if section, ok := value.(map[string]interface{}); ok {
+			if err := flatten(key, section, values); err != nil {
+				return err
+			}
+			continue
+		}
+		if _, ok := values[key]; ok {
+			return fmt.Errorf("%s: key is set more than once", key)
+		}
+		scalar, err := scalarString(value)
+		if err != nil {
+			return fmt.Errorf("%s: %w", key, err)
+		}
+		values[key] = scalar
+	}
+	return nil
+}
+
+// scalarString приводит значение из файла к строке в формате переменной
+// окружения; списки склеиваются через запятую
+func scalarString(value interface{}) (string, error) {
+	switch v := value.(type) {
+	case nil:
+		return "", nil
+	case string:
+		return v, nil
+	case time.Time:
+		if v.Hour() == 0 && v.Minute() == 0 && v.Second() == 0 && v.Nanosecond() == 0 {
+			return v.Format(time.DateOnly), nil
+		}
+		return v.Format(time.RFC3339), nil
+	case []interface{}:
+		items := make([]string, 0, len(v))
+		for _, item := range v {
+			if _, ok := item.([]interface{}); ok {
+				return "", fmt.Errorf("nested lists are not supported")
+			}
+			s, err := scalarString(item)
+			if err != nil {
+				return "", err
+			}
+			items = append(items, s)
+		}
+		return strings.Join(items, ","), nil
+	case map[string]interface{}:
+		return "", fmt.Errorf("unexpected section")
+	case bool, int, int64, uint64, float64:
+		return fmt.Sprint(v), nil
+	}
+	return "", fmt.Errorf("unsupported value %v", value)
+}
@@ -0,0 +1,152 @@
+package config
+
+import (
+	"sync"
+
+	"quotes-service/internal/infrastructure/logger"
+)
+
+// Change - изменившийся ключ; значения с секретами скрыты, как в Settings
+type Change struct {
+	Key string
+	Old string
+	New string
+}
+
+// Diff сравнивает итоговые значения ключей двух конфигураций. Сравниваются
+// исходные значения, чтобы смена одного пароля тоже считалась изменением,
+// а в Change попадают значения со скрытыми секретами
+func Diff(old, new *Config) []Change {
+	previous := make(map[string]string, len(old.settings))
+	for _, setting := range old.settings {
+		previous[setting.Key] = setting.Value
+	}
+
+	var changes []Change
+	for _, setting := range new.settings {
+		if value, ok := previous[setting.Key]; !ok || value != setting.Value {
+			changes = append(changes, Change{Key: setting.Key, Old: redactValue(value), New: redactValue(setting.Value)})
+		}
+	}
+	return changes
+}
+
+// Reloader перечитывает конфигурацию с теми же флагами и файлом (по SIGHUP).
+// Новая конфигурация сначала проверяется целиком: при ошибке работающие
+// компоненты не трогаются. Затем под одной блокировкой вызываются
+// обработчики компонентов, чьи ключи изменились; изменения остальных ключей
+// только логируются, так как требуют перезапуска. В Current для таких ключей
+// остаются работающие значения, и предупреждение повторяется при каждом
+// перечитывании, пока сервис не перезапущен
+type Reloader struct {
+	args     []string
+	logger   *logger.Logger
+	mu       sync.Mutex
+	current  *Config
+	handlers []reloadHandler
+}
+
+type reloadHandler struct {
+	keys  []string
+	apply func(*Config)
+}
+
+func NewReloader(args []string, current *Config, logger *logger.Logger) *Reloader {
+	return &Reloader{
+		args:    args,
+		logger:  logger,
+		current: current,
+	}
+}
+
+// OnChange регистрирует применение ключей keys к работающему компоненту.
+// apply не должен завершаться ошибкой: все значения уже проверены Load
+func (r *Reloader) OnChange(keys []string, apply func(*Config)) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.handlers = append(r.handlers, reloadHandler{keys: keys, apply: apply})
+}
+
+// Current возвращает конфигурацию, с которой работает сервис: значения
+// ключей, требующих перезапуска, в ней не меняются
+func (r *Reloader) Current() *Config {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.current
+}
+
+func (r *Reloader) Reload() error {
+	next, err := Load(r.args)
+	if err != nil {
+		r.logger.Error("Configuration reload rejected", "error", err)
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	changes := Diff(r.current, next)
+	if len(changes) == 0 {
+		r.logger.Info("Configuration reloaded without changes")
+		return nil
+	}
+
+	changed := make(map[string]bool, len(changes))
+	for _, change := range changes {
+		changed[change.Key] = true
+	}
+	reloadable := make(map[string]bool)
+	for _, handler := range r.handlers {
+		for _, key := range handler.keys {
+			reloadable[key] = true
+		}
+	}
+	applied := r.current.merge(next, reloadable)
+	for _, handler := range r.handlers {
+		for _, key := range handler.keys {
+			if changed[key] {
+				handler.apply(applied)
+				break
+			}
+		}
+	}
+
+	count := 0
+	for _, change := range changes {
+		if reloadable[change.Key] {
+			count++
+			r.logger.Warn("Configuration changed", "key", change.Key, "from", change.Old, "to", change.New)
+		} else {
+			r.logger.Warn("Configuration change requires restart", "key", change.Key, "from", change.Old, "to", change.New)
+		}
+	}
+	r.logger.Info("Configuration reloaded", "changed", len(changes), "applied", count)
+
+	r.current = applied
+	return nil
+}
+
+// merge собирает конфигурацию из значений next для ключей reloadable и
+// значений c для остальных ключей. Значения уже проверены Load, поэтому
+// повторный разбор ошибок не дает
+func (c *Config) merge(next *Config, reloadable map[string]bool) *Config {
+	running := make(map[string]Setting, len(c.settings))
+	for _, setting := range c.settings {
+		running[setting.Key] = setting
+	}
+
+	values := make(map[string]string, len(next.settings))
+	settings := make([]Setting, 0, len(next.settings))
+	for _, setting := range next.settings {
+		if old, ok := running[setting.Key]; ok && !reloadable[setting.Key] {
+			setting = old
+		}
+		values[setting.Key] = setting.Value
+		settings = append(settings, setting)
+	}
+
+	l := &loader{flags: values}
+	merged := l.load()
+	merged.settings = settings
+	return merged
+}
@@ -0,0 +1,199 @@
+package config
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	"quotes-service/internal/domain"
+	"quotes-service/internal/infrastructure/logger"
+	"quotes-service/internal/tlsconfig"
+	"quotes-service/internal/tracing"
+)
+
+// validate проверяет значения, которые разобрались, но не имеют смысла:
+// нулевые интервалы тикеров, неизвестные sink и экспортеры, противоречивые
+// настройки. Ошибки копятся в loader вместе с ошибками разбора
+func (c *Config) validate(l *loader) {
+	require := func(ok bool, key, format string, args ...interface{}) {
+		if !ok {
+			l.errorf(key, format, args...)
+		}
+	}
+	positive := func(key string, value time.Duration) {
+		require(value > 0, key, "must be positive, got %s", value)
+	}
+	atLeast := func(key string, value, min int) {
+		require(value >= min, key, "must be at least %d, got %d", min, value)
+	}
+
+	require(c.ServerAddress != "", "SERVER_ADDRESS", "must not be empty")
+	require(c.Server.ReadTimeout >= 0, "SERVER_READ_TIMEOUT", "must not be negative")
+	positive("SERVER_READ_HEADER_TIMEOUT", c.Server.ReadHeaderTimeout)
+	require(c.Server.WriteTimeout >= 0, "SERVER_WRITE_TIMEOUT", "must not be negative")
+	require(c.Server.IdleTimeout >= 0, "SERVER_IDLE_TIMEOUT", "must not be negative")
+	atLeast("SERVER_MAX_HEADER_BYTES", c.Server.MaxHeaderBytes, 1)
+	positive("SERVER_SHUTDOWN_TIMEOUT", c.Server.ShutdownTimeout)
+
+	if c.TLS.Files.Enabled() || c.TLS.Files.KeyFile != "" {
+		require(c.TLS.Files.CertFile != "", "TLS_CERT_FILE", "is required with TLS_KEY_FILE")
+		require(c.TLS.Files.KeyFile != "", "TLS_KEY_FILE", "is required with TLS_CERT_FILE")
+		positive("TLS_RELOAD_INTERVAL", c.TLS.ReloadInterval)
+		if _, err := tlsconfig.ParseMinVersion(c.TLS.Files.MinVersion); err != nil {
+			l.errorf("TLS_MIN_VERSION", "%v (expected 1.2, 1.3)", err)
+		}
+	}
+	switch c.TLS.Files.ClientAuth {
+	case tlsconfig.ClientAuthNone:
+	case tlsconfig.ClientAuthOptional, tlsconfig.ClientAuthRequire:
+		require(c.TLS.Files.Enabled(), "TLS_CLIENT_AUTH", "requires TLS_CERT_FILE")
+		require(c.TLS.Files.ClientCAFile != "", "TLS_CLIENT_CA_FILE", "is required for client auth %q", c.TLS.Files.ClientAuth)
+	default:
+		l.errorf("TLS_CLIENT_AUTH", "unknown mode %q (expected none, optional, require)", c.TLS.Files.ClientAuth)
+	}
+	if role := c.TLS.Clients.DefaultRole; role != "" {
+		require(domain.IsRole(role), "TLS_CLIENT_DEFAULT_ROLE", "unknown role %q", role)
+	}
+	if c.TLS.RedirectAddress != "" {
+		require(c.TLS.Files.Enabled(), "TLS_REDIRECT_ADDRESS", "requires TLS_CERT_FILE")
+		require(c.TLS.RedirectAddress != c.ServerAddress, "TLS_REDIRECT_ADDRESS", "must differ from SERVER_ADDRESS")
+	}
+
+	// Дедлайн запроса длиннее WriteTimeout бесполезен: сервер оборвет
+	// соединение раньше, и клиент не получит 504
+	if c.Server.WriteTimeout > 0 {
+		require(c.Timeouts.Default > 0 && c.Timeouts.Default <= c.Server.WriteTimeout, "HTTP_REQUEST_TIMEOUT",
+			"must be positive and not exceed SERVER_WRITE_TIMEOUT (%s), got %s", c.Server.WriteTimeout, c.Timeouts.Default)
+		routes := make([]string, 0, len(c.Timeouts.Routes))
+		for route := range c.Timeouts.Routes {
+			routes = append(routes, route)
+		}
+		sort.Strings(routes)
+		for _, route := range routes {
+			timeout := c.Timeouts.Routes[route]
+			require(timeout > 0 && timeout <= c.Server.WriteTimeout, "HTTP_ROUTE_TIMEOUTS",
+				"%s: must be positive and not exceed SERVER_WRITE_TIMEOUT (%s), got %s", route, c.Server.WriteTimeout, timeout)
+		}
+	}
+	require(c.Timeouts.Default >= 0, "HTTP_REQUEST_TIMEOUT", "must not be negative")
+
+	positive("QUOTE_TIMEOUT_CREATE", c.QuoteTimeouts.Create)
+	positive("QUOTE_TIMEOUT_GET", c.QuoteTimeouts.Get)
+	positive("QUOTE_TIMEOUT_LIST", c.QuoteTimeouts.List)
+	positive("QUOTE_TIMEOUT_RANDOM", c.QuoteTimeouts.Random)
+	positive("QUOTE_TIMEOUT_UPDATE", c.QuoteTimeouts.Update)
+	positive("QUOTE_TIMEOUT_DELETE", c.QuoteTimeouts.Delete)
+	positive("QUOTE_TIMEOUT_HEALTH", c.QuoteTimeouts.Health)
+	positive("APIKEY_TIMEOUT", c.QuoteTimeouts.APIKey)
+	positive("IDEMPOTENCY_TIMEOUT", c.QuoteTimeouts.Idempotency)
+
+	require(c.DatabaseConfig.URL != "", "DATABASE_URL", "must not be empty")
+	atLeast("DB_MAX_OPEN_CONNS", c.DatabaseConfig.MaxOpenConns, 0)
+	atLeast("DB_MAX_IDLE_CONNS", c.DatabaseConfig.MaxIdleConns, 0)
+	positive("DB_REPLICA_CHECK_INTERVAL", c.DatabaseConfig.ReplicaCheckInterval)
+
+	if c.CacheConfig.Enabled {
+		atLeast("CACHE_SIZE", c.CacheConfig.Size, 1)
+		positive("CACHE_TTL", c.CacheConfig.TTL)
+		positive("CACHE_LOAD_TIMEOUT", c.CacheConfig.LoadTimeout)
+	}
+
+	positive("OUTBOX_RELAY_INTERVAL", c.OutboxConfig.RelayInterval)
+	atLeast("OUTBOX_BATCH_SIZE", c.OutboxConfig.BatchSize, 1)
+	positive("OUTBOX_MAX_BACKOFF", c.OutboxConfig.MaxBackoff)
+	positive("OUTBOX_PUBLISH_TIMEOUT", c.OutboxConfig.PublishTimeout)
+	require(c.OutboxConfig.Lease >= c.OutboxConfig.PublishTimeout, "OUTBOX_LEASE",
+		"must be at least OUTBOX_PUBLISH_TIMEOUT (%s), got %s", c.OutboxConfig.PublishTimeout, c.OutboxConfig.Lease)
+	for _, sink := range c.OutboxConfig.Sinks {
+		switch sink {
+		case "log":
+		case "webhook":
+			require(c.OutboxConfig.WebhookURL != "", "OUTBOX_WEBHOOK_URL", "is required for webhook sink")
+		case "nats":
+			require(c.OutboxConfig.NATSURL != "", "OUTBOX_NATS_URL", "is required for nats sink")
+		default:
+			l.errorf("OUTBOX_SINKS", "unknown sink %q (expected log, webhook, nats)", sink)
+		}
+	}
+
+	positive("WEBHOOK_POLL_INTERVAL", c.WebhookConfig.PollInterval)
+	atLeast("WEBHOOK_BATCH_SIZE", c.WebhookConfig.BatchSize, 1)
+	positive("WEBHOOK_TIMEOUT", c.WebhookConfig.Timeout)
+	atLeast("WEBHOOK_MAX_ATTEMPTS", c.WebhookConfig.MaxAttempts, 1)
+	positive("WEBHOOK_MIN_BACKOFF", c.WebhookConfig.MinBackoff)
+	require(c.WebhookConfig.MaxBackoff >= c.WebhookConfig.MinBackoff, "WEBHOOK_MAX_BACKOFF",
+		"must not be less than WEBHOOK_MIN_BACKOFF (%s)", c.WebhookConfig.MinBackoff)
+
+	atLeast("STREAM_REPLAY_SIZE", c.StreamConfig.ReplaySize, 0)
+	atLeast("STREAM_CLIENT_BUFFER", c.StreamConfig.ClientBuffer, 1)
+	positive("STREAM_HEARTBEAT_INTERVAL", c.StreamConfig.HeartbeatInterval)
+	positive("STREAM_POLL_INTERVAL", c.StreamConfig.PollInterval)
+	// Пропуск в номерах событий ждется, пока может идти транзакция записи
+	writeTimeout := max(c.QuoteTimeouts.Create, c.QuoteTimeouts.Update, c.QuoteTimeouts.Delete)
+	require(c.StreamConfig.GapTimeout >= writeTimeout, "STREAM_GAP_TIMEOUT",
+		"must be at least QUOTE_TIMEOUT_CREATE/UPDATE/DELETE (%s), got %s", writeTimeout, c.StreamConfig.GapTimeout)
+
+	atLeast("WS_MAX_CONNECTIONS", c.SocketConfig.MaxConnections, 0)
+	atLeast("WS_SEND_BUFFER", c.SocketConfig.SendBuffer, 1)
+	positive("WS_PING_INTERVAL", c.SocketConfig.PingInterval)
+	require(c.SocketConfig.MinRandomInterval >= 0, "WS_MIN_RANDOM_INTERVAL", "must not be negative")
+
+	atLeast("GRAPHQL_MAX_DEPTH", c.GraphQLConfig.MaxDepth, 1)
+	atLeast("GRAPHQL_MAX_COMPLEXITY", c.GraphQLConfig.MaxComplexity, 1)
+
+	if c.APIConfig.LegacyRoutes {
+		require(c.APIConfig.Sunset.After(c.APIConfig.DeprecatedSince), "API_SUNSET",
+			"must be after API_DEPRECATED_SINCE (%s)", c.APIConfig.DeprecatedSince.Format(time.DateOnly))
+	}
+
+	if c.JWTConfig.JWKS != "" {
+		positive("JWT_JWKS_REFRESH", c.JWTConfig.RefreshInterval)
+		require(c.JWTConfig.RolesClaim != "", "JWT_ROLES_CLAIM", "must not be empty")
+	}
+	require(c.JWTConfig.Leeway >= 0, "JWT_LEEWAY", "must not be negative")
+
+	atLeast("RATE_LIMIT_MAX_CLIENTS", c.RateLimit.Limits.MaxClients, 1)
+
+	positive("IDEMPOTENCY_WINDOW", c.Idempotency.Window)
+	positive("IDEMPOTENCY_LOCK_TIMEOUT", c.Idempotency.LockTimeout)
+	positive("IDEMPOTENCY_CLEANUP_INTERVAL", c.Idempotency.CleanupInterval)
+
+	if c.Metrics.Enabled {
+		require(strings.HasPrefix(c.Metrics.Path, "/"), "METRICS_PATH", "must start with /")
+	}
+
+	switch c.Tracing.Exporter {
+	case tracing.ExporterNone, tracing.ExporterOTLP, tracing.ExporterStdout:
+	case tracing.ExporterFile:
+		require(c.Tracing.File != "", "TRACING_FILE", "is required for file exporter")
+	default:
+		l.errorf("TRACING_EXPORTER", "unknown exporter %q (expected none, otlp, stdout, file)", c.Tracing.Exporter)
+	}
+	require(c.Tracing.SampleRatio >= 0 && c.Tracing.SampleRatio <= 1, "TRACING_SAMPLE_RATIO",
+		"must be between 0 and 1, got %g", c.Tracing.SampleRatio)
+
+	positive("HEALTH_CHECK_TIMEOUT", c.Health.CheckTimeout)
+	positive("HEALTH_OUTBOX_MAX_LAG", c.Health.OutboxMaxLag)
+	require(c.Health.ShutdownDelay >= 0, "HEALTH_SHUTDOWN_DELAY", "must not be negative")
+
+	if c.Debug.Enabled {
+		require(c.Debug.Address != "", "DEBUG_ADDRESS", "must not be empty when debug server is enabled")
+	}
+
+	if _, err := logger.ParseLevel(c.Logging.Level); err != nil {
+		l.errorf("LOG_LEVEL", "%v (expected debug, info, warn, error)", err)
+	}
+	switch c.Logging.Format {
+	case logger.FormatJSON, logger.FormatText, logger.FormatPretty:
+	default:
+		l.errorf("LOG_FORMAT", "unknown format %q (expected json, text, pretty)", c.Logging.Format)
+	}
+	require(c.Logging.Output != "", "LOG_OUTPUT", "must not be empty")
+	atLeast("LOG_MAX_SIZE_MB", c.Logging.MaxSizeMB, 0)
+	atLeast("LOG_MAX_BACKUPS", c.Logging.MaxBackups, 0)
+	atLeast("LOG_SAMPLE_FIRST", c.Logging.SampleFirst, 0)
+	if c.Logging.SampleFirst > 0 {
+		atLeast("LOG_SAMPLE_THEREAFTER", c.Logging.SampleThereafter, 0)
+		positive("LOG_SAMPLE_PERIOD", c.Logging.SamplePeriod)
+	}
+}
@@ -0,0 +1,109 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	ErrAPIKeyNotFound  = errors.New("api key not found")
+	ErrInvalidAPIKey   = errors.New("invalid api key data")
+	ErrUnauthenticated = errors.New("missing or invalid api key")
+	ErrForbidden       = errors.New("api key lacks required scope")
+	ErrAdminKeyExists  = errors.New("an active admin api key already exists")
+)
+
+// Scope - уровень доступа ключа; admin включает write, write включает read
+type Scope string
+
+const (
+	ScopeRead  Scope = "read"
+	ScopeWrite Scope = "write"
+	ScopeAdmin Scope = "admin"
+)
+
+var scopeLevels = map[Scope]int{ScopeRead: 1, ScopeWrite: 2, ScopeAdmin: 3}
+
+type APIKey struct {
+	ID         int        `json:"id"`
+	Name       string     `json:"name"`
+	Prefix     string     `json:"prefix"`
+	Hash       string     `json:"-"`
+	Scopes     []Scope    `json:"scopes"`
+	ExpiresAt  *time.Time `json:"expires_at,omitempty"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+	RevokedAt  *time.Time `json:"revoked_at,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+// Allows сообщает, покрывают ли scopes ключа требуемый уровень доступа
+func (k *APIKey) Allows(required Scope) bool {
+	for _, scope := range k.Scopes {
+		if scopeLevels[scope] >= scopeLevels[required] {
+			return true
+		}
+	}
+	return false
+}
+
+// Active - ключ не отозван и не истек к моменту now
+func (k *APIKey) Active(now time.Time) bool {
+	if k.RevokedAt != nil {
+		return false
+	}
+	return k.ExpiresAt == nil || now.Before(*k.ExpiresAt)
+}
+
+type APIKeyRequest struct {
+	Name      string     `json:"name"`
+	Scopes    []Scope    `json:"scopes"`
+	ExpiresAt *time.Time `json:"expires_at"`
+}
+
+func (r *APIKeyRequest) Validate(now time.Time) error {
+	r.Name = strings.TrimSpace(r.Name)
+
+	if r.Name == "" {
+		return errors.New("name is required")
+	}
+	if len(r.Name) > 100 {
+		return errors.New("name must be less than 100 characters")
+	}
+	if len(r.Scopes) == 0 {
+		return errors.New("at least one scope is required")
+	}
+	for _, scope := range r.Scopes {
+		if _, ok := scopeLevels[scope]; !ok {
+			return errors.New("unknown scope: " + string(scope))
+		}
+	}
+	if r.ExpiresAt != nil && !r.ExpiresAt.After(now) {
+		return errors.New("expires_at must be in the future")
+	}
+
+	return nil
+}
+
+type APIKeyRepository interface {
+	Create(ctx context.Context, key *APIKey) (*APIKey, error)
+	// GetByPrefix ищет ключ по открытой части; секрет сверяется по хешу
+	GetByPrefix(ctx context.Context, prefix string) (*APIKey, error)
+	List(ctx context.Context) ([]*APIKey, error)
+	Revoke(ctx context.Context, id int, at time.Time) (*APIKey, error)
+	TouchLastUsed(ctx context.Context, id int, at time.Time) error
+	// CountActive считает неотозванные и неистекшие ключи со scope
+	CountActive(ctx context.Context, scope Scope) (int, error)
+}
+
+// Principal представляет ключ как автора запроса
+func (k *APIKey) Principal() *Principal {
+	return &Principal{
+		Subject: "api-key:" + strconv.Itoa(k.ID),
+		Name:    k.Name,
+		Method:  AuthMethodAPIKey,
+		Scopes:  k.Scopes,
+	}
+}
@@ -0,0 +1,67 @@
+package domain
+
+import (
+	"context"
+	"time"
+)
+
+type EventType string
+
+const (
+	EventQuoteCreated EventType = "quote.created"
+	EventQuoteUpdated EventType = "quote.updated"
+	EventQuoteDeleted EventType = "quote.deleted"
+)
+
+type Event struct {
+	ID         int64     `json:"id"`
+	Type       EventType `json:"type"`
+	QuoteID    int       `json:"quote_id"`
+	Quote      *Quote    `json:"quote,omitempty"`
+	Actor      string    `json:"actor,omitempty"`
+	OccurredAt time.Time `json:"occurred_at"`
+	Attempts   int       `json:"-"`
+}
+
+func NewQuoteEvent(eventType EventType, quoteID int, quote *Quote) *Event {
+	return &Event{
+		Type:       eventType,
+		QuoteID:    quoteID,
+		Quote:      quote,
+		OccurredAt: time.Now().UTC(),
+	}
+}
+
+// EventResult - итог публикации события релеем. Пустой Err означает,
+// что событие доставлено; иначе повтор не раньше RetryAt.
+type EventResult struct {
+	EventID int64
+	Err     error
+	RetryAt time.Time
+}
+
+type OutboxRepository interface {
+	Append(ctx context.Context, events ...*Event) error
+	// ProcessPending арендует пачку готовых к отправке событий по порядку
+	// на срок lease, передает их в process и сохраняет результаты
+	ProcessPending(ctx context.Context, limit int, lease time.Duration, process func(ctx context.Context, events []*Event) []EventResult) error
+	// PendingLag - возраст самого старого неопубликованного события (0, если их нет)
+	PendingLag(ctx context.Context) (time.Duration, error)
+	// ListAfter возвращает события с id больше afterID по порядку,
+	// независимо от публикации
+	ListAfter(ctx context.Context, afterID int64, limit int) ([]*Event, error)
+	// LastEventID - id последнего события (0, если их нет)
+	LastEventID(ctx context.Context) (int64, error)
+}
+
+// EventListener получает события после коммита, в процессе сервиса;
+// реализация не должна блокироваться
+type EventListener interface {
+	OnEvent(event *Event)
+}
+
+// Transactor выполняет fn в одной транзакции; репозитории, получившие
+// контекст fn, работают внутри нее
+type Transactor interface {
+	WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error
+}
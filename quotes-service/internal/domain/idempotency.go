@@ -0,0 +1,44 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+var (
+	ErrInvalidIdempotencyKey = errors.New("invalid idempotency key")
+	// ErrIdempotencyKeyReused - ключ уже использован с другим запросом
+	ErrIdempotencyKeyReused = errors.New("idempotency key reused with a different request")
+	// ErrIdempotencyInProgress - запрос с тем же ключом еще выполняется
+	ErrIdempotencyInProgress = errors.New("request with this idempotency key is in progress")
+)
+
+// IdempotencyRecord - ответ на запрос с Idempotency-Key. Пока запрос
+// выполняется, StatusCode равен нулю, а ExpiresAt ограничивает блокировку ключа
+type IdempotencyRecord struct {
+	Client      string
+	Key         string
+	Fingerprint string
+	StatusCode  int
+	ContentType string
+	Body        []byte
+	CreatedAt   time.Time
+	ExpiresAt   time.Time
+}
+
+func (r *IdempotencyRecord) Completed() bool {
+	return r.StatusCode != 0
+}
+
+type IdempotencyRepository interface {
+	// Claim атомарно занимает ключ клиента. Если ключ занят действующей записью,
+	// возвращает ее и false; истекшая запись заменяется
+	Claim(ctx context.Context, record *IdempotencyRecord) (*IdempotencyRecord, bool, error)
+	Complete(ctx context.Context, record *IdempotencyRecord) error
+	// Extend продлевает незавершенный ключ до expiresAt, пока запрос выполняется
+	Extend(ctx context.Context, client, key, fingerprint string, expiresAt time.Time) error
+	// Release освобождает незавершенный ключ, чтобы клиент мог повторить запрос
+	Release(ctx context.Context, client, key string) error
+	DeleteExpired(ctx context.Context) (int64, error)
+}
@@ -0,0 +1,10 @@
+package domain
+
+// QuoteMetrics - счетчики бизнес-операций сервиса цитат
+type QuoteMetrics interface {
+	QuoteCreated()
+	QuoteDeleted()
+	RandomQuoteServed()
+	// QuoteNotFound: operation - get, random, update или delete
+	QuoteNotFound(operation string)
+}
@@ -0,0 +1,111 @@
+package domain
+
+import "context"
+
+// Role - роль пользователя SSO, выводится из claims JWT
+type Role string
+
+const (
+	RoleViewer Role = "viewer"
+	RoleEditor Role = "editor"
+	RoleAdmin  Role = "admin"
+)
+
+// Permission - действие, которое проверяется на маршруте
+type Permission string
+
+const (
+	PermissionRead       Permission = "read"
+	PermissionCreate     Permission = "create"
+	PermissionUpdate     Permission = "update"
+	PermissionDelete     Permission = "delete"
+	PermissionManageKeys Permission = "manage_keys"
+	// PermissionModifyAny снимает проверку владельца цитаты
+	PermissionModifyAny Permission = "modify_any"
+	// PermissionManageService - служебные настройки (уровень логирования)
+	PermissionManageService Permission = "manage_service"
+)
+
+var rolePermissions = map[Role][]Permission{
+	RoleViewer: {PermissionRead},
+	RoleEditor: {PermissionRead, PermissionCreate, PermissionUpdate, PermissionDelete},
+	RoleAdmin:  {PermissionRead, PermissionCreate, PermissionUpdate, PermissionDelete, PermissionManageKeys, PermissionModifyAny, PermissionManageService},
+}
+
+// Scopes API-ключей повторяют роли: read - viewer, write - editor, admin - admin
+var scopePermissions = map[Scope][]Permission{
+	ScopeRead:  {PermissionRead},
+	ScopeWrite: {PermissionRead, PermissionCreate, PermissionUpdate, PermissionDelete},
+	ScopeAdmin: {PermissionRead, PermissionCreate, PermissionUpdate, PermissionDelete, PermissionManageKeys, PermissionModifyAny, PermissionManageService},
+}
+
+const (
+	AuthMethodAPIKey      = "api_key"
+	AuthMethodJWT         = "jwt"
+	AuthMethodCertificate = "certificate"
+)
+
+// Principal - аутентифицированный автор запроса: пользователь SSO, API-ключ
+// или клиент с сертификатом.
+// Subject стабилен и записывается в события как автор изменения
+type Principal struct {
+	Subject string  `json:"subject"`
+	Name    string  `json:"name,omitempty"`
+	Method  string  `json:"method"`
+	Roles   []Role  `json:"roles,omitempty"`
+	Scopes  []Scope `json:"scopes,omitempty"`
+}
+
+func (p *Principal) Can(permission Permission) bool {
+	for _, role := range p.Roles {
+		if containsPermission(rolePermissions[role], permission) {
+			return true
+		}
+	}
+	for _, scope := range p.Scopes {
+		if containsPermission(scopePermissions[scope], permission) {
+			return true
+		}
+	}
+	return false
+}
+
+// CanModify сообщает, может ли автор изменять цитату: свою или любую
+// с правом modify_any
+func (p *Principal) CanModify(quote *Quote) bool {
+	return p.Can(PermissionModifyAny) || (quote.CreatedBy != "" && quote.CreatedBy == p.Subject)
+}
+
+// IsRole сообщает, известна ли роль сервису
+func IsRole(role Role) bool {
+	_, ok := rolePermissions[role]
+	return ok
+}
+
+func containsPermission(permissions []Permission, permission Permission) bool {
+	for _, p := range permissions {
+		if p == permission {
+			return true
+		}
+	}
+	return false
+}
+
+type principalContextKey struct{}
+
+func ContextWithPrincipal(ctx context.Context, principal *Principal) context.Context {
+	return context.WithValue(ctx, principalContextKey{}, principal)
+}
+
+func PrincipalFromContext(ctx context.Context) (*Principal, bool) {
+	principal, ok := ctx.Value(principalContextKey{}).(*Principal)
+	return principal, ok
+}
+
+// ActorFromContext - Subject автора запроса или пустая строка для анонимного
+func ActorFromContext(ctx context.Context) string {
+	if principal, ok := PrincipalFromContext(ctx); ok {
+		return principal.Subject
+	}
+	return ""
+}
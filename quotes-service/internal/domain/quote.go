@@ -9,43 +9,109 @@ import (
 var (
 	ErrQuoteNotFound = errors.New("quote not found")
 	ErrInvalidQuote  = errors.New("invalid quote data")
+	// ErrQuoteForbidden - цитата создана другим пользователем
+	ErrQuoteForbidden = errors.New("quote belongs to another user")
 )
 
+// CreatedByMe в фильтре подставляется автором запроса
+const CreatedByMe = "me"
+
 type Quote struct {
 	ID        int       `json:"id" db:"id"`
 	Author    string    `json:"author" db:"author"`
 	Text      string    `json:"quote" db:"text"`
+	Tags      []string  `json:"tags" db:"tags"`
+	CreatedBy string    `json:"created_by,omitempty" db:"created_by"`
 	CreatedAt time.Time `json:"created_at" db:"created_at"`
 	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
 }
 
 type CreateQuoteRequest struct {
-	Author string `json:"author"`
-	Quote  string `json:"quote"`
+	Author string   `json:"author"`
+	Quote  string   `json:"quote"`
+	Tags   []string `json:"tags,omitempty"`
 }
 
 func (r *CreateQuoteRequest) Validate() error {
-	r.Author = strings.TrimSpace(r.Author)
-	r.Quote = strings.TrimSpace(r.Quote)
+	return validateQuoteFields(&r.Author, &r.Quote, &r.Tags)
+}
+
+type UpdateQuoteRequest struct {
+	Author string   `json:"author"`
+	Quote  string   `json:"quote"`
+	Tags   []string `json:"tags,omitempty"`
+}
+
+func (r *UpdateQuoteRequest) Validate() error {
+	return validateQuoteFields(&r.Author, &r.Quote, &r.Tags)
+}
+
+func validateQuoteFields(author, quote *string, tags *[]string) error {
+	*author = strings.TrimSpace(*author)
+	*quote = strings.TrimSpace(*quote)
 
-	if r.Author == "" {
+	if *author == "" {
 		return errors.New("author is required")
 	}
-	if r.Quote == "" {
+	if *quote == "" {
 		return errors.New("quote is required")
 	}
-	if len(r.Author) > 100 {
+	if len(*author) > 100 {
 		return errors.New("author must be less than 100 characters")
 	}
-	if len(r.Quote) > 1000 {
+	if len(*quote) > 1000 {
 		return errors.New("quote must be less than 1000 characters")
 	}
 
+	normalized, err := NormalizeTags(*tags)
+	if err != nil {
+		return err
+	}
+	*tags = normalized
+
 	return nil
 }
 
+// NormalizeTags приводит теги к нижнему регистру, убирает пустые и повторы
+func NormalizeTags(tags []string) ([]string, error) {
+	normalized := make([]string, 0, len(tags))
+	seen := make(map[string]bool, len(tags))
+	for _, tag := range tags {
+		tag = strings.ToLower(strings.TrimSpace(tag))
+		if tag == "" || seen[tag] {
+			continue
+		}
+		if len(tag) > 50 {
+			return nil, errors.New("tag must be less than 50 characters")
+		}
+		seen[tag] = true
+		normalized = append(normalized, tag)
+	}
+
+	if len(normalized) > 10 {
+		return nil, errors.New("quote can have at most 10 tags")
+	}
+
+	return normalized, nil
+}
+
 type QuoteFilter struct {
-	Author string
+	Author    string
+	Tag       string
+	CreatedBy string
+	Limit     int
+	Offset    int
+}
+
+// Author - автор и число его цитат
+type Author struct {
+	Name       string `json:"name"`
+	QuoteCount int    `json:"quote_count"`
+}
+
+// AuthorFilter: Names ограничивает выборку точными именами авторов
+type AuthorFilter struct {
+	Names  []string
 	Limit  int
 	Offset int
 }
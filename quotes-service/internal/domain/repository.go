@@ -7,7 +7,32 @@ type QuoteRepository interface {
 	GetAll(ctx context.Context, filter QuoteFilter) ([]*Quote, error)
 	GetByID(ctx context.Context, id int) (*Quote, error)
 	GetRandom(ctx context.Context) (*Quote, error)
+	Update(ctx context.Context, quote *Quote) (*Quote, error)
 	Delete(ctx context.Context, id int) error
 	Count(ctx context.Context, filter QuoteFilter) (int, error)
+	// ListAuthors возвращает авторов по убыванию числа цитат
+	ListAuthors(ctx context.Context, filter AuthorFilter) ([]*Author, error)
+	// GetByAuthors возвращает до limit последних цитат каждого автора одним запросом
+	GetByAuthors(ctx context.Context, authors []string, limit int) ([]*Quote, error)
 	HealthCheck(ctx context.Context) error
 }
+
+// CacheStatsProvider реализуют кэширующие обертки над репозиторием
+type CacheStatsProvider interface {
+	CacheStats() CacheStats
+}
+
+// CacheInvalidator реализуют кэширующие обертки. Обертка сбрасывает кэш при
+// записи, а сервис вызывает Invalidate еще раз после коммита: сброс только
+// внутри транзакции позволил бы параллельному чтению вернуть в кэш строки до коммита
+type CacheInvalidator interface {
+	Invalidate(ctx context.Context, ids ...int)
+}
+
+type CacheStats struct {
+	Hits      uint64  `json:"hits"`
+	Misses    uint64  `json:"misses"`
+	Coalesced uint64  `json:"coalesced"`
+	Entries   int     `json:"entries"`
+	HitRatio  float64 `json:"hit_ratio"`
+}
@@ -0,0 +1,120 @@
+package domain
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/url"
+	"strings"
+	"time"
+)
+
+var (
+	ErrWebhookNotFound  = errors.New("webhook subscription not found")
+	ErrDeliveryNotFound = errors.New("webhook delivery not found")
+	ErrDeliveryNotDead  = errors.New("webhook delivery is not a dead letter")
+	ErrInvalidWebhook   = errors.New("invalid webhook data")
+)
+
+type DeliveryStatus string
+
+const (
+	DeliveryPending   DeliveryStatus = "pending"
+	DeliveryDelivered DeliveryStatus = "delivered"
+	DeliveryDead      DeliveryStatus = "dead"
+)
+
+type WebhookSubscription struct {
+	ID         int         `json:"id"`
+	URL        string      `json:"url"`
+	Secret     string      `json:"-"`
+	EventTypes []EventType `json:"events"`
+	Active     bool        `json:"active"`
+	CreatedAt  time.Time   `json:"created_at"`
+	UpdatedAt  time.Time   `json:"updated_at"`
+}
+
+// Matches сообщает, подписан ли получатель на событие; пустой список - на все
+func (s *WebhookSubscription) Matches(eventType EventType) bool {
+	if !s.Active {
+		return false
+	}
+	if len(s.EventTypes) == 0 {
+		return true
+	}
+	for _, t := range s.EventTypes {
+		if t == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+type WebhookDelivery struct {
+	ID             int64           `json:"id"`
+	SubscriptionID int             `json:"subscription_id"`
+	EventID        int64           `json:"event_id"`
+	EventType      EventType       `json:"event_type"`
+	Payload        json.RawMessage `json:"payload"`
+	Status         DeliveryStatus  `json:"status"`
+	Attempts       int             `json:"attempts"`
+	ResponseStatus int             `json:"response_status,omitempty"`
+	LastError      string          `json:"last_error,omitempty"`
+	NextAttemptAt  time.Time       `json:"next_attempt_at"`
+	LastAttemptAt  *time.Time      `json:"last_attempt_at,omitempty"`
+	DeliveredAt    *time.Time      `json:"delivered_at,omitempty"`
+	CreatedAt      time.Time       `json:"created_at"`
+}
+
+type WebhookRequest struct {
+	URL    string      `json:"url"`
+	Secret string      `json:"secret"`
+	Events []EventType `json:"events"`
+	Active *bool       `json:"active"`
+}
+
+func (r *WebhookRequest) Validate() error {
+	r.URL = strings.TrimSpace(r.URL)
+
+	u, err := url.Parse(r.URL)
+	if r.URL == "" || err != nil || u.Host == "" || (u.Scheme != "http" && u.Scheme != "https") {
+		return errors.New("url must be an absolute http(s) URL")
+	}
+	if len(r.URL) > 2000 {
+		return errors.New("url must be less than 2000 characters")
+	}
+	if r.Secret != "" && len(r.Secret) < 16 {
+		return errors.New("secret must be at least 16 characters")
+	}
+	for _, t := range r.Events {
+		switch t {
+		case EventQuoteCreated, EventQuoteUpdated, EventQuoteDeleted:
+		default:
+			return errors.New("unknown event type: " + string(t))
+		}
+	}
+
+	return nil
+}
+
+type DeliveryFilter struct {
+	SubscriptionID int
+	Status         DeliveryStatus
+	Limit          int
+}
+
+type WebhookRepository interface {
+	CreateSubscription(ctx context.Context, sub *WebhookSubscription) (*WebhookSubscription, error)
+	GetSubscription(ctx context.Context, id int) (*WebhookSubscription, error)
+	ListSubscriptions(ctx context.Context) ([]*WebhookSubscription, error)
+	UpdateSubscription(ctx context.Context, sub *WebhookSubscription) (*WebhookSubscription, error)
+	DeleteSubscription(ctx context.Context, id int) error
+
+	// EnqueueDeliveries идемпотентна по паре (подписка, событие)
+	EnqueueDeliveries(ctx context.Context, deliveries ...*WebhookDelivery) error
+	// ClaimDueDeliveries резервирует готовые к отправке доставки на время lease
+	ClaimDueDeliveries(ctx context.Context, limit int, lease time.Duration) ([]*WebhookDelivery, error)
+	SaveDeliveryResult(ctx context.Context, delivery *WebhookDelivery) error
+	ListDeliveries(ctx context.Context, filter DeliveryFilter) ([]*WebhookDelivery, error)
+	RequeueDelivery(ctx context.Context, id int64) (*WebhookDelivery, error)
+}
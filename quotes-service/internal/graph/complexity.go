@@ -0,0 +1,171 @@
+package graph
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/vektah/gqlparser/v2/ast"
+	"github.com/vektah/gqlparser/v2/parser"
+)
+
+var (
+	ErrQueryTooDeep    = errors.New("query is too deep")
+	ErrQueryTooComplex = errors.New("query is too complex")
+)
+
+// QueryStats - оценка запроса до выполнения. Каждое поле стоит 1,
+// поля со списком умножают стоимость вложенной выборки на аргумент first.
+type QueryStats struct {
+	Depth      int
+	Complexity int
+	Mutation   bool
+	// Fields - корневые поля операции, по ним проверяются права на мутации
+	Fields []string
+}
+
+// Analyze оценивает операцию; синтаксические ошибки не возвращаются,
+// их сообщит исполнитель схемы
+func Analyze(query, operationName string, variables map[string]interface{}) QueryStats {
+	doc, err := parser.ParseQuery(&ast.Source{Input: query})
+	if err != nil {
+		return QueryStats{}
+	}
+
+	var op *ast.OperationDefinition
+	if operationName != "" {
+		op = doc.Operations.ForName(operationName)
+	} else if len(doc.Operations) == 1 {
+		op = doc.Operations[0]
+	}
+	if op == nil {
+		return QueryStats{}
+	}
+
+	a := analyzer{doc: doc, op: op, variables: variables}
+	depth, complexity := a.selectionSet(op.SelectionSet, 0, map[string]bool{})
+
+	return QueryStats{
+		Depth:      depth,
+		Complexity: complexity,
+		Mutation:   op.Operation == ast.Mutation,
+		Fields:     a.rootFields(op.SelectionSet, nil, map[string]bool{}),
+	}
+}
+
+func (s QueryStats) Check(limits Config) error {
+	if limits.MaxDepth > 0 && s.Depth > limits.MaxDepth {
+		return fmt.Errorf("%w: depth %d exceeds limit %d", ErrQueryTooDeep, s.Depth, limits.MaxDepth)
+	}
+	if limits.MaxComplexity > 0 && s.Complexity > limits.MaxComplexity {
+		return fmt.Errorf("%w: complexity %d exceeds limit %d", ErrQueryTooComplex, s.Complexity, limits.MaxComplexity)
+	}
+	return nil
+}
+
+type analyzer struct {
+	doc       *ast.QueryDocument
+	op        *ast.OperationDefinition
+	variables map[string]interface{}
+}
+
+func (a *analyzer) selectionSet(set ast.SelectionSet, level int, visiting map[string]bool) (int, int) {
+	maxDepth, total := level, 0
+
+	for _, selection := range set {
+		var depth, cost int
+
+		switch sel := selection.(type) {
+		case *ast.Field:
+			// Интроспекция не ограничивается
+			if len(sel.Name) > 1 && sel.Name[:2] == "__" {
+				continue
+			}
+			depth, cost = a.selectionSet(sel.SelectionSet, level+1, visiting)
+			cost = 1 + cost*a.multiplier(sel)
+		case *ast.InlineFragment:
+			depth, cost = a.selectionSet(sel.SelectionSet, level, visiting)
+		case *ast.FragmentSpread:
+			fragment := a.doc.Fragments.ForName(sel.Name)
+			if fragment == nil || visiting[sel.Name] {
+				continue
+			}
+			visiting[sel.Name] = true
+			depth, cost = a.selectionSet(fragment.SelectionSet, level, visiting)
+			delete(visiting, sel.Name)
+		}
+
+		if depth > maxDepth {
+			maxDepth = depth
+		}
+		total += cost
+	}
+
+	return maxDepth, total
+}
+
+func (a *analyzer) rootFields(set ast.SelectionSet, fields []string, visiting map[string]bool) []string {
+	for _, selection := range set {
+		switch sel := selection.(type) {
+		case *ast.Field:
+			fields = append(fields, sel.Name)
+		case *ast.InlineFragment:
+			fields = a.rootFields(sel.SelectionSet, fields, visiting)
+		case *ast.FragmentSpread:
+			fragment := a.doc.Fragments.ForName(sel.Name)
+			if fragment == nil || visiting[sel.Name] {
+				continue
+			}
+			visiting[sel.Name] = true
+			fields = a.rootFields(fragment.SelectionSet, fields, visiting)
+		}
+	}
+	return fields
+}
+
+// multiplier - размер списка, возвращаемого полем: значение first
+// или значение по умолчанию из схемы
+func (a *analyzer) multiplier(field *ast.Field) int {
+	if _, ok := listFields[field.Name]; !ok {
+		return 1
+	}
+
+	first := defaultPageSize
+	if arg := field.Arguments.ForName("first"); arg != nil {
+		if value, ok := a.intValue(arg.Value); ok {
+			first = value
+		}
+	}
+
+	if first < 1 {
+		return 1
+	}
+	if first > maxPageSize {
+		return maxPageSize
+	}
+	return first
+}
+
+func (a *analyzer) intValue(value *ast.Value) (int, bool) {
+	if value.Kind == ast.Variable {
+		if v, ok := a.variables[value.Raw]; ok {
+			switch n := v.(type) {
+			case float64:
+				return int(n), true
+			case int:
+				return n, true
+			}
+			return 0, false
+		}
+		if def := a.op.VariableDefinitions.ForName(value.Raw); def != nil && def.DefaultValue != nil {
+			return a.intValue(def.DefaultValue)
+		}
+		return 0, false
+	}
+
+	v, err := value.Value(nil)
+	if err != nil {
+		return 0, false
+	}
+	n, ok := v.(int64)
+	return int(n), ok
+}
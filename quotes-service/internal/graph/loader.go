@@ -0,0 +1,91 @@
+package graph
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// loader - простой dataloader: ключи, запрошенные резолверами в пределах
+// окна wait, загружаются одним вызовом fetch. Результаты кэшируются на
+// время запроса. Prime добавляет ключи в следующую пачку без ожидания,
+// чтобы список, резолвящийся с ограниченным параллелизмом, грузился целиком.
+type loader[K comparable, V any] struct {
+	fetch func(ctx context.Context, keys []K) (map[K]V, error)
+	wait  time.Duration
+
+	mu      sync.Mutex
+	results map[K]*loadResult[V]
+	pending []K
+	timer   bool
+}
+
+type loadResult[V any] struct {
+	done  chan struct{}
+	value V
+	err   error
+}
+
+func newLoader[K comparable, V any](wait time.Duration, fetch func(ctx context.Context, keys []K) (map[K]V, error)) *loader[K, V] {
+	return &loader[K, V]{
+		fetch:   fetch,
+		wait:    wait,
+		results: make(map[K]*loadResult[V]),
+	}
+}
+
+func (l *loader[K, V]) Prime(keys ...K) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	for _, key := range keys {
+		l.addLocked(key)
+	}
+}
+
+func (l *loader[K, V]) Load(ctx context.Context, key K) (V, error) {
+	l.mu.Lock()
+	result := l.addLocked(key)
+	if len(l.pending) > 0 && !l.timer {
+		l.timer = true
+		time.AfterFunc(l.wait, func() { l.dispatch(ctx) })
+	}
+	l.mu.Unlock()
+
+	select {
+	case <-result.done:
+		return result.value, result.err
+	case <-ctx.Done():
+		var zero V
+		return zero, ctx.Err()
+	}
+}
+
+func (l *loader[K, V]) addLocked(key K) *loadResult[V] {
+	if result, ok := l.results[key]; ok {
+		return result
+	}
+
+	result := &loadResult[V]{done: make(chan struct{})}
+	l.results[key] = result
+	l.pending = append(l.pending, key)
+	return result
+}
+
+func (l *loader[K, V]) dispatch(ctx context.Context) {
+	l.mu.Lock()
+	keys := l.pending
+	l.pending = nil
+	l.timer = false
+	l.mu.Unlock()
+
+	values, err := l.fetch(ctx, keys)
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for _, key := range keys {
+		result := l.results[key]
+		result.value, result.err = values[key], err
+		close(result.done)
+	}
+}
@@ -0,0 +1,398 @@
+package graph
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"strconv"
+	"strings"
+
+	"quotes-service/internal/domain"
+	"quotes-service/internal/infrastructure/logger"
+	"quotes-service/internal/service"
+
+	"github.com/graph-gophers/graphql-go"
+)
+
+// Resolver - корневой резолвер запросов и мутаций
+type Resolver struct {
+	service *service.QuoteService
+	logger  *logger.Logger
+}
+
+// Error - ошибка GraphQL с кодом в extensions
+type Error struct {
+	Message string
+	Code    string
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+func (e *Error) Extensions() map[string]interface{} {
+	return map[string]interface{}{"code": e.Code}
+}
+
+func (r *Resolver) Quote(ctx context.Context, args struct{ ID graphql.ID }) (*quoteResolver, error) {
+	id, err := strconv.Atoi(string(args.ID))
+	if err != nil {
+		return nil, &Error{Message: "invalid quote ID", Code: "BAD_USER_INPUT"}
+	}
+
+	quote, err := r.service.GetQuote(ctx, id)
+	if errors.Is(err, domain.ErrQuoteNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, r.toError(err)
+	}
+
+	return &quoteResolver{quote: quote}, nil
+}
+
+type quotesArgs struct {
+	Filter *struct {
+		Author    *string
+		Tag       *string
+		CreatedBy *string
+	}
+	First int32
+	After *string
+}
+
+func (r *Resolver) Quotes(ctx context.Context, args quotesArgs) (*connectionResolver, error) {
+	offset := 0
+	if args.After != nil {
+		var err error
+		if offset, err = decodeCursor(*args.After); err != nil {
+			return nil, &Error{Message: "invalid cursor", Code: "BAD_USER_INPUT"}
+		}
+	}
+
+	filter := domain.QuoteFilter{
+		Limit:  pageSize(args.First),
+		Offset: offset,
+	}
+	if args.Filter != nil {
+		if args.Filter.Author != nil {
+			filter.Author = *args.Filter.Author
+		}
+		if args.Filter.Tag != nil {
+			filter.Tag = *args.Filter.Tag
+		}
+		if args.Filter.CreatedBy != nil {
+			filter.CreatedBy = *args.Filter.CreatedBy
+		}
+	}
+
+	quotes, err := r.service.GetAllQuotes(ctx, filter)
+	if err != nil {
+		return nil, r.toError(err)
+	}
+
+	return &connectionResolver{
+		resolver: r,
+		filter:   filter,
+		quotes:   newQuoteResolvers(quotes),
+	}, nil
+}
+
+func (r *Resolver) RandomQuote(ctx context.Context) (*quoteResolver, error) {
+	quote, err := r.service.GetRandomQuote(ctx)
+	if errors.Is(err, domain.ErrQuoteNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, r.toError(err)
+	}
+
+	return &quoteResolver{quote: quote}, nil
+}
+
+func (r *Resolver) Authors(ctx context.Context, args struct {
+	First  int32
+	Offset int32
+}) ([]*authorResolver, error) {
+	authors, err := r.service.ListAuthors(ctx, domain.AuthorFilter{
+		Limit:  pageSize(args.First),
+		Offset: max(int(args.Offset), 0),
+	})
+	if err != nil {
+		return nil, r.toError(err)
+	}
+
+	names := make([]string, 0, len(authors))
+	for _, author := range authors {
+		names = append(names, author.Name)
+	}
+
+	resolvers := make([]*authorResolver, 0, len(authors))
+	for _, author := range authors {
+		resolvers = append(resolvers, &authorResolver{name: author.Name, author: author, siblings: names})
+	}
+	return resolvers, nil
+}
+
+func (r *Resolver) CreateQuote(ctx context.Context, args struct {
+	Input struct {
+		Author string
+		Quote  string
+		Tags   *[]string
+	}
+}) (*quoteResolver, error) {
+	req := domain.CreateQuoteRequest{Author: args.Input.Author, Quote: args.Input.Quote}
+	if args.Input.Tags != nil {
+		req.Tags = *args.Input.Tags
+	}
+
+	quote, err := r.service.CreateQuote(requestContext(ctx), req)
+	if err != nil {
+		return nil, r.toError(err)
+	}
+
+	return &quoteResolver{quote: quote}, nil
+}
+
+func (r *Resolver) DeleteQuote(ctx context.Context, args struct{ ID graphql.ID }) (bool, error) {
+	id, err := strconv.Atoi(string(args.ID))
+	if err != nil {
+		return false, &Error{Message: "invalid quote ID", Code: "BAD_USER_INPUT"}
+	}
+
+	if err := r.service.DeleteQuote(requestContext(ctx), id); err != nil {
+		return false, r.toError(err)
+	}
+
+	return true, nil
+}
+
+// toError скрывает внутренние ошибки так же, как HTTP-хендлеры
+func (r *Resolver) toError(err error) error {
+	switch {
+	case errors.Is(err, context.DeadlineExceeded):
+		return &Error{Message: "Request timed out", Code: "TIMEOUT"}
+	case errors.Is(err, domain.ErrQuoteNotFound):
+		return &Error{Message: "Quote not found", Code: "NOT_FOUND"}
+	case errors.Is(err, domain.ErrInvalidQuote):
+		return &Error{Message: err.Error(), Code: "BAD_USER_INPUT"}
+	case errors.Is(err, domain.ErrQuoteForbidden):
+		return &Error{Message: "Only the owner or an admin can modify this quote", Code: "FORBIDDEN"}
+	case errors.Is(err, domain.ErrUnauthenticated):
+		return &Error{Message: "Authentication required", Code: "UNAUTHENTICATED"}
+	default:
+		r.logger.Error("GraphQL resolver failed", "error", err)
+		return &Error{Message: "Internal server error", Code: "INTERNAL"}
+	}
+}
+
+type connectionResolver struct {
+	resolver *Resolver
+	filter   domain.QuoteFilter
+	quotes   []*quoteResolver
+}
+
+func (c *connectionResolver) Edges() []*edgeResolver {
+	edges := make([]*edgeResolver, 0, len(c.quotes))
+	for i, quote := range c.quotes {
+		edges = append(edges, &edgeResolver{cursor: encodeCursor(c.filter.Offset + i + 1), node: quote})
+	}
+	return edges
+}
+
+func (c *connectionResolver) Nodes() []*quoteResolver {
+	return c.quotes
+}
+
+func (c *connectionResolver) PageInfo(ctx context.Context) (*pageInfoResolver, error) {
+	info := &pageInfoResolver{}
+	if len(c.quotes) == 0 {
+		return info, nil
+	}
+
+	end := encodeCursor(c.filter.Offset + len(c.quotes))
+	info.endCursor = &end
+
+	if len(c.quotes) == c.filter.Limit {
+		total, err := c.totalCount(ctx)
+		if err != nil {
+			return nil, err
+		}
+		info.hasNextPage = c.filter.Offset+len(c.quotes) < total
+	}
+	return info, nil
+}
+
+func (c *connectionResolver) TotalCount(ctx context.Context) (int32, error) {
+	total, err := c.totalCount(ctx)
+	return int32(total), err
+}
+
+func (c *connectionResolver) totalCount(ctx context.Context) (int, error) {
+	total, err := c.resolver.service.CountQuotes(ctx, c.filter)
+	if err != nil {
+		return 0, c.resolver.toError(err)
+	}
+	return total, nil
+}
+
+type edgeResolver struct {
+	cursor string
+	node   *quoteResolver
+}
+
+func (e *edgeResolver) Cursor() string {
+	return e.cursor
+}
+
+func (e *edgeResolver) Node() *quoteResolver {
+	return e.node
+}
+
+type pageInfoResolver struct {
+	hasNextPage bool
+	endCursor   *string
+}
+
+func (p *pageInfoResolver) HasNextPage() bool {
+	return p.hasNextPage
+}
+
+func (p *pageInfoResolver) EndCursor() *string {
+	return p.endCursor
+}
+
+type quoteResolver struct {
+	quote *domain.Quote
+	// Авторы соседних цитат списка - грузятся одной пачкой
+	siblings []string
+}
+
+func newQuoteResolvers(quotes []*domain.Quote) []*quoteResolver {
+	seen := make(map[string]bool)
+	var authors []string
+	for _, quote := range quotes {
+		if !seen[quote.Author] {
+			seen[quote.Author] = true
+			authors = append(authors, quote.Author)
+		}
+	}
+
+	resolvers := make([]*quoteResolver, 0, len(quotes))
+	for _, quote := range quotes {
+		resolvers = append(resolvers, &quoteResolver{quote: quote, siblings: authors})
+	}
+	return resolvers
+}
+
+func (q *quoteResolver) ID() graphql.ID {
+	return graphql.ID(strconv.Itoa(q.quote.ID))
+}
+
+func (q *quoteResolver) Text() string {
+	return q.quote.Text
+}
+
+func (q *quoteResolver) Author() *authorResolver {
+	return &authorResolver{name: q.quote.Author, siblings: q.siblings}
+}
+
+func (q *quoteResolver) Tags() []string {
+	if q.quote.Tags == nil {
+		return []string{}
+	}
+	return q.quote.Tags
+}
+
+func (q *quoteResolver) CreatedBy() *string {
+	if q.quote.CreatedBy == "" {
+		return nil
+	}
+	return &q.quote.CreatedBy
+}
+
+func (q *quoteResolver) CreatedAt() graphql.Time {
+	return graphql.Time{Time: q.quote.CreatedAt}
+}
+
+func (q *quoteResolver) UpdatedAt() graphql.Time {
+	return graphql.Time{Time: q.quote.UpdatedAt}
+}
+
+type authorResolver struct {
+	name     string
+	author   *domain.Author
+	siblings []string
+}
+
+func (a *authorResolver) Name() string {
+	return a.name
+}
+
+func (a *authorResolver) QuoteCount(ctx context.Context) (int32, error) {
+	if a.author != nil {
+		return int32(a.author.QuoteCount), nil
+	}
+
+	l, err := loadersFrom(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	l.authors.Prime(a.siblings...)
+	author, err := l.authors.Load(ctx, a.name)
+	if err != nil {
+		return 0, l.resolver.toError(err)
+	}
+	if author == nil {
+		return 0, nil
+	}
+	return int32(author.QuoteCount), nil
+}
+
+func (a *authorResolver) Quotes(ctx context.Context, args struct{ First int32 }) ([]*quoteResolver, error) {
+	l, err := loadersFrom(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	ql := l.quotesOf(pageSize(args.First))
+	ql.Prime(a.siblings...)
+	quotes, err := ql.Load(ctx, a.name)
+	if err != nil {
+		return nil, l.resolver.toError(err)
+	}
+
+	resolvers := make([]*quoteResolver, 0, len(quotes))
+	for _, quote := range quotes {
+		resolvers = append(resolvers, &quoteResolver{quote: quote, siblings: []string{a.name}})
+	}
+	return resolvers, nil
+}
+
+func pageSize(first int32) int {
+	switch {
+	case first <= 0:
+		return defaultPageSize
+	case first > maxPageSize:
+		return maxPageSize
+	}
+	return int(first)
+}
+
+func encodeCursor(offset int) string {
+	return base64.RawURLEncoding.EncodeToString([]byte("offset:" + strconv.Itoa(offset)))
+}
+
+func decodeCursor(cursor string) (int, error) {
+	data, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return 0, err
+	}
+
+	offset, err := strconv.Atoi(strings.TrimPrefix(string(data), "offset:"))
+	if err != nil || offset < 0 {
+		return 0, errors.New("invalid cursor")
+	}
+	return offset, nil
+}
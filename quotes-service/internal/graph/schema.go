@@ -0,0 +1,154 @@
+package graph
+
+import (
+	"context"
+	_ "embed"
+	"errors"
+	"sync"
+	"time"
+
+	"quotes-service/internal/domain"
+	"quotes-service/internal/infrastructure/logger"
+	"quotes-service/internal/service"
+
+	"github.com/graph-gophers/graphql-go"
+)
+
+//go:embed schema.graphql
+var schemaSDL string
+
+const (
+	defaultPageSize = 20
+	maxPageSize     = 100
+	batchWait       = 2 * time.Millisecond
+)
+
+// Поля-списки с аргументом first; учитываются при оценке сложности
+var listFields = map[string]struct{}{
+	"quotes":  {},
+	"authors": {},
+}
+
+type Config struct {
+	MaxDepth      int
+	MaxComplexity int
+}
+
+// Executor проверяет лимиты запроса и выполняет его со свежими
+// dataloader'ами
+type Executor struct {
+	schema   *graphql.Schema
+	resolver *Resolver
+	config   Config
+}
+
+func NewExecutor(service *service.QuoteService, config Config, logger *logger.Logger) (*Executor, error) {
+	resolver := &Resolver{service: service, logger: logger}
+
+	schema, err := graphql.ParseSchema(schemaSDL, resolver, graphql.MaxParallelism(20))
+	if err != nil {
+		return nil, err
+	}
+
+	return &Executor{
+		schema:   schema,
+		resolver: resolver,
+		config:   config,
+	}, nil
+}
+
+// Exec возвращает ошибку лимитов до выполнения запроса
+func (e *Executor) Exec(ctx context.Context, query, operationName string, variables map[string]interface{}) (QueryStats, *graphql.Response, error) {
+	stats := Analyze(query, operationName, variables)
+	if err := stats.Check(e.config); err != nil {
+		return stats, nil, err
+	}
+
+	ctx = e.withLoaders(ctx)
+	if stats.Mutation {
+		// graphql-go отбрасывает весь ответ, если контекст истек к концу
+		// выполнения, вместе с уже зафиксированными мутациями. Схема
+		// выполняется без отмены, а резолверы мутаций берут контекст запроса
+		ctx = context.WithoutCancel(context.WithValue(ctx, requestKey{}, ctx))
+	}
+	return stats, e.schema.Exec(ctx, query, operationName, variables), nil
+}
+
+// TimedOut сообщает, что запрос или хотя бы один резолвер не успел до дедлайна
+func TimedOut(resp *graphql.Response) bool {
+	for _, queryErr := range resp.Errors {
+		var resolverErr *Error
+		if errors.Is(queryErr.Err, context.DeadlineExceeded) ||
+			errors.As(queryErr.ResolverError, &resolverErr) && resolverErr.Code == "TIMEOUT" {
+			return true
+		}
+	}
+	return false
+}
+
+type requestKey struct{}
+
+// requestContext возвращает контекст запроса с его дедлайном и отменой
+func requestContext(ctx context.Context) context.Context {
+	if request, ok := ctx.Value(requestKey{}).(context.Context); ok {
+		return request
+	}
+	return ctx
+}
+
+type loadersKey struct{}
+
+type loaders struct {
+	resolver *Resolver
+	authors  *loader[string, *domain.Author]
+
+	mu           sync.Mutex
+	authorQuotes map[int]*loader[string, []*domain.Quote]
+}
+
+// withLoaders создает dataloader'ы на время одного GraphQL-запроса
+func (e *Executor) withLoaders(ctx context.Context) context.Context {
+	l := &loaders{
+		resolver:     e.resolver,
+		authorQuotes: make(map[int]*loader[string, []*domain.Quote]),
+	}
+
+	l.authors = newLoader(batchWait, func(ctx context.Context, names []string) (map[string]*domain.Author, error) {
+		authors, err := e.resolver.service.ListAuthors(ctx, domain.AuthorFilter{Names: names, Limit: len(names)})
+		if err != nil {
+			return nil, err
+		}
+
+		result := make(map[string]*domain.Author, len(authors))
+		for _, author := range authors {
+			result[author.Name] = author
+		}
+		return result, nil
+	})
+
+	return context.WithValue(ctx, loadersKey{}, l)
+}
+
+func loadersFrom(ctx context.Context) (*loaders, error) {
+	if l, ok := ctx.Value(loadersKey{}).(*loaders); ok {
+		return l, nil
+	}
+	return nil, errors.New("graphql loaders are not configured")
+}
+
+// quotesOf - загрузчик цитат авторов для конкретного first; запросы
+// с разными first батчатся отдельно
+func (l *loaders) quotesOf(limit int) *loader[string, []*domain.Quote] {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if ql, ok := l.authorQuotes[limit]; ok {
+		return ql
+	}
+
+	ql := newLoader(batchWait, func(ctx context.Context, names []string) (map[string][]*domain.Quote, error) {
+		return l.resolver.service.GetQuotesByAuthors(ctx, names, limit)
+	})
+	l.authorQuotes[limit] = ql
+	return ql
+}
@@ -0,0 +1,108 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"quotes-service/internal/domain"
+	"quotes-service/internal/infrastructure/logger"
+	"quotes-service/internal/service"
+
+	"github.com/gorilla/mux"
+)
+
+// APIKeyHandler - админские эндпоинты выпуска и отзыва ключей
+type APIKeyHandler struct {
+	service *service.APIKeyService
+	auth    *Authenticator
+	logger  *logger.Logger
+}
+
+// Ключ открытым текстом отдается только в ответе на выпуск
+type issuedAPIKeyResponse struct {
+	*domain.APIKey
+	Key string `json:"key"`
+}
+
+func NewAPIKeyHandler(service *service.APIKeyService, auth *Authenticator, logger *logger.Logger) *APIKeyHandler {
+	return &APIKeyHandler{
+		service: service,
+		auth:    auth,
+		logger:  logger,
+	}
+}
+
+func (h *APIKeyHandler) RegisterRoutes(router *mux.Router) {
+	router.Handle("/admin/api-keys", h.admin(h.IssueKey)).Methods("POST")
+	router.Handle("/admin/api-keys", h.admin(h.ListKeys)).Methods("GET")
+	router.Handle("/admin/api-keys/{id:[0-9]+}", h.admin(h.RevokeKey)).Methods("DELETE")
+}
+
+func (h *APIKeyHandler) admin(next http.HandlerFunc) http.Handler {
+	return h.auth.Require(domain.PermissionManageKeys, next)
+}
+
+func (h *APIKeyHandler) IssueKey(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var req domain.APIKeyRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.sendError(w, http.StatusBadRequest, "Invalid JSON format")
+		return
+	}
+
+	key, raw, err := h.service.IssueKey(ctx, req)
+	if err != nil {
+		h.handleError(w, r, err, "Failed to issue API key")
+		return
+	}
+
+	h.sendSuccess(w, http.StatusCreated, issuedAPIKeyResponse{APIKey: key, Key: raw})
+}
+
+func (h *APIKeyHandler) ListKeys(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	keys, err := h.service.ListKeys(ctx)
+	if err != nil {
+		h.handleError(w, r, err, "Failed to list API keys")
+		return
+	}
+
+	h.sendSuccess(w, http.StatusOK, keys)
+}
+
+func (h *APIKeyHandler) RevokeKey(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	id, _ := strconv.Atoi(mux.Vars(r)["id"])
+	key, err := h.service.RevokeKey(ctx, id)
+	if err != nil {
+		h.handleError(w, r, err, "Failed to revoke API key")
+		return
+	}
+
+	h.sendSuccess(w, http.StatusOK, key)
+}
+
+func (h *APIKeyHandler) handleError(w http.ResponseWriter, r *http.Request, err error, message string) {
+	switch {
+	case errors.Is(err, domain.ErrInvalidAPIKey):
+		h.sendError(w, http.StatusBadRequest, err.Error())
+	case errors.Is(err, domain.ErrAPIKeyNotFound):
+		h.sendError(w, http.StatusNotFound, "API key not found")
+	default:
+		h.logger.ErrorContext(r.Context(), message, "error", err)
+		writeServerError(w, r, h.logger, err, message)
+	}
+}
+
+func (h *APIKeyHandler) sendSuccess(w http.ResponseWriter, statusCode int, data interface{}) {
+	writeResponse(w, h.logger, statusCode, Response{Data: data})
+}
+
+func (h *APIKeyHandler) sendError(w http.ResponseWriter, statusCode int, message string) {
+	writeResponse(w, h.logger, statusCode, Response{Error: message})
+}
@@ -0,0 +1,189 @@
+package handler
+
+import (
+	"crypto/x509"
+	"errors"
+	"net/http"
+	"strings"
+
+	"quotes-service/internal/auth"
+	"quotes-service/internal/domain"
+	"quotes-service/internal/infrastructure/logger"
+)
+
+// AuthConfig: при выключенной аутентификации запросы к API пропускаются как раньше
+type AuthConfig struct {
+	Enabled      bool
+	ProtectReads bool
+}
+
+// Authenticator проверяет учетные данные из заголовков Authorization: Bearer
+// (JWT или API-ключ) и X-API-Key, а без них - клиентский сертификат mTLS,
+// и сверяет права автора с правами маршрута
+type Authenticator struct {
+	authn   *auth.Authenticator
+	config  AuthConfig
+	limiter *RateLimiter
+	logger  *logger.Logger
+}
+
+type AuthOption func(*Authenticator)
+
+// WithFailureLimit считает неудачные аутентификации по IP в limiter и
+// отвечает 429, не проверяя учетные данные, когда лимит исчерпан: иначе
+// перебор ключей шел бы без ограничений и каждый раз обращался к БД
+func WithFailureLimit(limiter *RateLimiter) AuthOption {
+	return func(a *Authenticator) {
+		a.limiter = limiter
+	}
+}
+
+func NewAuthenticator(authn *auth.Authenticator, config AuthConfig, logger *logger.Logger, options ...AuthOption) *Authenticator {
+	a := &Authenticator{
+		authn:  authn,
+		config: config,
+		logger: logger,
+	}
+	for _, option := range options {
+		option(a)
+	}
+	return a
+}
+
+// Middleware выводит право из метода: чтение - read, POST - create,
+// PUT/PATCH - update, DELETE - delete
+func (a *Authenticator) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		a.Require(methodPermission(r.Method), next).ServeHTTP(w, r)
+	})
+}
+
+// Require пропускает запрос, только если у автора есть право permission
+func (a *Authenticator) Require(permission domain.Permission, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		principal, err := a.Authorize(r, permission)
+		if err != nil {
+			a.sendError(w, r, err)
+			return
+		}
+		if principal != nil {
+			r = r.WithContext(domain.ContextWithPrincipal(r.Context(), principal))
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// Authorize возвращает автора запроса, обладающего всеми правами, или nil
+// без ошибки, если проверка не нужна: аутентификация выключена или чтение
+// открыто. Переданные учетные данные проверяются и на открытом чтении, чтобы
+// работали фильтры вроде created_by=me. Управление ключами проверяется всегда
+func (a *Authenticator) Authorize(r *http.Request, permissions ...domain.Permission) (*domain.Principal, error) {
+	if a == nil {
+		return nil, nil
+	}
+	credential := credentials(r)
+	certificate := clientCertificate(r)
+	if !a.required(permissions) && ((credential == "" && certificate == nil) || !a.config.Enabled) {
+		return nil, nil
+	}
+
+	if a.limiter != nil {
+		if err := a.limiter.checkAuthentication(r); err != nil {
+			return nil, err
+		}
+	}
+
+	var principal *domain.Principal
+	var err error
+	if credential == "" && certificate != nil {
+		principal, err = a.authn.AuthenticateCertificate(certificate)
+	} else {
+		principal, err = a.authn.Authenticate(r.Context(), credential)
+	}
+	if err != nil {
+		if errors.Is(err, domain.ErrUnauthenticated) {
+			a.logger.DebugContext(r.Context(), "Authentication failed", "error", err, "path", r.URL.Path)
+			if a.limiter != nil {
+				a.limiter.authenticationFailed(r)
+			}
+		}
+		return nil, err
+	}
+	logger.SetField(r.Context(), "principal", principal.Method+":"+principal.Subject)
+
+	for _, permission := range permissions {
+		if !principal.Can(permission) {
+			a.logger.InfoContext(r.Context(), "Permission denied",
+				"subject", principal.Subject,
+				"permission", permission,
+				"path", r.URL.Path,
+			)
+			return nil, domain.ErrForbidden
+		}
+	}
+
+	return principal, nil
+}
+
+func (a *Authenticator) required(permissions []domain.Permission) bool {
+	for _, permission := range permissions {
+		switch {
+		case permission == domain.PermissionManageKeys:
+			return true
+		case !a.config.Enabled:
+		case permission != domain.PermissionRead || a.config.ProtectReads:
+			return true
+		}
+	}
+	return false
+}
+
+func (a *Authenticator) sendError(w http.ResponseWriter, r *http.Request, err error) {
+	var throttled *throttledError
+	switch {
+	case errors.As(err, &throttled):
+		w.Header().Set("Retry-After", ceilSeconds(throttled.retryAfter))
+		writeResponse(w, a.logger, http.StatusTooManyRequests, Response{Error: "Too many failed authentication attempts"})
+	case errors.Is(err, domain.ErrUnauthenticated):
+		w.Header().Set("WWW-Authenticate", `Bearer realm="quotes-service"`)
+		writeResponse(w, a.logger, http.StatusUnauthorized, Response{Error: "Missing or invalid credentials"})
+	case errors.Is(err, domain.ErrForbidden):
+		writeResponse(w, a.logger, http.StatusForbidden, Response{Error: "Insufficient permissions"})
+	default:
+		a.logger.ErrorContext(r.Context(), "Failed to authenticate request", "error", err)
+		writeResponse(w, a.logger, http.StatusInternalServerError, Response{Error: "Failed to authenticate request"})
+	}
+}
+
+func credentials(r *http.Request) string {
+	if key := r.Header.Get("X-API-Key"); key != "" {
+		return key
+	}
+	if header := r.Header.Get("Authorization"); len(header) > 7 && strings.EqualFold(header[:7], "Bearer ") {
+		return strings.TrimSpace(header[7:])
+	}
+	return ""
+}
+
+// clientCertificate возвращает сертификат клиента, только если TLS проверил
+// его цепочку по бандлу CA; непроверенные сертификаты не учитываются
+func clientCertificate(r *http.Request) *x509.Certificate {
+	if r.TLS == nil || len(r.TLS.VerifiedChains) == 0 || len(r.TLS.PeerCertificates) == 0 {
+		return nil
+	}
+	return r.TLS.PeerCertificates[0]
+}
+
+func methodPermission(method string) domain.Permission {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		return domain.PermissionRead
+	case http.MethodPost:
+		return domain.PermissionCreate
+	case http.MethodDelete:
+		return domain.PermissionDelete
+	default:
+		return domain.PermissionUpdate
+	}
+}
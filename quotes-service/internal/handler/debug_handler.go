@@ -0,0 +1,140 @@
+package handler
+
+import (
+	"database/sql"
+	"expvar"
+	"net/http"
+	"net/http/pprof"
+	"runtime"
+	"sync"
+	"time"
+
+	"quotes-service/internal/buildinfo"
+	"quotes-service/internal/infrastructure/logger"
+
+	"github.com/gorilla/mux"
+)
+
+// DebugHandler обслуживает отдельный отладочный листенер: pprof, runtime,
+// пулы БД, конфигурацию и сведения о сборке. Аутентификации здесь нет,
+// поэтому листенер по умолчанию слушает только localhost
+type DebugHandler struct {
+	pools  map[string]*sql.DB
+	config func() interface{}
+	logger *logger.Logger
+}
+
+type runtimeStats struct {
+	Uptime       string `json:"uptime"`
+	Goroutines   int    `json:"goroutines"`
+	GOMAXPROCS   int    `json:"gomaxprocs"`
+	NumCPU       int    `json:"num_cpu"`
+	HeapAlloc    uint64 `json:"heap_alloc_bytes"`
+	HeapInuse    uint64 `json:"heap_inuse_bytes"`
+	HeapObjects  uint64 `json:"heap_objects"`
+	Sys          uint64 `json:"sys_bytes"`
+	NumGC        uint32 `json:"num_gc"`
+	GCPauseTotal string `json:"gc_pause_total"`
+	LastGC       string `json:"last_gc,omitempty"`
+}
+
+type poolStats struct {
+	MaxOpenConnections int    `json:"max_open_connections"`
+	OpenConnections    int    `json:"open_connections"`
+	InUse              int    `json:"in_use"`
+	Idle               int    `json:"idle"`
+	WaitCount          int64  `json:"wait_count"`
+	WaitDuration       string `json:"wait_duration"`
+	MaxIdleClosed      int64  `json:"max_idle_closed"`
+	MaxIdleTimeClosed  int64  `json:"max_idle_time_closed"`
+	MaxLifetimeClosed  int64  `json:"max_lifetime_closed"`
+}
+
+var (
+	processStart = time.Now()
+	publishVars  sync.Once
+)
+
+// NewDebugHandler: config вызывается на каждый запрос и отдается как есть,
+// секреты из него нужно убрать заранее
+func NewDebugHandler(pools map[string]*sql.DB, config func() interface{}, logger *logger.Logger) *DebugHandler {
+	// expvar - глобальный реестр, повторная публикация имени паникует
+	publishVars.Do(func() {
+		expvar.Publish("runtime", expvar.Func(func() interface{} { return readRuntimeStats() }))
+		expvar.Publish("build", expvar.Func(func() interface{} { return buildinfo.Get() }))
+	})
+
+	return &DebugHandler{
+		pools:  pools,
+		config: config,
+		logger: logger,
+	}
+}
+
+func (h *DebugHandler) RegisterRoutes(router *mux.Router) {
+	router.HandleFunc("/debug/pprof/cmdline", pprof.Cmdline)
+	router.HandleFunc("/debug/pprof/profile", pprof.Profile)
+	router.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
+	router.HandleFunc("/debug/pprof/trace", pprof.Trace)
+	// heap, goroutine, block и остальные профили отдает pprof.Index по имени
+	router.PathPrefix("/debug/pprof/").HandlerFunc(pprof.Index)
+
+	router.Handle("/debug/vars", expvar.Handler()).Methods("GET")
+	router.HandleFunc("/debug/runtime", h.Runtime).Methods("GET")
+	router.HandleFunc("/debug/db", h.DBStats).Methods("GET")
+	router.HandleFunc("/debug/config", h.Config).Methods("GET")
+	router.HandleFunc("/debug/build", h.Build).Methods("GET")
+}
+
+func (h *DebugHandler) Runtime(w http.ResponseWriter, r *http.Request) {
+	writeResponse(w, h.logger, http.StatusOK, Response{Data: readRuntimeStats()})
+}
+
+func (h *DebugHandler) DBStats(w http.ResponseWriter, r *http.Request) {
+	stats := make(map[string]poolStats, len(h.pools))
+	for name, db := range h.pools {
+		s := db.Stats()
+		stats[name] = poolStats{
+			MaxOpenConnections: s.MaxOpenConnections,
+			OpenConnections:    s.OpenConnections,
+			InUse:              s.InUse,
+			Idle:               s.Idle,
+			WaitCount:          s.WaitCount,
+			WaitDuration:       s.WaitDuration.String(),
+			MaxIdleClosed:      s.MaxIdleClosed,
+			MaxIdleTimeClosed:  s.MaxIdleTimeClosed,
+			MaxLifetimeClosed:  s.MaxLifetimeClosed,
+		}
+	}
+	writeResponse(w, h.logger, http.StatusOK, Response{Data: stats})
+}
+
+func (h *DebugHandler) Config(w http.ResponseWriter, r *http.Request) {
+	writeResponse(w, h.logger, http.StatusOK, Response{Data: h.config()})
+}
+
+func (h *DebugHandler) Build(w http.ResponseWriter, r *http.Request) {
+	writeResponse(w, h.logger, http.StatusOK, Response{Data: buildinfo.Get()})
+}
+
+func readRuntimeStats() runtimeStats {
+	var mem runtime.MemStats
+	runtime.ReadMemStats(&mem)
+
+	stats := runtimeStats{
+		Uptime:       time.Since(processStart).Round(time.Second).String(),
+		Goroutines:   runtime.NumGoroutine(),
+		GOMAXPROCS:   runtime.GOMAXPROCS(0),
+		NumCPU:       runtime.NumCPU(),
+		HeapAlloc:    mem.HeapAlloc,
+		HeapInuse:    mem.HeapInuse,
+		HeapObjects:  mem.HeapObjects,
+		Sys:          mem.Sys,
+		NumGC:        mem.NumGC,
+		GCPauseTotal: time.Duration(mem.PauseTotalNs).String(),
+	}
+	if mem.LastGC > 0 {
+		stats.LastGC = time.Unix(0, int64(mem.LastGC)).UTC().Format(time.RFC3339)
+	}
+	return stats
+}
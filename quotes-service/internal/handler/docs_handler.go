@@ -0,0 +1,54 @@
+package handler
+
+import (
+	"net/http"
+
+	"quotes-service/api/openapi"
+	"quotes-service/internal/infrastructure/logger"
+
+	"github.com/gorilla/mux"
+)
+
+// Redoc подключается с CDN, чтобы не тащить статику в бинарник
+const redocPage = `<!DOCTYPE html>
+<html>
+<head>
+  <title>Quotes Service API</title>
+  <meta charset="utf-8"/>
+  <meta name="viewport" content="width=device-width, initial-scale=1">
+</head>
+<body>
+  <redoc spec-url="/openapi.json"></redoc>
+  <script src="https://cdn.redoc.ly/redoc/latest/bundles/redoc.standalone.js"></script>
+</body>
+</html>
+`
+
+// DocsHandler отдает OpenAPI спецификацию и страницу документации
+type DocsHandler struct {
+	logger *logger.Logger
+}
+
+func NewDocsHandler(logger *logger.Logger) *DocsHandler {
+	return &DocsHandler{logger: logger}
+}
+
+func (h *DocsHandler) RegisterRoutes(router *mux.Router) {
+	router.HandleFunc("/openapi.json", h.Spec).Methods("GET")
+	router.HandleFunc("/docs", h.Docs).Methods("GET")
+}
+
+func (h *DocsHandler) Spec(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+	if _, err := w.Write(openapi.Spec); err != nil {
+		h.logger.ErrorContext(r.Context(), "Failed to write OpenAPI spec", "error", err)
+	}
+}
+
+func (h *DocsHandler) Docs(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	if _, err := w.Write([]byte(redocPage)); err != nil {
+		h.logger.ErrorContext(r.Context(), "Failed to write docs page", "error", err)
+	}
+}
@@ -0,0 +1,183 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+
+	"quotes-service/internal/domain"
+	"quotes-service/internal/graph"
+	"quotes-service/internal/infrastructure/logger"
+
+	"github.com/gorilla/mux"
+)
+
+const maxGraphQLBody = 1 << 20
+
+// GraphQLHandler обслуживает /graphql: POST с JSON-телом или GET с
+// параметрами query, operationName и variables (только запросы).
+// Мутации требуют тех же прав, что и соответствующие REST-запросы
+type GraphQLHandler struct {
+	executor *graph.Executor
+	auth     *Authenticator
+	limiter  *RateLimiter
+	logger   *logger.Logger
+}
+
+// mutationRoutes - REST-маршруты, чьи лимиты расходуют поля мутаций
+var mutationRoutes = map[string]string{
+	"createQuote": "POST /quotes",
+	"deleteQuote": "DELETE /quotes/{id}",
+}
+
+type graphQLRequest struct {
+	Query         string                 `json:"query"`
+	OperationName string                 `json:"operationName"`
+	Variables     map[string]interface{} `json:"variables"`
+}
+
+type graphQLError struct {
+	Message    string                 `json:"message"`
+	Extensions map[string]interface{} `json:"extensions,omitempty"`
+}
+
+func NewGraphQLHandler(executor *graph.Executor, auth *Authenticator, limiter *RateLimiter, logger *logger.Logger) *GraphQLHandler {
+	return &GraphQLHandler{
+		executor: executor,
+		auth:     auth,
+		limiter:  limiter,
+		logger:   logger,
+	}
+}
+
+func (h *GraphQLHandler) RegisterRoutes(router *mux.Router) {
+	router.HandleFunc("/graphql", h.Serve).Methods("GET", "POST")
+}
+
+func (h *GraphQLHandler) Serve(w http.ResponseWriter, r *http.Request) {
+	var req graphQLRequest
+
+	if r.Method == http.MethodGet {
+		req.Query = r.URL.Query().Get("query")
+		req.OperationName = r.URL.Query().Get("operationName")
+		if vars := r.URL.Query().Get("variables"); vars != "" {
+			if err := json.Unmarshal([]byte(vars), &req.Variables); err != nil {
+				h.sendErrors(w, http.StatusBadRequest, "Invalid variables", "BAD_REQUEST")
+				return
+			}
+		}
+	} else {
+		if err := json.NewDecoder(io.LimitReader(r.Body, maxGraphQLBody)).Decode(&req); err != nil {
+			h.sendErrors(w, http.StatusBadRequest, "Invalid JSON format", "BAD_REQUEST")
+			return
+		}
+	}
+
+	if req.Query == "" {
+		h.sendErrors(w, http.StatusBadRequest, "Query is required", "BAD_REQUEST")
+		return
+	}
+
+	permissions := []domain.Permission{domain.PermissionRead}
+	analysis := graph.Analyze(req.Query, req.OperationName, req.Variables)
+	if analysis.Mutation {
+		// Мутации через GET не выполняются, чтобы их нельзя было вызвать ссылкой
+		if r.Method == http.MethodGet {
+			h.sendErrors(w, http.StatusMethodNotAllowed, "Mutations require POST", "METHOD_NOT_ALLOWED")
+			return
+		}
+		permissions = mutationPermissions(analysis.Fields)
+	}
+
+	ctx := r.Context()
+	principal, err := h.auth.Authorize(r, permissions...)
+	var throttled *throttledError
+	switch {
+	case errors.As(err, &throttled):
+		w.Header().Set("Retry-After", ceilSeconds(throttled.retryAfter))
+		h.sendErrors(w, http.StatusTooManyRequests, "Too many failed authentication attempts", "RATE_LIMITED")
+		return
+	case errors.Is(err, domain.ErrUnauthenticated):
+		w.Header().Set("WWW-Authenticate", `Bearer realm="quotes-service"`)
+		h.sendErrors(w, http.StatusUnauthorized, "Missing or invalid credentials", "UNAUTHENTICATED")
+		return
+	case errors.Is(err, domain.ErrForbidden):
+		h.sendErrors(w, http.StatusForbidden, "Insufficient permissions", "FORBIDDEN")
+		return
+	case err != nil:
+		h.logger.ErrorContext(r.Context(), "Failed to authenticate request", "error", err)
+		h.sendErrors(w, http.StatusInternalServerError, "Failed to authenticate request", "INTERNAL")
+		return
+	case principal != nil:
+		ctx = domain.ContextWithPrincipal(ctx, principal)
+	}
+
+	if analysis.Mutation {
+		for _, field := range analysis.Fields {
+			route, ok := mutationRoutes[field]
+			if ok && !h.limiter.allowRoute(w, r.WithContext(ctx), route) {
+				h.sendErrors(w, http.StatusTooManyRequests, "Rate limit exceeded", "RATE_LIMITED")
+				return
+			}
+		}
+	}
+
+	stats, resp, err := h.executor.Exec(ctx, req.Query, req.OperationName, req.Variables)
+	if err != nil {
+		code := "QUERY_TOO_COMPLEX"
+		if errors.Is(err, graph.ErrQueryTooDeep) {
+			code = "QUERY_TOO_DEEP"
+		}
+		h.logger.WarnContext(r.Context(), "GraphQL query rejected", "error", err, "depth", stats.Depth, "complexity", stats.Complexity)
+		h.sendErrors(w, http.StatusBadRequest, err.Error(), code)
+		return
+	}
+	// 504 только для запросов, чьи резолверы упали по дедлайну. Мутация
+	// могла зафиксировать часть полей, поэтому ее ответ отдается целиком
+	// с ошибками TIMEOUT у не успевших полей
+	if !analysis.Mutation && graph.TimedOut(resp) {
+		h.sendErrors(w, http.StatusGatewayTimeout, "Request timed out", "TIMEOUT")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		h.logger.Error("Failed to encode response", "error", err)
+	}
+}
+
+// mutationPermissions - права, нужные для корневых полей мутации; неизвестное
+// поле требует самого сильного права на данные
+func mutationPermissions(fields []string) []domain.Permission {
+	var permissions []domain.Permission
+	for _, field := range fields {
+		switch field {
+		case "__typename":
+		case "createQuote":
+			permissions = append(permissions, domain.PermissionCreate)
+		case "updateQuote":
+			permissions = append(permissions, domain.PermissionUpdate)
+		default:
+			permissions = append(permissions, domain.PermissionDelete)
+		}
+	}
+	return permissions
+}
+
+func (h *GraphQLHandler) sendErrors(w http.ResponseWriter, status int, message, code string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+
+	extensions := map[string]interface{}{"code": code}
+	if id := w.Header().Get(RequestIDHeader); id != "" {
+		extensions["request_id"] = id
+	}
+	body := map[string][]graphQLError{
+		"errors": {{Message: message, Extensions: extensions}},
+	}
+	if err := json.NewEncoder(w).Encode(body); err != nil {
+		h.logger.Error("Failed to encode response", "error", err)
+	}
+}
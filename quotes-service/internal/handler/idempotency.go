@@ -0,0 +1,149 @@
+package handler
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net/http"
+
+	"quotes-service/internal/domain"
+	"quotes-service/internal/infrastructure/logger"
+	"quotes-service/internal/service"
+)
+
+// Тело запроса читается целиком ради отпечатка, поэтому его размер ограничен
+const maxIdempotentBody = 1 << 20
+
+// Idempotency повторяет сохраненный ответ на POST с тем же заголовком
+// Idempotency-Key (draft-ietf-httpapi-idempotency-key-header). Ключ действует
+// в пределах автора запроса, поэтому middleware подключается после аутентификации.
+// Анонимные клиенты различаются по IP так же, как в лимитах запросов
+type Idempotency struct {
+	service *service.IdempotencyService
+	clients *RateLimiter
+	logger  *logger.Logger
+}
+
+func NewIdempotency(service *service.IdempotencyService, clients *RateLimiter, logger *logger.Logger) *Idempotency {
+	return &Idempotency{
+		service: service,
+		clients: clients,
+		logger:  logger,
+	}
+}
+
+func (i *Idempotency) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		key := r.Header.Get("Idempotency-Key")
+		if r.Method != http.MethodPost || key == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		body, err := io.ReadAll(io.LimitReader(r.Body, maxIdempotentBody+1))
+		if err != nil {
+			writeResponse(w, i.logger, http.StatusBadRequest, Response{Error: "Failed to read request body"})
+			return
+		}
+		if len(body) > maxIdempotentBody {
+			writeResponse(w, i.logger, http.StatusRequestEntityTooLarge, Response{Error: "Request body too large"})
+			return
+		}
+		r.Body = io.NopCloser(bytes.NewReader(body))
+
+		client := i.clients.client(r)
+		fingerprint := requestFingerprint(r, body)
+		stored, err := i.service.Begin(r.Context(), client, key, fingerprint)
+		switch {
+		case errors.Is(err, domain.ErrInvalidIdempotencyKey):
+			writeResponse(w, i.logger, http.StatusBadRequest, Response{Error: err.Error()})
+			return
+		case errors.Is(err, domain.ErrIdempotencyKeyReused):
+			writeResponse(w, i.logger, http.StatusUnprocessableEntity, Response{Error: "Idempotency-Key was already used with a different request"})
+			return
+		case errors.Is(err, domain.ErrIdempotencyInProgress):
+			w.Header().Set("Retry-After", "1")
+			writeResponse(w, i.logger, http.StatusConflict, Response{Error: "A request with this Idempotency-Key is still in progress"})
+			return
+		case err != nil:
+			i.logger.ErrorContext(r.Context(), "Failed to check idempotency key", "error", err)
+			writeResponse(w, i.logger, http.StatusInternalServerError, Response{Error: "Failed to check idempotency key"})
+			return
+		case stored != nil:
+			if stored.ContentType != "" {
+				w.Header().Set("Content-Type", stored.ContentType)
+			}
+			w.Header().Set("Idempotent-Replayed", "true")
+			w.WriteHeader(stored.StatusCode)
+			w.Write(stored.Body)
+			return
+		}
+
+		// Ответ сохраняется и после отключения клиента: именно он придет с повтором
+		ctx := context.WithoutCancel(r.Context())
+		recorder := &recordingWriter{ResponseWriter: w, statusCode: http.StatusOK}
+		hold := i.service.Hold(ctx, client, key, fingerprint)
+		saved := false
+		defer func() {
+			hold()
+			if saved {
+				return
+			}
+			if err := i.service.Release(ctx, client, key); err != nil {
+				i.logger.ErrorContext(r.Context(), "Failed to release idempotency key", "error", err)
+			}
+		}()
+
+		next.ServeHTTP(recorder, r)
+
+		// Ошибки сервера не запоминаются, чтобы повтор мог выполниться заново
+		if recorder.statusCode >= http.StatusInternalServerError {
+			return
+		}
+		err = i.service.Complete(ctx, &domain.IdempotencyRecord{
+			Client:      client,
+			Key:         key,
+			Fingerprint: fingerprint,
+			StatusCode:  recorder.statusCode,
+			ContentType: recorder.Header().Get("Content-Type"),
+			Body:        recorder.body.Bytes(),
+		})
+		saved = err == nil
+	})
+}
+
+func requestFingerprint(r *http.Request, body []byte) string {
+	hash := sha256.New()
+	io.WriteString(hash, r.Method+" "+r.URL.RequestURI()+"\n")
+	hash.Write(body)
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// recordingWriter копирует ответ, чтобы сохранить его для повторов
+type recordingWriter struct {
+	http.ResponseWriter
+	statusCode  int
+	wroteHeader bool
+	body        bytes.Buffer
+}
+
+func (rw *recordingWriter) WriteHeader(code int) {
+	if !rw.wroteHeader {
+		rw.statusCode = code
+		rw.wroteHeader = true
+	}
+	rw.ResponseWriter.WriteHeader(code)
+}
+
+func (rw *recordingWriter) Write(p []byte) (int, error) {
+	rw.wroteHeader = true
+	rw.body.Write(p)
+	return rw.ResponseWriter.Write(p)
+}
+
+func (rw *recordingWriter) Unwrap() http.ResponseWriter {
+	return rw.ResponseWriter
+}
@@ -0,0 +1,54 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"quotes-service/internal/domain"
+	"quotes-service/internal/infrastructure/logger"
+
+	"github.com/gorilla/mux"
+)
+
+// LogLevelHandler - админский эндпоинт смены уровня логирования без перезапуска
+type LogLevelHandler struct {
+	auth   *Authenticator
+	logger *logger.Logger
+}
+
+type logLevelRequest struct {
+	Level string `json:"level"`
+}
+
+func NewLogLevelHandler(auth *Authenticator, logger *logger.Logger) *LogLevelHandler {
+	return &LogLevelHandler{
+		auth:   auth,
+		logger: logger,
+	}
+}
+
+func (h *LogLevelHandler) RegisterRoutes(router *mux.Router) {
+	router.Handle("/admin/log-level", h.auth.Require(domain.PermissionManageService, http.HandlerFunc(h.GetLevel))).Methods("GET")
+	router.Handle("/admin/log-level", h.auth.Require(domain.PermissionManageService, http.HandlerFunc(h.SetLevel))).Methods("PUT")
+}
+
+func (h *LogLevelHandler) GetLevel(w http.ResponseWriter, r *http.Request) {
+	writeResponse(w, h.logger, http.StatusOK, Response{Data: logLevelRequest{Level: h.logger.Level()}})
+}
+
+func (h *LogLevelHandler) SetLevel(w http.ResponseWriter, r *http.Request) {
+	var req logLevelRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeResponse(w, h.logger, http.StatusBadRequest, Response{Error: "Invalid JSON format"})
+		return
+	}
+
+	previous := h.logger.Level()
+	if err := h.logger.SetLevel(req.Level); err != nil {
+		writeResponse(w, h.logger, http.StatusBadRequest, Response{Error: "Level must be one of debug, info, warn, error"})
+		return
+	}
+	h.logger.WarnContext(r.Context(), "Log level changed", "from", previous, "to", h.logger.Level())
+
+	writeResponse(w, h.logger, http.StatusOK, Response{Data: logLevelRequest{Level: h.logger.Level()}})
+}
@@ -0,0 +1,63 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"regexp"
+	"time"
+
+	"quotes-service/internal/metrics"
+
+	"github.com/gorilla/mux"
+)
+
+// unmatchedRoute - метка запросов, для которых не нашлось маршрута (404/405),
+// чтобы сканирование произвольных путей не раздувало число серий
+const unmatchedRoute = "unmatched"
+
+// routePattern убирает регулярные выражения из переменных шаблона:
+// /quotes/{id:[0-9]+} -> /quotes/{id}
+var routePattern = regexp.MustCompile(`\{(\w+):[^}]+\}`)
+
+type routeLabelKey struct{}
+
+// InstrumentRouter считает запросы и их длительность по шаблону маршрута и
+// статусу. Обертка снаружи маршрутизатора видит и 404/405, а шаблон
+// совпавшего маршрута сообщает middleware внутри него
+func InstrumentRouter(router *mux.Router, registry *metrics.Registry) http.Handler {
+	router.Use(routeLabelMiddleware)
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		route := unmatchedRoute
+		ww := &responseWriter{ResponseWriter: w, statusCode: http.StatusOK}
+
+		router.ServeHTTP(ww, r.WithContext(context.WithValue(r.Context(), routeLabelKey{}, &route)))
+
+		registry.ObserveRequest(r.Method, route, ww.statusCode, time.Since(start))
+	})
+}
+
+func routeLabelMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if label, ok := r.Context().Value(routeLabelKey{}).(*string); ok {
+			if route, ok := routeTemplate(r); ok {
+				*label = route
+			}
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// routeTemplate возвращает шаблон совпавшего маршрута без регулярных выражений
+func routeTemplate(r *http.Request) (string, bool) {
+	route := mux.CurrentRoute(r)
+	if route == nil {
+		return "", false
+	}
+	template, err := route.GetPathTemplate()
+	if err != nil {
+		return "", false
+	}
+	return routePattern.ReplaceAllString(template, "{$1}"), true
+}
@@ -0,0 +1,59 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"quotes-service/internal/health"
+	"quotes-service/internal/infrastructure/logger"
+
+	"github.com/gorilla/mux"
+)
+
+// ProbeHandler отдает /livez, /readyz и /startupz для оркестратора. Без
+// параметра verbose ответ содержит только статус, с verbose=true - результаты,
+// задержку и последнюю ошибку каждой проверки
+type ProbeHandler struct {
+	registry *health.Registry
+	logger   *logger.Logger
+}
+
+func NewProbeHandler(registry *health.Registry, logger *logger.Logger) *ProbeHandler {
+	return &ProbeHandler{
+		registry: registry,
+		logger:   logger,
+	}
+}
+
+func (h *ProbeHandler) RegisterRoutes(router *mux.Router) {
+	for _, probe := range []health.Probe{health.Liveness, health.Readiness, health.Startup} {
+		router.Handle("/"+string(probe), h.probe(probe)).Methods("GET")
+	}
+}
+
+func (h *ProbeHandler) probe(probe health.Probe) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		report := h.registry.Run(r.Context(), probe)
+
+		status := http.StatusOK
+		if report.Status != health.StatusOK {
+			status = http.StatusServiceUnavailable
+			h.logger.WarnContext(r.Context(), "Probe failed", "probe", probe, "reason", report.Reason, "checks", failedChecks(report))
+		}
+
+		if verbose, _ := strconv.ParseBool(r.URL.Query().Get("verbose")); !verbose {
+			report.Checks = nil
+		}
+		writeResponse(w, h.logger, status, Response{Data: report})
+	})
+}
+
+func failedChecks(report health.Report) []string {
+	var failed []string
+	for _, check := range report.Checks {
+		if check.Status == health.StatusFail {
+			failed = append(failed, check.Name)
+		}
+	}
+	return failed
+}
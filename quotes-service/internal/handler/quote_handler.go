@@ -1,14 +1,16 @@
 package handler
 
 import (
-	"context"
+	"bufio"
 	"encoding/json"
 	"errors"
+	"net"
 	"net/http"
 	"strconv"
 	"time"
 
 	"quotes-service/internal/domain"
+	"quotes-service/internal/infrastructure/database"
 	"quotes-service/internal/infrastructure/logger"
 	"quotes-service/internal/service"
 
@@ -21,16 +23,18 @@ type QuoteHandler struct {
 }
 
 type Response struct {
-	Data    interface{} `json:"data,omitempty"`
-	Error   string      `json:"error,omitempty"`
-	Message string      `json:"message,omitempty"`
+	Data      interface{} `json:"data,omitempty"`
+	Error     string      `json:"error,omitempty"`
+	Message   string      `json:"message,omitempty"`
+	RequestID string      `json:"request_id,omitempty"`
 }
 
 type HealthResponse struct {
-	Status    string    `json:"status"`
-	Timestamp time.Time `json:"timestamp"`
-	Database  string    `json:"database"`
-	Uptime    string    `json:"uptime"`
+	Status    string             `json:"status"`
+	Timestamp time.Time          `json:"timestamp"`
+	Database  string             `json:"database"`
+	Uptime    string             `json:"uptime"`
+	Cache     *domain.CacheStats `json:"cache,omitempty"`
 }
 
 var startTime = time.Now()
@@ -42,28 +46,34 @@ func NewQuoteHandler(service *service.QuoteService, logger *logger.Logger) *Quot
 	}
 }
 
+// RegisterRoutes регистрирует маршруты API v1; префикс версии задает MountVersions
 func (h *QuoteHandler) RegisterRoutes(router *mux.Router) {
-	// API routes
 	router.HandleFunc("/quotes", h.CreateQuote).Methods("POST")
 	router.HandleFunc("/quotes", h.GetQuotes).Methods("GET")
 	router.HandleFunc("/quotes/random", h.GetRandomQuote).Methods("GET")
+	router.HandleFunc("/quotes/{id:[0-9]+}", h.UpdateQuote).Methods("PUT")
 	router.HandleFunc("/quotes/{id:[0-9]+}", h.DeleteQuote).Methods("DELETE")
+}
 
-	// Health check
+// RegisterHealthRoutes регистрирует health check вне версий API
+func (h *QuoteHandler) RegisterHealthRoutes(router *mux.Router) {
 	router.HandleFunc("/health", h.HealthCheck).Methods("GET")
+}
 
-	// Add middleware
+// RegisterMiddleware подключает общие middleware к корневому маршрутизатору,
+// чтобы они срабатывали один раз для всех версий API
+func (h *QuoteHandler) RegisterMiddleware(router *mux.Router) {
 	router.Use(h.loggingMiddleware)
 	router.Use(h.recoveryMiddleware)
+	router.Use(h.consistencyMiddleware)
 }
 
 func (h *QuoteHandler) CreateQuote(w http.ResponseWriter, r *http.Request) {
-	ctx, cancel := context.WithTimeout(r.Context(), 10*time.Second)
-	defer cancel()
+	ctx := r.Context()
 
 	var req domain.CreateQuoteRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		h.logger.Debug("Invalid JSON in request", "error", err)
+		h.logger.DebugContext(r.Context(), "Invalid JSON in request", "error", err)
 		h.sendError(w, http.StatusBadRequest, "Invalid JSON format")
 		return
 	}
@@ -74,8 +84,8 @@ func (h *QuoteHandler) CreateQuote(w http.ResponseWriter, r *http.Request) {
 			h.sendError(w, http.StatusBadRequest, err.Error())
 			return
 		}
-		h.logger.Error("Failed to create quote", "error", err)
-		h.sendError(w, http.StatusInternalServerError, "Failed to create quote")
+		h.logger.ErrorContext(r.Context(), "Failed to create quote", "error", err)
+		writeServerError(w, r, h.logger, err, "Failed to create quote")
 		return
 	}
 
@@ -83,11 +93,12 @@ func (h *QuoteHandler) CreateQuote(w http.ResponseWriter, r *http.Request) {
 }
 
 func (h *QuoteHandler) GetQuotes(w http.ResponseWriter, r *http.Request) {
-	ctx, cancel := context.WithTimeout(r.Context(), 15*time.Second)
-	defer cancel()
+	ctx := r.Context()
 
 	filter := domain.QuoteFilter{
-		Author: r.URL.Query().Get("author"),
+		Author:    r.URL.Query().Get("author"),
+		Tag:       r.URL.Query().Get("tag"),
+		CreatedBy: r.URL.Query().Get("created_by"),
 	}
 
 	// Парсинг параметра "limit"
@@ -106,17 +117,25 @@ func (h *QuoteHandler) GetQuotes(w http.ResponseWriter, r *http.Request) {
 
 	quotes, err := h.service.GetAllQuotes(ctx, filter)
 	if err != nil {
-		h.logger.Error("Failed to get quotes", "error", err, "filter", filter)
-		h.sendError(w, http.StatusInternalServerError, "Failed to get quotes")
+		if errors.Is(err, domain.ErrUnauthenticated) {
+			h.sendError(w, http.StatusUnauthorized, "Authentication required for created_by=me")
+			return
+		}
+		h.logger.ErrorContext(r.Context(), "Failed to get quotes", "error", err, "filter", filter)
+		writeServerError(w, r, h.logger, err, "Failed to get quotes")
 		return
 	}
 
+	// Пустой список отдается как [], а не null
+	if quotes == nil {
+		quotes = []*domain.Quote{}
+	}
+
 	h.sendSuccess(w, http.StatusOK, quotes)
 }
 
 func (h *QuoteHandler) GetRandomQuote(w http.ResponseWriter, r *http.Request) {
-	ctx, cancel := context.WithTimeout(r.Context(), 10*time.Second)
-	defer cancel()
+	ctx := r.Context()
 
 	quote, err := h.service.GetRandomQuote(ctx)
 	if err != nil {
@@ -124,8 +143,47 @@ func (h *QuoteHandler) GetRandomQuote(w http.ResponseWriter, r *http.Request) {
 			h.sendError(w, http.StatusNotFound, "No quotes found")
 			return
 		}
-		h.logger.Error("Failed to get random quote", "error", err)
-		h.sendError(w, http.StatusInternalServerError, "Failed to get random quote")
+		h.logger.ErrorContext(r.Context(), "Failed to get random quote", "error", err)
+		writeServerError(w, r, h.logger, err, "Failed to get random quote")
+		return
+	}
+
+	h.sendSuccess(w, http.StatusOK, quote)
+}
+
+func (h *QuoteHandler) UpdateQuote(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	vars := mux.Vars(r)
+	id, err := strconv.Atoi(vars["id"])
+	if err != nil {
+		h.sendError(w, http.StatusBadRequest, "Invalid quote ID")
+		return
+	}
+
+	var req domain.UpdateQuoteRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.logger.DebugContext(r.Context(), "Invalid JSON in request", "error", err)
+		h.sendError(w, http.StatusBadRequest, "Invalid JSON format")
+		return
+	}
+
+	quote, err := h.service.UpdateQuote(ctx, id, req)
+	if err != nil {
+		if errors.Is(err, domain.ErrQuoteNotFound) {
+			h.sendError(w, http.StatusNotFound, "Quote not found")
+			return
+		}
+		if errors.Is(err, domain.ErrQuoteForbidden) {
+			h.sendError(w, http.StatusForbidden, "Only the owner or an admin can modify this quote")
+			return
+		}
+		if errors.Is(err, domain.ErrInvalidQuote) {
+			h.sendError(w, http.StatusBadRequest, err.Error())
+			return
+		}
+		h.logger.ErrorContext(r.Context(), "Failed to update quote", "id", id, "error", err)
+		writeServerError(w, r, h.logger, err, "Failed to update quote")
 		return
 	}
 
@@ -133,8 +191,7 @@ func (h *QuoteHandler) GetRandomQuote(w http.ResponseWriter, r *http.Request) {
 }
 
 func (h *QuoteHandler) DeleteQuote(w http.ResponseWriter, r *http.Request) {
-	ctx, cancel := context.WithTimeout(r.Context(), 10*time.Second)
-	defer cancel()
+	ctx := r.Context()
 
 	vars := mux.Vars(r)
 	id, err := strconv.Atoi(vars["id"])
@@ -149,12 +206,16 @@ func (h *QuoteHandler) DeleteQuote(w http.ResponseWriter, r *http.Request) {
 			h.sendError(w, http.StatusNotFound, "Quote not found")
 			return
 		}
+		if errors.Is(err, domain.ErrQuoteForbidden) {
+			h.sendError(w, http.StatusForbidden, "Only the owner or an admin can modify this quote")
+			return
+		}
 		if errors.Is(err, domain.ErrInvalidQuote) {
 			h.sendError(w, http.StatusBadRequest, err.Error())
 			return
 		}
-		h.logger.Error("Failed to delete quote", "id", id, "error", err)
-		h.sendError(w, http.StatusInternalServerError, "Failed to delete quote")
+		h.logger.ErrorContext(r.Context(), "Failed to delete quote", "id", id, "error", err)
+		writeServerError(w, r, h.logger, err, "Failed to delete quote")
 		return
 	}
 
@@ -164,12 +225,11 @@ func (h *QuoteHandler) DeleteQuote(w http.ResponseWriter, r *http.Request) {
 }
 
 func (h *QuoteHandler) HealthCheck(w http.ResponseWriter, r *http.Request) {
-	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
-	defer cancel()
+	ctx := r.Context()
 
 	dbStatus := "connected"
 	if err := h.service.HealthCheck(ctx); err != nil {
-		h.logger.Error("Database health check failed", "error", err)
+		h.logger.ErrorContext(r.Context(), "Database health check failed", "error", err)
 		dbStatus = "disconnected"
 	}
 
@@ -182,6 +242,10 @@ func (h *QuoteHandler) HealthCheck(w http.ResponseWriter, r *http.Request) {
 		Uptime:    uptime,
 	}
 
+	if stats, ok := h.service.CacheStats(); ok {
+		response.Cache = &stats
+	}
+
 	// Если БД недоступна, возвращаем 503
 	if dbStatus == "disconnected" {
 		response.Status = "unhealthy"
@@ -201,11 +265,19 @@ func (h *QuoteHandler) sendError(w http.ResponseWriter, statusCode int, message
 }
 
 func (h *QuoteHandler) sendResponse(w http.ResponseWriter, statusCode int, response Response) {
+	writeResponse(w, h.logger, statusCode, response)
+}
+
+func writeResponse(w http.ResponseWriter, logger *logger.Logger, statusCode int, response Response) {
+	// Идентификатор запроса в теле ошибки, чтобы его было проще передать в поддержку
+	if response.Error != "" {
+		response.RequestID = w.Header().Get(RequestIDHeader)
+	}
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(statusCode)
 
 	if err := json.NewEncoder(w).Encode(response); err != nil {
-		h.logger.Error("Failed to encode response", "error", err)
+		logger.Error("Failed to encode response", "error", err)
 	}
 }
 
@@ -213,6 +285,9 @@ func (h *QuoteHandler) sendResponse(w http.ResponseWriter, statusCode int, respo
 func (h *QuoteHandler) loggingMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		start := time.Now()
+		if route, ok := routeTemplate(r); ok {
+			logger.SetField(r.Context(), "route", route)
+		}
 
 		// Обертка для записи статуса ответа
 		ww := &responseWriter{ResponseWriter: w, statusCode: http.StatusOK}
@@ -221,7 +296,7 @@ func (h *QuoteHandler) loggingMiddleware(next http.Handler) http.Handler {
 
 		duration := time.Since(start)
 
-		h.logger.Info("HTTP request",
+		h.logger.InfoContext(r.Context(), "HTTP request",
 			"method", r.Method,
 			"path", r.URL.Path,
 			"status", ww.statusCode,
@@ -237,7 +312,7 @@ func (h *QuoteHandler) recoveryMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		defer func() {
 			if err := recover(); err != nil {
-				h.logger.Error("Panic recovered",
+				h.logger.ErrorContext(r.Context(), "Panic recovered",
 					"error", err,
 					"path", r.URL.Path,
 					"method", r.Method,
@@ -251,6 +326,18 @@ func (h *QuoteHandler) recoveryMiddleware(next http.Handler) http.Handler {
 	})
 }
 
+// Middleware for read-your-writes: клиент, только что создавший цитату,
+// может потребовать чтение из primary заголовком X-Read-Your-Writes
+func (h *QuoteHandler) consistencyMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if force, err := strconv.ParseBool(r.Header.Get("X-Read-Your-Writes")); err == nil && force {
+			r = r.WithContext(database.WithPrimary(r.Context()))
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
 // кастомный ResponseWriter для записи статуса ответа
 type responseWriter struct {
 	http.ResponseWriter
@@ -261,3 +348,14 @@ func (rw *responseWriter) WriteHeader(code int) {
 	rw.statusCode = code
 	rw.ResponseWriter.WriteHeader(code)
 }
+
+// Unwrap дает http.ResponseController доступ к Flush и дедлайнам
+func (rw *responseWriter) Unwrap() http.ResponseWriter {
+	return rw.ResponseWriter
+}
+
+// Hijack нужен для upgrade до WebSocket
+func (rw *responseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	rw.statusCode = http.StatusSwitchingProtocols
+	return http.NewResponseController(rw.ResponseWriter).Hijack()
+}
@@ -0,0 +1,225 @@
+package handler
+
+import (
+	"math"
+	"net"
+	"net/http"
+	"net/netip"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"quotes-service/internal/domain"
+	"quotes-service/internal/infrastructure/logger"
+	"quotes-service/internal/ratelimit"
+)
+
+// versionPrefix срезается с шаблона маршрута, чтобы одно правило
+// действовало на /v1/quotes и на устаревший алиас /quotes
+var versionPrefix = regexp.MustCompile(`^/v[0-9]+/`)
+
+// RateLimitConfig: TrustedProxies - адреса балансировщиков, которым
+// доверяется X-Forwarded-For и X-Real-IP. AuthFailures - лимит неудачных
+// аутентификаций с одного IP (Method и Path не используются, Requests == 0 -
+// без лимита)
+type RateLimitConfig struct {
+	Rules          []ratelimit.Rule
+	TrustedProxies []netip.Prefix
+	AuthFailures   ratelimit.Rule
+}
+
+// RateLimiter ограничивает запросы к маршрутам из правил. Клиент - автор
+// запроса (API-ключ или пользователь SSO), для анонимных - IP-адрес, поэтому
+// middleware подключается после аутентификации. Перебор учетных данных до
+// аутентификации ограничивает лимит неудачных попыток на IP (WithFailureLimit)
+type RateLimiter struct {
+	limiter *ratelimit.Limiter
+	state   atomic.Pointer[rateLimitState]
+	logger  *logger.Logger
+}
+
+type rateLimitState struct {
+	rules        map[string]ratelimit.Rule
+	trusted      []netip.Prefix
+	authFailures ratelimit.Rule
+}
+
+// throttledError: адрес исчерпал лимит неудачных аутентификаций
+type throttledError struct {
+	retryAfter time.Duration
+}
+
+func (e *throttledError) Error() string {
+	return "too many failed authentication attempts"
+}
+
+func NewRateLimiter(limiter *ratelimit.Limiter, config RateLimitConfig, logger *logger.Logger) *RateLimiter {
+	l := &RateLimiter{
+		limiter: limiter,
+		logger:  logger,
+	}
+	l.Update(config)
+	return l
+}
+
+// Update заменяет правила и доверенные прокси на лету. Корзины привязаны к
+// тексту правила, поэтому измененное правило начинается с полной корзины
+func (l *RateLimiter) Update(config RateLimitConfig) {
+	rules := make(map[string]ratelimit.Rule, len(config.Rules))
+	for _, rule := range config.Rules {
+		rules[rule.Method+" "+rule.Path] = rule
+	}
+	failures := config.AuthFailures
+	failures.Method, failures.Path = "AUTH", "failed"
+	l.state.Store(&rateLimitState{rules: rules, trusted: config.TrustedProxies, authFailures: failures})
+}
+
+func (l *RateLimiter) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		rule, ok := l.rule(r)
+		if !ok {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		if !l.limit(w, r, rule) {
+			writeResponse(w, l.logger, http.StatusTooManyRequests, Response{Error: "Rate limit exceeded"})
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// allowRoute списывает запрос по правилу REST-маршрута route ("POST /quotes"),
+// чтобы GraphQL-мутации расходовали ту же квоту. Без правила запрос разрешен
+func (l *RateLimiter) allowRoute(w http.ResponseWriter, r *http.Request, route string) bool {
+	if l == nil {
+		return true
+	}
+	rule, ok := l.state.Load().rules[route]
+	if !ok {
+		return true
+	}
+	return l.limit(w, r, rule)
+}
+
+// limit списывает запрос клиента по правилу и выставляет заголовки по
+// draft-ietf-httpapi-ratelimit-headers; ответ 429 пишет вызывающий
+func (l *RateLimiter) limit(w http.ResponseWriter, r *http.Request, rule ratelimit.Rule) bool {
+	client := l.client(r)
+	decision := l.limiter.Allow(client, rule)
+
+	w.Header().Set("RateLimit-Limit", strconv.Itoa(decision.Limit))
+	w.Header().Set("RateLimit-Remaining", strconv.Itoa(decision.Remaining))
+	w.Header().Set("RateLimit-Reset", ceilSeconds(decision.Reset))
+	w.Header().Set("RateLimit-Policy", strconv.Itoa(rule.Requests)+";w="+ceilSeconds(rule.Period))
+
+	if !decision.Allowed {
+		l.logger.DebugContext(r.Context(), "Rate limit exceeded", "client", client, "rule", rule.String())
+		w.Header().Set("Retry-After", ceilSeconds(decision.RetryAfter))
+	}
+	return decision.Allowed
+}
+
+// checkAuthentication возвращает throttledError, если IP клиента исчерпал
+// лимит неудачных аутентификаций: учетные данные тогда не проверяются
+func (l *RateLimiter) checkAuthentication(r *http.Request) error {
+	rule := l.state.Load().authFailures
+	if rule.Requests == 0 {
+		return nil
+	}
+	client := "ip:" + l.clientIP(r)
+	if decision := l.limiter.Check(client, rule); !decision.Allowed {
+		l.logger.DebugContext(r.Context(), "Authentication throttled", "client", client)
+		return &throttledError{retryAfter: decision.RetryAfter}
+	}
+	return nil
+}
+
+// authenticationFailed списывает неудачную попытку с IP клиента
+func (l *RateLimiter) authenticationFailed(r *http.Request) {
+	if rule := l.state.Load().authFailures; rule.Requests > 0 {
+		l.limiter.Allow("ip:"+l.clientIP(r), rule)
+	}
+}
+
+func (l *RateLimiter) rule(r *http.Request) (ratelimit.Rule, bool) {
+	template, ok := versionlessRoute(r)
+	if !ok {
+		return ratelimit.Rule{}, false
+	}
+	rule, ok := l.state.Load().rules[r.Method+" "+template]
+	return rule, ok
+}
+
+// versionlessRoute возвращает шаблон маршрута без префикса версии и
+// регулярных выражений, как он записан в правилах: /v1/quotes/{id:[0-9]+} -> /quotes/{id}
+func versionlessRoute(r *http.Request) (string, bool) {
+	template, ok := routeTemplate(r)
+	if !ok {
+		return "", false
+	}
+	return versionPrefix.ReplaceAllString(template, "/"), true
+}
+
+func (l *RateLimiter) client(r *http.Request) string {
+	if principal, ok := domain.PrincipalFromContext(r.Context()); ok {
+		return principal.Method + ":" + principal.Subject
+	}
+	return "ip:" + l.clientIP(r)
+}
+
+// clientIP доверяет заголовкам прокси, только если запрос пришел от
+// доверенного адреса; в X-Forwarded-For берется ближайший справа
+// недоверенный адрес, так как левые значения клиент может подделать
+func (l *RateLimiter) clientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	remote, err := netip.ParseAddr(host)
+	if err != nil || !l.isTrusted(remote) {
+		return host
+	}
+
+	if forwarded := r.Header.Values("X-Forwarded-For"); len(forwarded) > 0 {
+		hops := strings.Split(strings.Join(forwarded, ","), ",")
+		client := remote
+		for i := len(hops) - 1; i >= 0; i-- {
+			addr, err := netip.ParseAddr(strings.TrimSpace(hops[i]))
+			if err != nil {
+				break
+			}
+			client = addr
+			if !l.isTrusted(addr) {
+				break
+			}
+		}
+		return client.Unmap().String()
+	}
+
+	if realIP, err := netip.ParseAddr(strings.TrimSpace(r.Header.Get("X-Real-IP"))); err == nil {
+		return realIP.Unmap().String()
+	}
+	return host
+}
+
+func (l *RateLimiter) isTrusted(addr netip.Addr) bool {
+	if l == nil {
+		return false
+	}
+	addr = addr.Unmap()
+	for _, prefix := range l.state.Load().trusted {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+func ceilSeconds(d time.Duration) string {
+	return strconv.Itoa(int(math.Ceil(d.Seconds())))
+}
@@ -0,0 +1,33 @@
+package handler
+
+import (
+	"net"
+	"net/http"
+	"strings"
+)
+
+// HTTPSRedirect отвечает 308 на адрес HTTPS-сервера с тем же путем и
+// запросом; 308 сохраняет метод и тело, в отличие от 301
+func HTTPSRedirect(httpsAddress string) http.Handler {
+	_, port, _ := net.SplitHostPort(httpsAddress)
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		host := strings.TrimSuffix(strings.TrimPrefix(r.Host, "["), "]")
+		if h, _, err := net.SplitHostPort(r.Host); err == nil {
+			host = h
+		}
+		if host == "" {
+			http.Error(w, "Host header is required", http.StatusBadRequest)
+			return
+		}
+		switch {
+		case port != "" && port != "443":
+			host = net.JoinHostPort(host, port)
+		case strings.Contains(host, ":"):
+			host = "[" + host + "]"
+		}
+
+		target := "https://" + host + r.URL.RequestURI()
+		http.Redirect(w, r, target, http.StatusPermanentRedirect)
+	})
+}
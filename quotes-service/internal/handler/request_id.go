@@ -0,0 +1,42 @@
+package handler
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"net/http"
+	"regexp"
+
+	"quotes-service/internal/infrastructure/logger"
+)
+
+const RequestIDHeader = "X-Request-ID"
+
+// Входящий идентификатор принимается, только если он безопасен для логов
+// и заголовков; иначе генерируется новый
+var validRequestID = regexp.MustCompile(`^[A-Za-z0-9._:-]{1,128}$`)
+
+// RequestID принимает X-Request-ID клиента или генерирует его, возвращает в
+// ответе и заводит поля запроса для логов. Оборачивает маршрутизатор целиком,
+// чтобы идентификатор был и у ответов 404/405
+func RequestID(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := r.Header.Get(RequestIDHeader)
+		if !validRequestID.MatchString(id) {
+			id = newRequestID()
+		}
+		w.Header().Set(RequestIDHeader, id)
+
+		ctx := logger.NewContext(r.Context())
+		logger.SetField(ctx, "request_id", id)
+
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+func newRequestID() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return ""
+	}
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,449 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"quotes-service/internal/domain"
+	"quotes-service/internal/infrastructure/logger"
+	"quotes-service/internal/service"
+	"quotes-service/internal/stream"
+
+	"github.com/gorilla/mux"
+	"github.com/gorilla/websocket"
+)
+
+type SocketConfig struct {
+	MaxConnections    int
+	SendBuffer        int
+	MaxMessageSize    int64
+	PingInterval      time.Duration
+	PongWait          time.Duration
+	WriteWait         time.Duration
+	MinRandomInterval time.Duration
+}
+
+// SocketHandler - WebSocket API: подписки на темы и случайные цитаты по таймеру.
+// Темы: "quotes" (все события), "author:<имя>", "tag:<тег>".
+type SocketHandler struct {
+	service  *service.QuoteService
+	broker   *stream.Broker
+	config   SocketConfig
+	logger   *logger.Logger
+	upgrader websocket.Upgrader
+	active   atomic.Int64
+
+	mu      sync.Mutex
+	clients map[*socketClient]struct{}
+}
+
+type socketRequest struct {
+	ID       string `json:"id,omitempty"`
+	Type     string `json:"type"`
+	Topic    string `json:"topic,omitempty"`
+	Interval string `json:"interval,omitempty"`
+}
+
+type socketMessage struct {
+	ID     string        `json:"id,omitempty"`
+	Type   string        `json:"type"`
+	Topic  string        `json:"topic,omitempty"`
+	Topics []string      `json:"topics,omitempty"`
+	Event  *domain.Event `json:"event,omitempty"`
+	Quote  *domain.Quote `json:"quote,omitempty"`
+	Error  string        `json:"error,omitempty"`
+}
+
+func NewSocketHandler(service *service.QuoteService, broker *stream.Broker, config SocketConfig, logger *logger.Logger) *SocketHandler {
+	if config.SendBuffer <= 0 {
+		config.SendBuffer = 64
+	}
+	if config.MaxMessageSize <= 0 {
+		config.MaxMessageSize = 4096
+	}
+	if config.PongWait <= 0 {
+		config.PongWait = 60 * time.Second
+	}
+	if config.PingInterval <= 0 || config.PingInterval >= config.PongWait {
+		config.PingInterval = config.PongWait * 9 / 10
+	}
+	if config.WriteWait <= 0 {
+		config.WriteWait = 10 * time.Second
+	}
+	if config.MinRandomInterval <= 0 {
+		config.MinRandomInterval = time.Second
+	}
+
+	return &SocketHandler{
+		service: service,
+		broker:  broker,
+		config:  config,
+		logger:  logger,
+		clients: make(map[*socketClient]struct{}),
+		upgrader: websocket.Upgrader{
+			ReadBufferSize:  1024,
+			WriteBufferSize: 1024,
+			// Оверлеи и TV-приложения подключаются с других origin
+			CheckOrigin: func(r *http.Request) bool { return true },
+		},
+	}
+}
+
+func (h *SocketHandler) RegisterRoutes(router *mux.Router) {
+	router.HandleFunc("/ws", h.Connect).Methods("GET")
+}
+
+func (h *SocketHandler) Connect(w http.ResponseWriter, r *http.Request) {
+	if h.config.MaxConnections > 0 && h.active.Add(1) > int64(h.config.MaxConnections) {
+		h.active.Add(-1)
+		writeResponse(w, h.logger, http.StatusServiceUnavailable, Response{Error: "Too many WebSocket connections"})
+		return
+	} else if h.config.MaxConnections <= 0 {
+		h.active.Add(1)
+	}
+	defer h.active.Add(-1)
+
+	conn, err := h.upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		// Upgrader уже ответил клиенту
+		h.logger.DebugContext(r.Context(), "WebSocket upgrade failed", "error", err)
+		return
+	}
+
+	client := &socketClient{
+		handler: h,
+		conn:    conn,
+		send:    make(chan socketMessage, h.config.SendBuffer),
+		topics:  make(map[string]bool),
+		done:    make(chan struct{}),
+	}
+
+	h.mu.Lock()
+	h.clients[client] = struct{}{}
+	h.mu.Unlock()
+
+	h.logger.DebugContext(r.Context(), "WebSocket client connected", "remote_addr", r.RemoteAddr, "active", h.active.Load())
+	client.run(r.Context())
+
+	h.mu.Lock()
+	delete(h.clients, client)
+	h.mu.Unlock()
+	h.logger.DebugContext(r.Context(), "WebSocket client disconnected", "remote_addr", r.RemoteAddr)
+}
+
+func (h *SocketHandler) ActiveConnections() int64 {
+	return h.active.Load()
+}
+
+// Close закрывает все соединения: http.Server.Shutdown не ждет
+// захваченные (hijacked) соединения
+func (h *SocketHandler) Close() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for client := range h.clients {
+		client.shutdown(websocket.CloseGoingAway, "server shutting down")
+	}
+}
+
+type socketClient struct {
+	handler *SocketHandler
+	conn    *websocket.Conn
+	send    chan socketMessage
+
+	mu          sync.Mutex
+	topics      map[string]bool
+	stopRandom  context.CancelFunc
+	closeCode   int
+	closeReason string
+
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+func (c *socketClient) run(ctx context.Context) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	sub, _, _ := c.handler.broker.Subscribe(0, stream.Filter{})
+	defer c.handler.broker.Unsubscribe(sub)
+
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		c.writeLoop()
+	}()
+	go func() {
+		defer wg.Done()
+		c.eventLoop(sub)
+	}()
+
+	c.readLoop(ctx)
+
+	c.mu.Lock()
+	if c.stopRandom != nil {
+		c.stopRandom()
+	}
+	c.mu.Unlock()
+
+	c.shutdown(websocket.CloseNormalClosure, "")
+	wg.Wait()
+	c.conn.Close()
+}
+
+func (c *socketClient) readLoop(ctx context.Context) {
+	c.conn.SetReadLimit(c.handler.config.MaxMessageSize)
+	c.conn.SetReadDeadline(time.Now().Add(c.handler.config.PongWait))
+	c.conn.SetPongHandler(func(string) error {
+		return c.conn.SetReadDeadline(time.Now().Add(c.handler.config.PongWait))
+	})
+
+	for {
+		var req socketRequest
+		if err := c.conn.ReadJSON(&req); err != nil {
+			var syntaxErr *json.SyntaxError
+			if errors.As(err, &syntaxErr) {
+				c.enqueue(socketMessage{Type: "error", Error: "Invalid JSON format"})
+				continue
+			}
+			return
+		}
+
+		select {
+		case <-c.done:
+			return
+		default:
+		}
+
+		c.handle(ctx, req)
+	}
+}
+
+func (c *socketClient) handle(ctx context.Context, req socketRequest) {
+	switch req.Type {
+	case "subscribe":
+		topic, ok := normalizeTopic(req.Topic)
+		if !ok {
+			c.enqueue(socketMessage{ID: req.ID, Type: "error", Error: "Unknown topic: use quotes, author:<name> or tag:<tag>"})
+			return
+		}
+		c.mu.Lock()
+		c.topics[topic] = true
+		c.mu.Unlock()
+		c.enqueue(socketMessage{ID: req.ID, Type: "subscribed", Topic: topic})
+
+	case "unsubscribe":
+		topic, _ := normalizeTopic(req.Topic)
+		c.mu.Lock()
+		delete(c.topics, topic)
+		c.mu.Unlock()
+		c.enqueue(socketMessage{ID: req.ID, Type: "unsubscribed", Topic: topic})
+
+	case "topics":
+		c.mu.Lock()
+		topics := make([]string, 0, len(c.topics))
+		for topic := range c.topics {
+			topics = append(topics, topic)
+		}
+		c.mu.Unlock()
+		c.enqueue(socketMessage{ID: req.ID, Type: "topics", Topics: topics})
+
+	case "random":
+		c.handleRandom(ctx, req)
+
+	default:
+		c.enqueue(socketMessage{ID: req.ID, Type: "error", Error: "Unknown message type"})
+	}
+}
+
+// handleRandom: без интервала - одна цитата, "0" - остановка таймера,
+// иначе цитата сразу и далее с заданным интервалом
+func (c *socketClient) handleRandom(ctx context.Context, req socketRequest) {
+	var interval time.Duration
+	if req.Interval != "" {
+		var err error
+		interval, err = time.ParseDuration(req.Interval)
+		if err != nil || interval < 0 {
+			c.enqueue(socketMessage{ID: req.ID, Type: "error", Error: "Invalid interval"})
+			return
+		}
+		if interval > 0 && interval < c.handler.config.MinRandomInterval {
+			c.enqueue(socketMessage{ID: req.ID, Type: "error", Error: "Interval must be at least " + c.handler.config.MinRandomInterval.String()})
+			return
+		}
+	}
+
+	c.mu.Lock()
+	if c.stopRandom != nil {
+		c.stopRandom()
+		c.stopRandom = nil
+	}
+	if req.Interval != "" && interval == 0 {
+		c.mu.Unlock()
+		c.enqueue(socketMessage{ID: req.ID, Type: "random_stopped"})
+		return
+	}
+
+	var timerCtx context.Context
+	if interval > 0 {
+		timerCtx, c.stopRandom = context.WithCancel(ctx)
+	}
+	c.mu.Unlock()
+
+	c.sendRandom(ctx, req.ID)
+
+	if interval > 0 {
+		go func() {
+			ticker := time.NewTicker(interval)
+			defer ticker.Stop()
+			for {
+				select {
+				case <-timerCtx.Done():
+					return
+				case <-c.done:
+					return
+				case <-ticker.C:
+					c.sendRandom(timerCtx, req.ID)
+				}
+			}
+		}()
+	}
+}
+
+func (c *socketClient) sendRandom(ctx context.Context, id string) {
+	quote, err := c.handler.service.GetRandomQuote(ctx)
+	if err != nil {
+		if errors.Is(err, domain.ErrQuoteNotFound) {
+			c.enqueue(socketMessage{ID: id, Type: "error", Error: "No quotes found"})
+			return
+		}
+		if ctx.Err() == nil {
+			c.handler.logger.ErrorContext(ctx, "Failed to get random quote", "error", err)
+			c.enqueue(socketMessage{ID: id, Type: "error", Error: "Failed to get random quote"})
+		}
+		return
+	}
+
+	c.enqueue(socketMessage{ID: id, Type: "random_quote", Quote: quote})
+}
+
+func (c *socketClient) eventLoop(sub *stream.Subscription) {
+	for {
+		select {
+		case <-c.done:
+			return
+		case msg, ok := <-sub.C:
+			if !ok {
+				c.shutdown(websocket.CloseTryAgainLater, "event buffer overflow")
+				return
+			}
+			if topic, ok := c.matchTopic(msg.Event); ok {
+				c.enqueue(socketMessage{Type: "event", Topic: topic, Event: msg.Event})
+			}
+		}
+	}
+}
+
+func (c *socketClient) matchTopic(event *domain.Event) (string, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for topic := range c.topics {
+		if topicFilter(topic).Matches(event) {
+			return topic, true
+		}
+	}
+	return "", false
+}
+
+// enqueue не блокирует: клиент, не успевающий забирать сообщения,
+// отключается вместо того, чтобы копить память на сервере
+func (c *socketClient) enqueue(msg socketMessage) {
+	select {
+	case <-c.done:
+		return
+	default:
+	}
+
+	select {
+	case c.send <- msg:
+	default:
+		c.shutdown(websocket.CloseTryAgainLater, "send buffer overflow")
+	}
+}
+
+func (c *socketClient) writeLoop() {
+	ticker := time.NewTicker(c.handler.config.PingInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case msg := <-c.send:
+			c.conn.SetWriteDeadline(time.Now().Add(c.handler.config.WriteWait))
+			if err := c.conn.WriteJSON(msg); err != nil {
+				c.shutdown(websocket.CloseNormalClosure, "")
+				return
+			}
+		case <-ticker.C:
+			c.conn.SetWriteDeadline(time.Now().Add(c.handler.config.WriteWait))
+			if err := c.conn.WriteMessage(websocket.PingMessage, nil); err != nil {
+				c.shutdown(websocket.CloseNormalClosure, "")
+				return
+			}
+		case <-c.done:
+			c.mu.Lock()
+			code, reason := c.closeCode, c.closeReason
+			c.mu.Unlock()
+
+			c.conn.WriteControl(websocket.CloseMessage,
+				websocket.FormatCloseMessage(code, reason),
+				time.Now().Add(c.handler.config.WriteWait))
+			// Разблокирует readLoop
+			c.conn.SetReadDeadline(time.Now())
+			return
+		}
+	}
+}
+
+func (c *socketClient) shutdown(code int, reason string) {
+	c.closeOnce.Do(func() {
+		c.mu.Lock()
+		c.closeCode, c.closeReason = code, reason
+		c.mu.Unlock()
+
+		if code == websocket.CloseTryAgainLater {
+			c.handler.logger.Warn("WebSocket client dropped", "reason", reason)
+		}
+		close(c.done)
+	})
+}
+
+func normalizeTopic(topic string) (string, bool) {
+	topic = strings.TrimSpace(topic)
+	switch {
+	case topic == "quotes":
+		return topic, true
+	case strings.HasPrefix(topic, "author:") && strings.TrimSpace(topic[len("author:"):]) != "":
+		return "author:" + strings.TrimSpace(topic[len("author:"):]), true
+	case strings.HasPrefix(topic, "tag:") && strings.TrimSpace(topic[len("tag:"):]) != "":
+		return "tag:" + strings.ToLower(strings.TrimSpace(topic[len("tag:"):])), true
+	}
+	return topic, false
+}
+
+func topicFilter(topic string) stream.Filter {
+	switch {
+	case strings.HasPrefix(topic, "author:"):
+		return stream.Filter{Author: topic[len("author:"):]}
+	case strings.HasPrefix(topic, "tag:"):
+		return stream.Filter{Tag: topic[len("tag:"):]}
+	}
+	return stream.Filter{}
+}
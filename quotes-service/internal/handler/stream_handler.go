@@ -0,0 +1,130 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"quotes-service/internal/domain"
+	"quotes-service/internal/infrastructure/logger"
+	"quotes-service/internal/stream"
+
+	"github.com/gorilla/mux"
+)
+
+// StreamHandler отдает события цитат через Server-Sent Events
+type StreamHandler struct {
+	broker    *stream.Broker
+	heartbeat time.Duration
+	logger    *logger.Logger
+}
+
+func NewStreamHandler(broker *stream.Broker, heartbeat time.Duration, logger *logger.Logger) *StreamHandler {
+	if heartbeat <= 0 {
+		heartbeat = 15 * time.Second
+	}
+	return &StreamHandler{
+		broker:    broker,
+		heartbeat: heartbeat,
+		logger:    logger,
+	}
+}
+
+func (h *StreamHandler) RegisterRoutes(router *mux.Router) {
+	router.HandleFunc("/quotes/stream", h.Stream).Methods("GET")
+}
+
+func (h *StreamHandler) Stream(w http.ResponseWriter, r *http.Request) {
+	filter := stream.Filter{
+		Author: r.URL.Query().Get("author"),
+		Tag:    r.URL.Query().Get("tag"),
+	}
+	for _, t := range strings.Split(r.URL.Query().Get("types"), ",") {
+		if t = strings.TrimSpace(t); t != "" {
+			filter.Types = append(filter.Types, domain.EventType(t))
+		}
+	}
+
+	lastID, _ := strconv.ParseUint(r.Header.Get("Last-Event-ID"), 10, 64)
+	if lastID == 0 {
+		// EventSource не умеет слать заголовки при первом подключении
+		lastID, _ = strconv.ParseUint(r.URL.Query().Get("last_event_id"), 10, 64)
+	}
+
+	// WriteTimeout сервера ограничивает весь ответ, поэтому дедлайн записи
+	// продлевается перед каждой отправкой
+	rc := http.NewResponseController(w)
+	if err := rc.SetWriteDeadline(time.Now().Add(2 * h.heartbeat)); err != nil {
+		h.logger.ErrorContext(r.Context(), "Streaming is not supported by response writer", "error", err)
+		writeResponse(w, h.logger, http.StatusInternalServerError, Response{Error: "Streaming unsupported"})
+		return
+	}
+
+	sub, missed, complete := h.broker.Subscribe(lastID, filter)
+	defer h.broker.Unsubscribe(sub)
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+
+	fmt.Fprintf(w, "retry: %d\n\n", (3 * time.Second).Milliseconds())
+	if !complete {
+		fmt.Fprint(w, ": some events were dropped from the replay buffer\n\n")
+	}
+	for _, msg := range missed {
+		if err := h.writeEvent(r.Context(), w, msg); err != nil {
+			return
+		}
+	}
+	if err := rc.Flush(); err != nil {
+		return
+	}
+
+	h.logger.DebugContext(r.Context(), "SSE client connected", "remote_addr", r.RemoteAddr, "last_event_id", lastID, "replayed", len(missed))
+
+	ticker := time.NewTicker(h.heartbeat)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case msg, ok := <-sub.C:
+			if !ok {
+				// Клиент отстал - переподключится с Last-Event-ID
+				h.logger.WarnContext(r.Context(), "SSE client dropped: buffer overflow", "remote_addr", r.RemoteAddr)
+				return
+			}
+			rc.SetWriteDeadline(time.Now().Add(2 * h.heartbeat))
+			if err := h.writeEvent(r.Context(), w, msg); err != nil {
+				return
+			}
+		case <-ticker.C:
+			rc.SetWriteDeadline(time.Now().Add(2 * h.heartbeat))
+			if _, err := fmt.Fprint(w, ": heartbeat\n\n"); err != nil {
+				return
+			}
+		}
+
+		if err := rc.Flush(); err != nil {
+			return
+		}
+	}
+}
+
+func (h *StreamHandler) writeEvent(ctx context.Context, w http.ResponseWriter, msg stream.Message) error {
+	data, err := json.Marshal(msg.Event)
+	if err != nil {
+		h.logger.ErrorContext(ctx, "Failed to encode event", "error", err)
+		return nil
+	}
+
+	_, err = fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", msg.ID, msg.Event.Type, data)
+	return err
+}
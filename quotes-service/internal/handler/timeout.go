@@ -0,0 +1,101 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"quotes-service/internal/infrastructure/logger"
+)
+
+// TimeoutConfig: Routes - тайм-ауты по "МЕТОД /шаблон" без префикса версии,
+// остальные маршруты получают Default. Нулевой тайм-аут - без дедлайна
+type TimeoutConfig struct {
+	Default time.Duration
+	Routes  map[string]time.Duration
+}
+
+// ParseRouteTimeouts разбирает правила вида "GET /quotes=15s", через запятую
+func ParseRouteTimeouts(spec string) (map[string]time.Duration, error) {
+	routes := make(map[string]time.Duration)
+	for _, item := range strings.Split(spec, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+
+		route, value, ok := strings.Cut(item, "=")
+		method, path, okRoute := strings.Cut(strings.TrimSpace(route), " ")
+		if !ok || !okRoute {
+			return nil, fmt.Errorf("invalid route timeout %q: want METHOD PATH=DURATION", item)
+		}
+		timeout, err := time.ParseDuration(strings.TrimSpace(value))
+		if err != nil || timeout < 0 {
+			return nil, fmt.Errorf("invalid duration in route timeout %q", item)
+		}
+		routes[strings.ToUpper(method)+" "+strings.TrimSpace(path)] = timeout
+	}
+	return routes, nil
+}
+
+// Timeouts ограничивает обработку запроса дедлайном контекста. Сервисный
+// слой передает контекст в запросы к БД, а истекший дедлайн отдается как 504.
+// Долгоживущие маршруты (SSE, WebSocket) монтируются без этого middleware
+type Timeouts struct {
+	config atomic.Pointer[TimeoutConfig]
+}
+
+func NewTimeouts(config TimeoutConfig) *Timeouts {
+	t := &Timeouts{}
+	t.Update(config)
+	return t
+}
+
+// Update заменяет тайм-ауты на лету; запросы в работе сохраняют прежний дедлайн
+func (t *Timeouts) Update(config TimeoutConfig) {
+	t.config.Store(&config)
+}
+
+func (t *Timeouts) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		timeout := t.timeout(r)
+		if timeout <= 0 {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(r.Context(), timeout)
+		defer cancel()
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+func (t *Timeouts) timeout(r *http.Request) time.Duration {
+	config := t.config.Load()
+	if template, ok := versionlessRoute(r); ok {
+		if timeout, ok := config.Routes[r.Method+" "+template]; ok {
+			return timeout
+		}
+	}
+	return config.Default
+}
+
+// isTimeout: драйвер БД при отмене запроса возвращает собственную ошибку
+// (pq: canceling statement), поэтому кроме err проверяется и сам контекст
+func isTimeout(ctx context.Context, err error) bool {
+	return errors.Is(err, context.DeadlineExceeded) || errors.Is(ctx.Err(), context.DeadlineExceeded)
+}
+
+// writeServerError отвечает 504, если истек дедлайн операции, иначе 500
+func writeServerError(w http.ResponseWriter, r *http.Request, logger *logger.Logger, err error, message string) {
+	status := http.StatusInternalServerError
+	if isTimeout(r.Context(), err) {
+		status = http.StatusGatewayTimeout
+		message += ": request timed out"
+	}
+	writeResponse(w, logger, status, Response{Error: message})
+}
@@ -0,0 +1,60 @@
+package handler
+
+import (
+	"net/http"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Tracing открывает серверный span на каждый HTTP-запрос, продолжая трейс из
+// входящего traceparent. Handler оборачивает маршрутизатор целиком, а
+// RouteMiddleware внутри него называет span по шаблону маршрута
+type Tracing struct {
+	tracer     trace.Tracer
+	propagator propagation.TextMapPropagator
+}
+
+func NewTracing() *Tracing {
+	return &Tracing{
+		tracer:     otel.Tracer("quotes-service/internal/handler"),
+		propagator: otel.GetTextMapPropagator(),
+	}
+}
+
+func (t *Tracing) Handler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := t.propagator.Extract(r.Context(), propagation.HeaderCarrier(r.Header))
+		ctx, span := t.tracer.Start(ctx, r.Method,
+			trace.WithSpanKind(trace.SpanKindServer),
+			trace.WithAttributes(
+				semconv.HTTPRequestMethodKey.String(r.Method),
+				semconv.URLPath(r.URL.Path),
+				semconv.UserAgentOriginal(r.UserAgent()),
+			),
+		)
+		defer span.End()
+
+		ww := &responseWriter{ResponseWriter: w, statusCode: http.StatusOK}
+		next.ServeHTTP(ww, r.WithContext(ctx))
+
+		span.SetAttributes(semconv.HTTPResponseStatusCode(ww.statusCode))
+		if ww.statusCode >= http.StatusInternalServerError {
+			span.SetStatus(codes.Error, http.StatusText(ww.statusCode))
+		}
+	})
+}
+
+func (t *Tracing) RouteMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if route, ok := routeTemplate(r); ok {
+			span := trace.SpanFromContext(r.Context())
+			span.SetName(r.Method + " " + route)
+			span.SetAttributes(semconv.HTTPRoute(route))
+		}
+		next.ServeHTTP(w, r)
+	})
+}
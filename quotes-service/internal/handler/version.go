@@ -0,0 +1,91 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+// RouteRegistrar регистрирует маршруты одной версии API. Обработчики разных
+// версий работают поверх одного сервисного слоя и отличаются только формой ответов,
+// поэтому для /v2 заводится новый набор обработчиков, а не ветвления внутри v1
+type RouteRegistrar interface {
+	RegisterRoutes(router *mux.Router)
+}
+
+// APIVersion - набор обработчиков, смонтированных под префиксом /<Name>;
+// Middleware оборачивает каждый маршрут версии, включая устаревшие алиасы
+type APIVersion struct {
+	Name       string
+	Handlers   []RouteRegistrar
+	Middleware []mux.MiddlewareFunc
+}
+
+// Deprecation описывает вывод неверсионированных путей из эксплуатации
+type Deprecation struct {
+	Since  time.Time
+	Sunset time.Time
+}
+
+// MountVersions монтирует каждую версию API под своим префиксом
+func MountVersions(router *mux.Router, versions ...APIVersion) {
+	for _, version := range versions {
+		Mount(router, "/"+version.Name, version.Handlers, version.Middleware...)
+	}
+}
+
+// MountDeprecatedAliases регистрирует маршруты версии без префикса для старых
+// клиентов; ответы помечаются заголовками Deprecation, Sunset и ссылкой на замену
+func MountDeprecatedAliases(router *mux.Router, version APIVersion, deprecation Deprecation) {
+	middleware := append([]mux.MiddlewareFunc{deprecationMiddleware(version.Name, deprecation)}, version.Middleware...)
+	Mount(router, "", version.Handlers, middleware...)
+}
+
+// Mount переносит маршруты обработчиков на корневой маршрутизатор с префиксом
+// и middleware. Подроутеры mux не используются: они сбрасывают ErrMethodMismatch
+// соседних маршрутов, и вместо 405 клиент получает 404
+func Mount(router *mux.Router, prefix string, handlers []RouteRegistrar, middleware ...mux.MiddlewareFunc) {
+	staging := mux.NewRouter()
+	for _, handler := range handlers {
+		handler.RegisterRoutes(staging)
+	}
+
+	staging.Walk(func(route *mux.Route, _ *mux.Router, _ []*mux.Route) error {
+		template, err := route.GetPathTemplate()
+		if err != nil {
+			return nil
+		}
+
+		handler := route.GetHandler()
+		for i := len(middleware) - 1; i >= 0; i-- {
+			handler = middleware[i](handler)
+		}
+
+		mounted := router.Handle(prefix+template, handler)
+		if methods, err := route.GetMethods(); err == nil {
+			mounted.Methods(methods...)
+		}
+		return nil
+	})
+}
+
+func deprecationMiddleware(version string, deprecation Deprecation) mux.MiddlewareFunc {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			// RFC 9745: дата в формате structured field, RFC 8594: HTTP-date
+			if deprecation.Since.IsZero() {
+				w.Header().Set("Deprecation", "true")
+			} else {
+				w.Header().Set("Deprecation", "@"+strconv.FormatInt(deprecation.Since.Unix(), 10))
+			}
+			if !deprecation.Sunset.IsZero() {
+				w.Header().Set("Sunset", deprecation.Sunset.UTC().Format(http.TimeFormat))
+			}
+			w.Header().Add("Link", "</"+version+r.URL.Path+`>; rel="successor-version"`)
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
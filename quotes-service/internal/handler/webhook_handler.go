@@ -0,0 +1,196 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"quotes-service/internal/domain"
+	"quotes-service/internal/infrastructure/logger"
+	"quotes-service/internal/service"
+
+	"github.com/gorilla/mux"
+)
+
+// WebhookHandler - управление подписками и доставками. URL подписок часто
+// содержат токены получателей, а доставки - полные события, поэтому все
+// маршруты, включая чтение, доступны только администраторам
+type WebhookHandler struct {
+	service *service.WebhookService
+	auth    *Authenticator
+	logger  *logger.Logger
+}
+
+// Секрет отдается только в ответе на создание подписки
+type createdWebhookResponse struct {
+	*domain.WebhookSubscription
+	Secret string `json:"secret"`
+}
+
+func NewWebhookHandler(service *service.WebhookService, auth *Authenticator, logger *logger.Logger) *WebhookHandler {
+	return &WebhookHandler{
+		service: service,
+		auth:    auth,
+		logger:  logger,
+	}
+}
+
+func (h *WebhookHandler) RegisterRoutes(router *mux.Router) {
+	router.Handle("/webhooks", h.admin(h.CreateSubscription)).Methods("POST")
+	router.Handle("/webhooks", h.admin(h.ListSubscriptions)).Methods("GET")
+	router.Handle("/webhooks/dead-letters", h.admin(h.ListDeadLetters)).Methods("GET")
+	router.Handle("/webhooks/deliveries/{id:[0-9]+}/redeliver", h.admin(h.Redeliver)).Methods("POST")
+	router.Handle("/webhooks/{id:[0-9]+}", h.admin(h.GetSubscription)).Methods("GET")
+	router.Handle("/webhooks/{id:[0-9]+}", h.admin(h.UpdateSubscription)).Methods("PUT")
+	router.Handle("/webhooks/{id:[0-9]+}", h.admin(h.DeleteSubscription)).Methods("DELETE")
+	router.Handle("/webhooks/{id:[0-9]+}/deliveries", h.admin(h.ListDeliveries)).Methods("GET")
+}
+
+func (h *WebhookHandler) admin(next http.HandlerFunc) http.Handler {
+	return h.auth.Require(domain.PermissionManageKeys, next)
+}
+
+func (h *WebhookHandler) CreateSubscription(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var req domain.WebhookRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.sendError(w, http.StatusBadRequest, "Invalid JSON format")
+		return
+	}
+
+	sub, err := h.service.CreateSubscription(ctx, req)
+	if err != nil {
+		h.handleError(w, r, err, "Failed to create webhook subscription")
+		return
+	}
+
+	h.sendSuccess(w, http.StatusCreated, createdWebhookResponse{WebhookSubscription: sub, Secret: sub.Secret})
+}
+
+func (h *WebhookHandler) ListSubscriptions(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	subs, err := h.service.ListSubscriptions(ctx)
+	if err != nil {
+		h.handleError(w, r, err, "Failed to list webhook subscriptions")
+		return
+	}
+
+	h.sendSuccess(w, http.StatusOK, subs)
+}
+
+func (h *WebhookHandler) GetSubscription(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	id, _ := strconv.Atoi(mux.Vars(r)["id"])
+	sub, err := h.service.GetSubscription(ctx, id)
+	if err != nil {
+		h.handleError(w, r, err, "Failed to get webhook subscription")
+		return
+	}
+
+	h.sendSuccess(w, http.StatusOK, sub)
+}
+
+func (h *WebhookHandler) UpdateSubscription(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	id, _ := strconv.Atoi(mux.Vars(r)["id"])
+
+	var req domain.WebhookRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		h.sendError(w, http.StatusBadRequest, "Invalid JSON format")
+		return
+	}
+
+	sub, err := h.service.UpdateSubscription(ctx, id, req)
+	if err != nil {
+		h.handleError(w, r, err, "Failed to update webhook subscription")
+		return
+	}
+
+	h.sendSuccess(w, http.StatusOK, sub)
+}
+
+func (h *WebhookHandler) DeleteSubscription(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	id, _ := strconv.Atoi(mux.Vars(r)["id"])
+	if err := h.service.DeleteSubscription(ctx, id); err != nil {
+		h.handleError(w, r, err, "Failed to delete webhook subscription")
+		return
+	}
+
+	h.sendSuccess(w, http.StatusOK, map[string]string{
+		"message": "Webhook subscription deleted successfully",
+	})
+}
+
+func (h *WebhookHandler) ListDeliveries(w http.ResponseWriter, r *http.Request) {
+	id, _ := strconv.Atoi(mux.Vars(r)["id"])
+	h.listDeliveries(w, r, domain.DeliveryFilter{
+		SubscriptionID: id,
+		Status:         domain.DeliveryStatus(r.URL.Query().Get("status")),
+	})
+}
+
+func (h *WebhookHandler) ListDeadLetters(w http.ResponseWriter, r *http.Request) {
+	h.listDeliveries(w, r, domain.DeliveryFilter{Status: domain.DeliveryDead})
+}
+
+func (h *WebhookHandler) Redeliver(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	id, _ := strconv.ParseInt(mux.Vars(r)["id"], 10, 64)
+	delivery, err := h.service.Redeliver(ctx, id)
+	if err != nil {
+		h.handleError(w, r, err, "Failed to requeue webhook delivery")
+		return
+	}
+
+	h.sendSuccess(w, http.StatusAccepted, delivery)
+}
+
+func (h *WebhookHandler) listDeliveries(w http.ResponseWriter, r *http.Request, filter domain.DeliveryFilter) {
+	ctx := r.Context()
+
+	if limitStr := r.URL.Query().Get("limit"); limitStr != "" {
+		if limit, err := strconv.Atoi(limitStr); err == nil && limit > 0 {
+			filter.Limit = limit
+		}
+	}
+
+	deliveries, err := h.service.ListDeliveries(ctx, filter)
+	if err != nil {
+		h.handleError(w, r, err, "Failed to list webhook deliveries")
+		return
+	}
+
+	h.sendSuccess(w, http.StatusOK, deliveries)
+}
+
+func (h *WebhookHandler) handleError(w http.ResponseWriter, r *http.Request, err error, message string) {
+	switch {
+	case errors.Is(err, domain.ErrInvalidWebhook):
+		h.sendError(w, http.StatusBadRequest, err.Error())
+	case errors.Is(err, domain.ErrWebhookNotFound):
+		h.sendError(w, http.StatusNotFound, "Webhook subscription not found")
+	case errors.Is(err, domain.ErrDeliveryNotFound):
+		h.sendError(w, http.StatusNotFound, "Webhook delivery not found")
+	case errors.Is(err, domain.ErrDeliveryNotDead):
+		h.sendError(w, http.StatusConflict, "Only dead-letter deliveries can be redelivered")
+	default:
+		h.logger.ErrorContext(r.Context(), message, "error", err)
+		writeServerError(w, r, h.logger, err, message)
+	}
+}
+
+func (h *WebhookHandler) sendSuccess(w http.ResponseWriter, statusCode int, data interface{}) {
+	writeResponse(w, h.logger, statusCode, Response{Data: data})
+}
+
+func (h *WebhookHandler) sendError(w http.ResponseWriter, statusCode int, message string) {
+	writeResponse(w, h.logger, statusCode, Response{Error: message})
+}
@@ -0,0 +1,60 @@
+package health
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"quotes-service/internal/domain"
+	"quotes-service/internal/infrastructure/database"
+)
+
+// Database пингует primary и сообщает число здоровых реплик
+func Database(cluster *database.Cluster) CheckFunc {
+	return func(ctx context.Context) (interface{}, error) {
+		details := map[string]int{
+			"replicas":         len(cluster.Pools()) - 1,
+			"healthy_replicas": cluster.HealthyReplicas(),
+		}
+		return details, cluster.PingContext(ctx)
+	}
+}
+
+// Migrations проверяет, что схема БД соответствует последней миграции
+func Migrations(check func(ctx context.Context) ([]string, error)) CheckFunc {
+	return func(ctx context.Context) (interface{}, error) {
+		missing, err := check(ctx)
+		if err != nil {
+			return nil, err
+		}
+		if len(missing) > 0 {
+			return map[string][]string{"missing": missing}, fmt.Errorf("%d migrations are not applied", len(missing))
+		}
+		return nil, nil
+	}
+}
+
+// Cache всегда исправен (кэш в памяти) и отдает статистику попаданий
+func Cache(stats func() (domain.CacheStats, bool)) CheckFunc {
+	return func(ctx context.Context) (interface{}, error) {
+		if s, ok := stats(); ok {
+			return s, nil
+		}
+		return map[string]bool{"enabled": false}, nil
+	}
+}
+
+// OutboxLag падает, если самое старое неопубликованное событие ждет дольше maxLag
+func OutboxLag(outbox domain.OutboxRepository, maxLag time.Duration) CheckFunc {
+	return func(ctx context.Context) (interface{}, error) {
+		lag, err := outbox.PendingLag(ctx)
+		if err != nil {
+			return nil, err
+		}
+		details := map[string]string{"lag": lag.String(), "max_lag": maxLag.String()}
+		if lag > maxLag {
+			return details, fmt.Errorf("outbox lag %s exceeds %s", lag, maxLag)
+		}
+		return details, nil
+	}
+}
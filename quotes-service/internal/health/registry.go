@@ -0,0 +1,174 @@
+package health
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Probe - вид проверки: liveness (процесс жив), readiness (готов принимать
+// трафик), startup (закончил запуск)
+type Probe string
+
+const (
+	Liveness  Probe = "livez"
+	Readiness Probe = "readyz"
+	Startup   Probe = "startupz"
+)
+
+const (
+	StatusOK   = "ok"
+	StatusFail = "fail"
+	// StatusWarn - сбой необязательной проверки, probe остается успешным
+	StatusWarn = "warn"
+)
+
+// CheckFunc проверяет зависимость; details попадают в подробный отчет
+type CheckFunc func(ctx context.Context) (details interface{}, err error)
+
+type CheckResult struct {
+	Name        string      `json:"name"`
+	Status      string      `json:"status"`
+	Latency     string      `json:"latency"`
+	Error       string      `json:"error,omitempty"`
+	LastError   string      `json:"last_error,omitempty"`
+	LastErrorAt *time.Time  `json:"last_error_at,omitempty"`
+	Details     interface{} `json:"details,omitempty"`
+}
+
+type Report struct {
+	Status string        `json:"status"`
+	Reason string        `json:"reason,omitempty"`
+	Checks []CheckResult `json:"checks,omitempty"`
+}
+
+type check struct {
+	name     string
+	fn       CheckFunc
+	probes   []Probe
+	optional bool
+
+	mu          sync.Mutex
+	lastError   string
+	lastErrorAt time.Time
+}
+
+// Registry хранит проверки и выполняет их параллельно, каждую со своим
+// тайм-аутом. Последняя ошибка проверки запоминается и показывается, даже
+// когда зависимость восстановилась. Startup после первого успеха больше
+// проверки не выполняет
+type Registry struct {
+	timeout      atomic.Int64
+	mu           sync.RWMutex
+	checks       []*check
+	started      atomic.Bool
+	shuttingDown atomic.Bool
+}
+
+func NewRegistry(timeout time.Duration) *Registry {
+	r := &Registry{}
+	r.SetTimeout(timeout)
+	return r
+}
+
+// SetTimeout меняет тайм-аут проверок на лету
+func (r *Registry) SetTimeout(timeout time.Duration) {
+	r.timeout.Store(int64(timeout))
+}
+
+// Register добавляет проверку в перечисленные probe
+func (r *Registry) Register(name string, fn CheckFunc, probes ...Probe) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.checks = append(r.checks, &check{name: name, fn: fn, probes: probes})
+}
+
+// RegisterOptional добавляет проверку, сбой которой виден в отчете, но не
+// переводит probe в fail: например, отставание outbox не должно снимать все
+// инстансы с балансировки
+func (r *Registry) RegisterOptional(name string, fn CheckFunc, probes ...Probe) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.checks = append(r.checks, &check{name: name, fn: fn, probes: probes, optional: true})
+}
+
+// SetShuttingDown переводит readiness в fail, чтобы балансировщик перестал
+// присылать запросы до остановки сервера
+func (r *Registry) SetShuttingDown() {
+	r.shuttingDown.Store(true)
+}
+
+func (r *Registry) Run(ctx context.Context, probe Probe) Report {
+	switch {
+	case probe == Readiness && r.shuttingDown.Load():
+		return Report{Status: StatusFail, Reason: "shutting down"}
+	case probe == Startup && r.started.Load():
+		return Report{Status: StatusOK}
+	}
+
+	r.mu.RLock()
+	var checks []*check
+	for _, c := range r.checks {
+		for _, p := range c.probes {
+			if p == probe {
+				checks = append(checks, c)
+				break
+			}
+		}
+	}
+	r.mu.RUnlock()
+
+	report := Report{Status: StatusOK, Checks: make([]CheckResult, len(checks))}
+	var wg sync.WaitGroup
+	for i, c := range checks {
+		wg.Add(1)
+		go func(i int, c *check) {
+			defer wg.Done()
+			report.Checks[i] = r.run(ctx, c)
+		}(i, c)
+	}
+	wg.Wait()
+
+	for _, result := range report.Checks {
+		if result.Status == StatusFail {
+			report.Status = StatusFail
+		}
+	}
+	if probe == Startup && report.Status == StatusOK {
+		r.started.Store(true)
+	}
+	return report
+}
+
+func (r *Registry) run(ctx context.Context, c *check) CheckResult {
+	ctx, cancel := context.WithTimeout(ctx, time.Duration(r.timeout.Load()))
+	defer cancel()
+
+	start := time.Now()
+	details, err := c.fn(ctx)
+	result := CheckResult{
+		Name:    c.name,
+		Status:  StatusOK,
+		Latency: time.Since(start).String(),
+		Details: details,
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err != nil {
+		result.Status = StatusFail
+		if c.optional {
+			result.Status = StatusWarn
+		}
+		result.Error = err.Error()
+		c.lastError = err.Error()
+		c.lastErrorAt = start
+	}
+	if c.lastError != "" {
+		at := c.lastErrorAt
+		result.LastError = c.lastError
+		result.LastErrorAt = &at
+	}
+	return result
+}
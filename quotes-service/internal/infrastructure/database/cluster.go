@@ -0,0 +1,224 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"sync/atomic"
+	"time"
+
+	"quotes-service/internal/infrastructure/logger"
+
+	"github.com/lib/pq"
+)
+
+// Cluster держит пул primary для записи и пулы реплик для чтения.
+// Реплики выбираются по round-robin, недоступные временно исключаются.
+type Cluster struct {
+	primary  *sql.DB
+	replicas []*replica
+	next     atomic.Uint64
+	logger   *logger.Logger
+}
+
+type replica struct {
+	name    string
+	db      *sql.DB
+	healthy atomic.Bool
+}
+
+type primaryKey struct{}
+
+// WithPrimary помечает контекст так, что чтения идут в primary
+// (read-your-writes сразу после записи).
+func WithPrimary(ctx context.Context) context.Context {
+	return context.WithValue(ctx, primaryKey{}, true)
+}
+
+func PrimaryRequested(ctx context.Context) bool {
+	forced, _ := ctx.Value(primaryKey{}).(bool)
+	return forced
+}
+
+type transactionKey struct{}
+
+// WithTransaction помечает контекст открытой транзакцией: чтения в ней
+// должны видеть ее незафиксированные изменения, поэтому идут мимо кэша
+func WithTransaction(ctx context.Context) context.Context {
+	return context.WithValue(ctx, transactionKey{}, true)
+}
+
+func InTransaction(ctx context.Context) bool {
+	inTx, _ := ctx.Value(transactionKey{}).(bool)
+	return inTx
+}
+
+func NewCluster(primary *sql.DB, logger *logger.Logger, replicas ...*sql.DB) *Cluster {
+	c := &Cluster{
+		primary: primary,
+		logger:  logger,
+	}
+	for i, db := range replicas {
+		r := &replica{name: fmt.Sprintf("replica-%d", i), db: db}
+		r.healthy.Store(true)
+		c.replicas = append(c.replicas, r)
+	}
+	return c
+}
+
+func NewPostgresCluster(config Config, logger *logger.Logger) (*Cluster, error) {
+	primary, err := NewPostgresConnection(config)
+	if err != nil {
+		return nil, err
+	}
+
+	replicas := make([]*sql.DB, 0, len(config.ReplicaURLs))
+	for _, url := range config.ReplicaURLs {
+		db, err := openPostgres(url, config)
+		if err != nil {
+			for _, opened := range replicas {
+				opened.Close()
+			}
+			primary.Close()
+			return nil, fmt.Errorf("failed to open replica: %w", err)
+		}
+		replicas = append(replicas, db)
+	}
+
+	cluster := NewCluster(primary, logger, replicas...)
+
+	// Недоступная на старте реплика не мешает запуску, но сразу исключается
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	cluster.CheckReplicas(ctx)
+
+	return cluster, nil
+}
+
+func (c *Cluster) Primary() *sql.DB {
+	return c.primary
+}
+
+// Reader возвращает пул для чтения: следующую здоровую реплику
+// или primary, если реплик нет или запрошен read-your-writes.
+func (c *Cluster) Reader(ctx context.Context) *sql.DB {
+	if len(c.replicas) == 0 || PrimaryRequested(ctx) {
+		return c.primary
+	}
+
+	start := c.next.Add(1)
+	for i := 0; i < len(c.replicas); i++ {
+		r := c.replicas[(start+uint64(i))%uint64(len(c.replicas))]
+		if r.healthy.Load() {
+			return r.db
+		}
+	}
+
+	return c.primary
+}
+
+// ReportFailure исключает реплику после ошибки соединения; вернется она
+// после успешной проверки в CheckReplicas. Ошибки запроса (конфликт,
+// нарушение ограничения, отмена) реплику не исключают.
+func (c *Cluster) ReportFailure(db *sql.DB, err error) {
+	if db == c.primary || !isConnectionError(err) {
+		return
+	}
+
+	for _, r := range c.replicas {
+		if r.db == db && r.healthy.CompareAndSwap(true, false) {
+			c.logger.Warn("Replica evicted", "replica", r.name, "error", err)
+		}
+	}
+}
+
+func isConnectionError(err error) bool {
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	if errors.Is(err, driver.ErrBadConn) || errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+		return true
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		return true
+	}
+
+	// Класс 08 - connection exception
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Code.Class() == "08"
+}
+
+func (c *Cluster) CheckReplicas(ctx context.Context) {
+	for _, r := range c.replicas {
+		err := r.db.PingContext(ctx)
+		switch {
+		case err != nil && r.healthy.CompareAndSwap(true, false):
+			c.logger.Warn("Replica evicted", "replica", r.name, "error", err)
+		case err == nil && r.healthy.CompareAndSwap(false, true):
+			c.logger.Info("Replica restored", "replica", r.name)
+		}
+	}
+}
+
+// RunHealthChecks периодически пингует реплики до отмены контекста.
+func (c *Cluster) RunHealthChecks(ctx context.Context, interval time.Duration) {
+	if len(c.replicas) == 0 || interval <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			checkCtx, cancel := context.WithTimeout(ctx, interval)
+			c.CheckReplicas(checkCtx)
+			cancel()
+		}
+	}
+}
+
+func (c *Cluster) HealthyReplicas() int {
+	healthy := 0
+	for _, r := range c.replicas {
+		if r.healthy.Load() {
+			healthy++
+		}
+	}
+	return healthy
+}
+
+// Pools возвращает все пулы по именам: primary и replica-N
+func (c *Cluster) Pools() map[string]*sql.DB {
+	pools := map[string]*sql.DB{"primary": c.primary}
+	for _, r := range c.replicas {
+		pools[r.name] = r.db
+	}
+	return pools
+}
+
+func (c *Cluster) PingContext(ctx context.Context) error {
+	return c.primary.PingContext(ctx)
+}
+
+func (c *Cluster) Close() error {
+	var errs []error
+	for _, r := range c.replicas {
+		if err := r.db.Close(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if err := c.primary.Close(); err != nil {
+		errs = append(errs, err)
+	}
+	return errors.Join(errs...)
+}
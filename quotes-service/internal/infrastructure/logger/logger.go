@@ -1,52 +1,270 @@
 package logger
 
 import (
+	"context"
+	"fmt"
+	"io"
 	"log/slog"
 	"os"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+const (
+	FormatJSON   = "json"
+	FormatText   = "text"
+	FormatPretty = "pretty"
 )
 
+// Config: Output - stdout, stderr или путь к файлу, который ротируется по
+// достижении MaxSizeMB. Debug-записи с одинаковым сообщением сэмплируются:
+// за SamplePeriod пишутся первые SampleFirst, затем каждая SampleThereafter-я
+type Config struct {
+	Level            string
+	Format           string
+	Output           string
+	AddSource        bool
+	MaxSizeMB        int
+	MaxBackups       int
+	SampleFirst      int
+	SampleThereafter int
+	SamplePeriod     time.Duration
+	RedactKeys       []string
+}
+
 type Logger struct {
 	logger *slog.Logger
+	level  *slog.LevelVar
+	closer io.Closer
 }
 
+// New создает JSON-логгер в stdout с заданным уровнем
 func New(level string) *Logger {
-	var logLevel slog.Level
-	switch level {
+	l, _ := NewWithConfig(Config{Level: level, Format: FormatJSON, AddSource: true})
+	return l
+}
+
+func NewWithConfig(config Config) (*Logger, error) {
+	levelVar := new(slog.LevelVar)
+	level, err := ParseLevel(config.Level)
+	if err != nil {
+		level = slog.LevelInfo
+	}
+	levelVar.Set(level)
+
+	var (
+		out    io.Writer
+		closer io.Closer
+	)
+	switch config.Output {
+	case "", "stdout":
+		out = os.Stdout
+	case "stderr":
+		out = os.Stderr
+	default:
+		file, err := newRotatingFile(config.Output, int64(config.MaxSizeMB)<<20, config.MaxBackups)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open log file: %w", err)
+		}
+		out, closer = file, file
+	}
+
+	opts := &slog.HandlerOptions{
+		Level:       levelVar,
+		AddSource:   config.AddSource,
+		ReplaceAttr: redactor(config.RedactKeys),
+	}
+
+	var handler slog.Handler
+	switch config.Format {
+	case FormatJSON, "":
+		handler = slog.NewJSONHandler(out, opts)
+	case FormatText:
+		handler = slog.NewTextHandler(out, opts)
+	case FormatPretty:
+		handler = newPrettyHandler(out, opts)
+	default:
+		return nil, fmt.Errorf("unknown log format %q", config.Format)
+	}
+
+	if config.SampleFirst > 0 {
+		handler = newSamplingHandler(handler, config.SampleFirst, config.SampleThereafter, config.SamplePeriod)
+	}
+	handler = contextHandler{handler}
+
+	return &Logger{logger: slog.New(handler), level: levelVar, closer: closer}, nil
+}
+
+// ParseLevel разбирает debug, info, warn или error
+func ParseLevel(level string) (slog.Level, error) {
+	switch strings.ToLower(level) {
 	case "debug":
-		logLevel = slog.LevelDebug
+		return slog.LevelDebug, nil
 	case "info":
-		logLevel = slog.LevelInfo
+		return slog.LevelInfo, nil
 	case "warn":
-		logLevel = slog.LevelWarn
+		return slog.LevelWarn, nil
 	case "error":
-		logLevel = slog.LevelError
-	default:
-		logLevel = slog.LevelInfo
+		return slog.LevelError, nil
 	}
+	return slog.LevelInfo, fmt.Errorf("unknown log level %q", level)
+}
 
-	opts := &slog.HandlerOptions{
-		Level:     logLevel,
-		AddSource: true,
+// Level возвращает текущий уровень: debug, info, warn или error
+func (l *Logger) Level() string {
+	return strings.ToLower(l.level.Level().String())
+}
+
+// SetLevel меняет уровень на лету; действует и на логгеры, полученные через With
+func (l *Logger) SetLevel(level string) error {
+	parsed, err := ParseLevel(level)
+	if err != nil {
+		return err
 	}
+	l.level.Set(parsed)
+	return nil
+}
 
-	handler := slog.NewJSONHandler(os.Stdout, opts)
-	logger := slog.New(handler)
+// Close закрывает файл логов, если вывод идет в файл
+func (l *Logger) Close() error {
+	if l.closer == nil {
+		return nil
+	}
+	return l.closer.Close()
+}
 
-	return &Logger{logger: logger}
+// With возвращает логгер, добавляющий атрибуты к каждой записи
+func (l *Logger) With(args ...interface{}) *Logger {
+	return &Logger{logger: l.logger.With(args...), level: l.level, closer: l.closer}
 }
 
 func (l *Logger) Info(msg string, args ...interface{}) {
-	l.logger.Info(msg, args...)
+	l.log(context.Background(), slog.LevelInfo, msg, args)
 }
 
 func (l *Logger) Error(msg string, args ...interface{}) {
-	l.logger.Error(msg, args...)
+	l.log(context.Background(), slog.LevelError, msg, args)
 }
 
 func (l *Logger) Debug(msg string, args ...interface{}) {
-	l.logger.Debug(msg, args...)
+	l.log(context.Background(), slog.LevelDebug, msg, args)
 }
 
 func (l *Logger) Warn(msg string, args ...interface{}) {
-	l.logger.Warn(msg, args...)
+	l.log(context.Background(), slog.LevelWarn, msg, args)
+}
+
+// Методы *Context добавляют к записи поля запроса из контекста (request_id,
+// route, principal) и trace_id и span_id текущего span
+func (l *Logger) InfoContext(ctx context.Context, msg string, args ...interface{}) {
+	l.log(ctx, slog.LevelInfo, msg, args)
+}
+
+func (l *Logger) ErrorContext(ctx context.Context, msg string, args ...interface{}) {
+	l.log(ctx, slog.LevelError, msg, args)
+}
+
+func (l *Logger) DebugContext(ctx context.Context, msg string, args ...interface{}) {
+	l.log(ctx, slog.LevelDebug, msg, args)
+}
+
+func (l *Logger) WarnContext(ctx context.Context, msg string, args ...interface{}) {
+	l.log(ctx, slog.LevelWarn, msg, args)
+}
+
+// log пропускает кадры оберток, чтобы source указывал на место вызова
+func (l *Logger) log(ctx context.Context, level slog.Level, msg string, args []interface{}) {
+	if !l.logger.Enabled(ctx, level) {
+		return
+	}
+
+	var pcs [1]uintptr
+	runtime.Callers(3, pcs[:])
+	record := slog.NewRecord(time.Now(), level, msg, pcs[0])
+	record.Add(args...)
+	_ = l.logger.Handler().Handle(ctx, record)
+}
+
+// redactor заменяет значения чувствительных ключей (без учета регистра)
+func redactor(keys []string) func(groups []string, attr slog.Attr) slog.Attr {
+	if len(keys) == 0 {
+		return nil
+	}
+	redacted := make(map[string]bool, len(keys))
+	for _, key := range keys {
+		redacted[strings.ToLower(key)] = true
+	}
+
+	return func(_ []string, attr slog.Attr) slog.Attr {
+		if redacted[strings.ToLower(attr.Key)] {
+			return slog.String(attr.Key, "[REDACTED]")
+		}
+		return attr
+	}
+}
+
+type fieldsKey struct{}
+
+// fields - атрибуты запроса, общие для всех записей с его контекстом.
+// Набор изменяемый: middleware дополняют его по мере прохождения запроса,
+// и поля видны даже в записях внешних middleware
+type fields struct {
+	mu    sync.Mutex
+	attrs []slog.Attr
+}
+
+// NewContext заводит в контексте набор полей запроса
+func NewContext(ctx context.Context) context.Context {
+	return context.WithValue(ctx, fieldsKey{}, &fields{})
+}
+
+// SetField задает поле запроса; без NewContext вызов ничего не делает
+func SetField(ctx context.Context, key string, value interface{}) {
+	f, ok := ctx.Value(fieldsKey{}).(*fields)
+	if !ok {
+		return
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for i := range f.attrs {
+		if f.attrs[i].Key == key {
+			f.attrs[i] = slog.Any(key, value)
+			return
+		}
+	}
+	f.attrs = append(f.attrs, slog.Any(key, value))
+}
+
+// contextHandler дописывает поля запроса и идентификаторы трассировки
+// из контекста записи
+type contextHandler struct {
+	slog.Handler
+}
+
+func (h contextHandler) Handle(ctx context.Context, record slog.Record) error {
+	if f, ok := ctx.Value(fieldsKey{}).(*fields); ok {
+		f.mu.Lock()
+		record.AddAttrs(f.attrs...)
+		f.mu.Unlock()
+	}
+	if spanContext := trace.SpanContextFromContext(ctx); spanContext.IsValid() {
+		record.AddAttrs(
+			slog.String("trace_id", spanContext.TraceID().String()),
+			slog.String("span_id", spanContext.SpanID().String()),
+		)
+	}
+	return h.Handler.Handle(ctx, record)
+}
+
+func (h contextHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return contextHandler{h.Handler.WithAttrs(attrs)}
+}
+
+func (h contextHandler) WithGroup(name string) slog.Handler {
+	return contextHandler{h.Handler.WithGroup(name)}
 }
@@ -0,0 +1,121 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+)
+
+var levelColors = map[slog.Level]string{
+	slog.LevelDebug: "\x1b[90m",
+	slog.LevelInfo:  "\x1b[36m",
+	slog.LevelWarn:  "\x1b[33m",
+	slog.LevelError: "\x1b[31m",
+}
+
+const colorReset = "\x1b[0m"
+
+// prettyHandler - цветной вывод для локальной разработки:
+// 15:04:05.000 INFO  сообщение key=value (file.go:42)
+type prettyHandler struct {
+	out    io.Writer
+	mu     *sync.Mutex
+	opts   slog.HandlerOptions
+	attrs  []slog.Attr
+	groups []string
+}
+
+func newPrettyHandler(out io.Writer, opts *slog.HandlerOptions) *prettyHandler {
+	return &prettyHandler{out: out, mu: &sync.Mutex{}, opts: *opts}
+}
+
+func (h *prettyHandler) Enabled(_ context.Context, level slog.Level) bool {
+	minLevel := slog.LevelInfo
+	if h.opts.Level != nil {
+		minLevel = h.opts.Level.Level()
+	}
+	return level >= minLevel
+}
+
+func (h *prettyHandler) Handle(_ context.Context, record slog.Record) error {
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "%s %s%-5s%s %s",
+		record.Time.Format("15:04:05.000"),
+		levelColors[record.Level], record.Level.String(), colorReset,
+		record.Message,
+	)
+
+	for _, attr := range h.attrs {
+		h.writeAttr(&buf, nil, attr)
+	}
+	record.Attrs(func(attr slog.Attr) bool {
+		h.writeAttr(&buf, h.groups, attr)
+		return true
+	})
+
+	if h.opts.AddSource && record.PC != 0 {
+		frame, _ := runtime.CallersFrames([]uintptr{record.PC}).Next()
+		fmt.Fprintf(&buf, " %s(%s:%d)%s", levelColors[slog.LevelDebug], filepath.Base(frame.File), frame.Line, colorReset)
+	}
+	buf.WriteByte('\n')
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	_, err := h.out.Write(buf.Bytes())
+	return err
+}
+
+func (h *prettyHandler) writeAttr(buf *bytes.Buffer, groups []string, attr slog.Attr) {
+	attr.Value = attr.Value.Resolve()
+	if attr.Value.Kind() == slog.KindGroup {
+		nested := groups
+		if attr.Key != "" {
+			nested = append(append([]string(nil), groups...), attr.Key)
+		}
+		for _, a := range attr.Value.Group() {
+			h.writeAttr(buf, nested, a)
+		}
+		return
+	}
+	if h.opts.ReplaceAttr != nil {
+		attr = h.opts.ReplaceAttr(groups, attr)
+	}
+	if attr.Key == "" {
+		return
+	}
+
+	key := strings.Join(append(append([]string(nil), groups...), attr.Key), ".")
+	value := attr.Value.String()
+	if attr.Value.Kind() == slog.KindTime {
+		value = attr.Value.Time().Format(time.RFC3339)
+	}
+	fmt.Fprintf(buf, " %s=%s", key, value)
+}
+
+func (h *prettyHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	clone := *h
+	clone.attrs = append([]slog.Attr(nil), h.attrs...)
+	for _, attr := range attrs {
+		if len(h.groups) > 0 {
+			attr = slog.Group(strings.Join(h.groups, "."), attr)
+		}
+		clone.attrs = append(clone.attrs, attr)
+	}
+	return &clone
+}
+
+func (h *prettyHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	clone := *h
+	clone.groups = append(append([]string(nil), h.groups...), name)
+	return &clone
+}
@@ -0,0 +1,73 @@
+package logger
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// samplingHandler ограничивает повторяющиеся debug-записи: за период для
+// каждого сообщения пишутся первые first, затем каждая thereafter-я
+// (thereafter <= 0 - остальные отбрасываются). Записи уровня info и выше
+// проходят всегда
+type samplingHandler struct {
+	slog.Handler
+	state *samplingState
+}
+
+type samplingState struct {
+	mu          sync.Mutex
+	first       int
+	thereafter  int
+	period      time.Duration
+	windowStart time.Time
+	counts      map[string]int
+}
+
+func newSamplingHandler(next slog.Handler, first, thereafter int, period time.Duration) slog.Handler {
+	if period <= 0 {
+		period = time.Second
+	}
+	return samplingHandler{
+		Handler: next,
+		state: &samplingState{
+			first:      first,
+			thereafter: thereafter,
+			period:     period,
+			counts:     make(map[string]int),
+		},
+	}
+}
+
+func (h samplingHandler) Handle(ctx context.Context, record slog.Record) error {
+	if record.Level >= slog.LevelInfo || h.state.allow(record.Message, record.Time) {
+		return h.Handler.Handle(ctx, record)
+	}
+	return nil
+}
+
+func (h samplingHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return samplingHandler{Handler: h.Handler.WithAttrs(attrs), state: h.state}
+}
+
+func (h samplingHandler) WithGroup(name string) slog.Handler {
+	return samplingHandler{Handler: h.Handler.WithGroup(name), state: h.state}
+}
+
+func (s *samplingState) allow(msg string, now time.Time) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if now.Sub(s.windowStart) >= s.period {
+		s.windowStart = now
+		clear(s.counts)
+	}
+
+	s.counts[msg]++
+	n := s.counts[msg]
+	if n <= s.first {
+		return true
+	}
+	return s.thereafter > 0 && (n-s.first)%s.thereafter == 0
+}
@@ -0,0 +1,112 @@
+package metrics
+
+import (
+	"database/sql"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "quotes"
+
+// Registry хранит метрики сервиса и отдает их в текстовом формате Prometheus.
+// Реализует domain.QuoteMetrics
+type Registry struct {
+	registry *prometheus.Registry
+
+	requests *prometheus.CounterVec
+	duration *prometheus.HistogramVec
+
+	created  prometheus.Counter
+	deleted  prometheus.Counter
+	random   prometheus.Counter
+	notFound *prometheus.CounterVec
+}
+
+func NewRegistry() *Registry {
+	r := &Registry{
+		registry: prometheus.NewRegistry(),
+		requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "http",
+			Name:      "requests_total",
+			Help:      "HTTP requests by method, route template and status.",
+		}, []string{"method", "route", "status"}),
+		duration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Subsystem: "http",
+			Name:      "request_duration_seconds",
+			Help:      "HTTP request latency by method, route template and status.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"method", "route", "status"}),
+		created: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "created_total",
+			Help:      "Quotes created.",
+		}),
+		deleted: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "deleted_total",
+			Help:      "Quotes deleted.",
+		}),
+		random: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "random_served_total",
+			Help:      "Random quotes served.",
+		}),
+		notFound: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "not_found_total",
+			Help:      "Operations on quotes that do not exist.",
+		}, []string{"operation"}),
+	}
+
+	r.registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		r.requests, r.duration,
+		r.created, r.deleted, r.random, r.notFound,
+	)
+	return r
+}
+
+// RegisterDBPools экспортирует sql.DB.Stats() каждого пула с меткой db_name
+func (r *Registry) RegisterDBPools(pools map[string]*sql.DB) error {
+	for name, db := range pools {
+		if err := r.registry.Register(collectors.NewDBStatsCollector(db, name)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *Registry) ObserveRequest(method, route string, status int, duration time.Duration) {
+	code := strconv.Itoa(status)
+	r.requests.WithLabelValues(method, route, code).Inc()
+	r.duration.WithLabelValues(method, route, code).Observe(duration.Seconds())
+}
+
+func (r *Registry) QuoteCreated() {
+	r.created.Inc()
+}
+
+func (r *Registry) QuoteDeleted() {
+	r.deleted.Inc()
+}
+
+func (r *Registry) RandomQuoteServed() {
+	r.random.Inc()
+}
+
+func (r *Registry) QuoteNotFound(operation string) {
+	r.notFound.WithLabelValues(operation).Inc()
+}
+
+// Handler отдает метрики в текстовом формате Prometheus
+func (r *Registry) Handler() http.Handler {
+	return promhttp.HandlerFor(r.registry, promhttp.HandlerOpts{})
+}
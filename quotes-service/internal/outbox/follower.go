@@ -0,0 +1,112 @@
+package outbox
+
+import (
+	"context"
+	"time"
+
+	"quotes-service/internal/domain"
+	"quotes-service/internal/infrastructure/logger"
+)
+
+type FollowerConfig struct {
+	Interval  time.Duration
+	BatchSize int
+	// GapTimeout - сколько ждать пропущенный номер события: его транзакция
+	// могла еще не закоммититься или откатиться
+	GapTimeout time.Duration
+}
+
+// Follower читает outbox на каждом инстансе независимо от релея и передает
+// все закоммиченные события локальным получателям (SSE, WebSocket). Релей
+// доставляет событие один раз на весь кластер, а потоковым клиентам нужны
+// изменения, сделанные через любой инстанс. Доставка best-effort, с момента
+// запуска: история до старта не повторяется.
+type Follower struct {
+	repo      domain.OutboxRepository
+	listeners []domain.EventListener
+	config    FollowerConfig
+	logger    *logger.Logger
+
+	started  bool
+	cursor   int64
+	gapSince time.Time
+}
+
+func NewFollower(repo domain.OutboxRepository, listeners []domain.EventListener, config FollowerConfig, logger *logger.Logger) *Follower {
+	if config.Interval <= 0 {
+		config.Interval = 500 * time.Millisecond
+	}
+	if config.BatchSize <= 0 {
+		config.BatchSize = 100
+	}
+	if config.GapTimeout <= 0 {
+		config.GapTimeout = 10 * time.Second
+	}
+
+	return &Follower{
+		repo:      repo,
+		listeners: listeners,
+		config:    config,
+		logger:    logger,
+	}
+}
+
+// Run передает события до отмены контекста
+func (f *Follower) Run(ctx context.Context) {
+	ticker := time.NewTicker(f.config.Interval)
+	defer ticker.Stop()
+
+	for {
+		if err := f.RunOnce(ctx); err != nil && ctx.Err() == nil {
+			f.logger.Error("Outbox follower failed", "error", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (f *Follower) RunOnce(ctx context.Context) error {
+	if !f.started {
+		cursor, err := f.repo.LastEventID(ctx)
+		if err != nil {
+			return err
+		}
+		f.cursor, f.started = cursor, true
+	}
+
+	for {
+		events, err := f.repo.ListAfter(ctx, f.cursor, f.config.BatchSize)
+		if err != nil {
+			return err
+		}
+
+		for _, event := range events {
+			if event.ID != f.cursor+1 && !f.gapExpired() {
+				return nil
+			}
+			f.gapSince = time.Time{}
+			f.cursor = event.ID
+
+			for _, listener := range f.listeners {
+				listener.OnEvent(event)
+			}
+		}
+
+		if len(events) < f.config.BatchSize {
+			return nil
+		}
+	}
+}
+
+// gapExpired: номера событий идут подряд, поэтому пропуск означает
+// незакоммиченную или откатившуюся транзакцию. Пропуск ждет не дольше GapTimeout
+func (f *Follower) gapExpired() bool {
+	if f.gapSince.IsZero() {
+		f.gapSince = time.Now()
+	}
+	return time.Since(f.gapSince) >= f.config.GapTimeout
+}
@@ -0,0 +1,30 @@
+package outbox
+
+import (
+	"context"
+
+	"quotes-service/internal/domain"
+	"quotes-service/internal/infrastructure/logger"
+)
+
+type LogSink struct {
+	logger *logger.Logger
+}
+
+func NewLogSink(logger *logger.Logger) *LogSink {
+	return &LogSink{logger: logger}
+}
+
+func (s *LogSink) Name() string {
+	return "log"
+}
+
+func (s *LogSink) Publish(ctx context.Context, event *domain.Event) error {
+	s.logger.Info("Domain event",
+		"event_id", event.ID,
+		"type", event.Type,
+		"quote_id", event.QuoteID,
+		"occurred_at", event.OccurredAt,
+	)
+	return nil
+}
@@ -0,0 +1,181 @@
+package outbox
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"quotes-service/internal/domain"
+)
+
+// NATSSink публикует события в NATS-совместимый брокер по текстовому
+// протоколу. Каждая публикация подтверждается PING/PONG, так что
+// ошибка доставки до сервера возвращается вызывающему и событие повторяется.
+type NATSSink struct {
+	address       string
+	subjectPrefix string
+	timeout       time.Duration
+	connectOpts   map[string]interface{}
+
+	mu     sync.Mutex
+	conn   net.Conn
+	reader *bufio.Reader
+}
+
+func NewNATSSink(rawURL, subjectPrefix string, timeout time.Duration) (*NATSSink, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid NATS URL: %w", err)
+	}
+	if u.Host == "" {
+		return nil, fmt.Errorf("invalid NATS URL: missing host")
+	}
+
+	address := u.Host
+	if u.Port() == "" {
+		address = net.JoinHostPort(u.Hostname(), "4222")
+	}
+
+	opts := map[string]interface{}{
+		"verbose":  false,
+		"pedantic": false,
+		"name":     "quotes-service",
+	}
+	if u.User != nil {
+		opts["user"] = u.User.Username()
+		if password, ok := u.User.Password(); ok {
+			opts["pass"] = password
+		}
+	}
+
+	return &NATSSink{
+		address:       address,
+		subjectPrefix: strings.TrimSuffix(subjectPrefix, "."),
+		timeout:       timeout,
+		connectOpts:   opts,
+	}, nil
+}
+
+func (s *NATSSink) Name() string {
+	return "nats"
+}
+
+func (s *NATSSink) Publish(ctx context.Context, event *domain.Event) error {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to encode event: %w", err)
+	}
+
+	subject := string(event.Type)
+	if s.subjectPrefix != "" {
+		subject = s.subjectPrefix + "." + subject
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.publishLocked(ctx, subject, payload); err != nil {
+		s.closeLocked()
+		return err
+	}
+
+	return nil
+}
+
+func (s *NATSSink) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.closeLocked()
+}
+
+func (s *NATSSink) publishLocked(ctx context.Context, subject string, payload []byte) error {
+	if s.conn == nil {
+		if err := s.connectLocked(ctx); err != nil {
+			return err
+		}
+	}
+
+	s.conn.SetDeadline(s.deadline(ctx))
+
+	msg := fmt.Sprintf("PUB %s %d\r\n%s\r\nPING\r\n", subject, len(payload), payload)
+	if _, err := s.conn.Write([]byte(msg)); err != nil {
+		return fmt.Errorf("failed to publish: %w", err)
+	}
+
+	return s.awaitPongLocked()
+}
+
+func (s *NATSSink) connectLocked(ctx context.Context) error {
+	dialer := net.Dialer{Timeout: s.timeout}
+	conn, err := dialer.DialContext(ctx, "tcp", s.address)
+	if err != nil {
+		return fmt.Errorf("failed to connect to NATS: %w", err)
+	}
+
+	s.conn = conn
+	s.reader = bufio.NewReader(conn)
+	conn.SetDeadline(s.deadline(ctx))
+
+	line, err := s.reader.ReadString('\n')
+	if err != nil {
+		return fmt.Errorf("failed to read NATS INFO: %w", err)
+	}
+	if !strings.HasPrefix(line, "INFO ") {
+		return fmt.Errorf("unexpected NATS greeting: %q", strings.TrimSpace(line))
+	}
+
+	opts, err := json.Marshal(s.connectOpts)
+	if err != nil {
+		return fmt.Errorf("failed to encode CONNECT options: %w", err)
+	}
+	if _, err := fmt.Fprintf(conn, "CONNECT %s\r\nPING\r\n", opts); err != nil {
+		return fmt.Errorf("failed to send CONNECT: %w", err)
+	}
+
+	return s.awaitPongLocked()
+}
+
+func (s *NATSSink) awaitPongLocked() error {
+	for {
+		line, err := s.reader.ReadString('\n')
+		if err != nil {
+			return fmt.Errorf("failed to read NATS reply: %w", err)
+		}
+
+		switch line = strings.TrimSpace(line); {
+		case line == "PONG":
+			return nil
+		case line == "PING":
+			if _, err := s.conn.Write([]byte("PONG\r\n")); err != nil {
+				return fmt.Errorf("failed to answer PING: %w", err)
+			}
+		case strings.HasPrefix(line, "-ERR"):
+			return fmt.Errorf("NATS error: %s", strings.TrimSpace(strings.TrimPrefix(line, "-ERR")))
+		}
+	}
+}
+
+func (s *NATSSink) deadline(ctx context.Context) time.Time {
+	deadline := time.Now().Add(s.timeout)
+	if ctxDeadline, ok := ctx.Deadline(); ok && ctxDeadline.Before(deadline) {
+		return ctxDeadline
+	}
+	return deadline
+}
+
+func (s *NATSSink) closeLocked() error {
+	if s.conn == nil {
+		return nil
+	}
+	err := s.conn.Close()
+	s.conn = nil
+	s.reader = nil
+	return err
+}
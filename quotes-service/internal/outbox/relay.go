@@ -0,0 +1,137 @@
+package outbox
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"quotes-service/internal/domain"
+	"quotes-service/internal/infrastructure/logger"
+)
+
+// Sink - получатель событий. Доставка at-least-once: при ошибке любого
+// sink событие повторяется для всех, поэтому получатели должны
+// дедуплицировать по Event.ID.
+type Sink interface {
+	Name() string
+	Publish(ctx context.Context, event *domain.Event) error
+}
+
+type RelayConfig struct {
+	Interval   time.Duration
+	BatchSize  int
+	MinBackoff time.Duration
+	MaxBackoff time.Duration
+	// Lease - срок аренды пачки событий на время публикации
+	Lease time.Duration
+}
+
+type Relay struct {
+	repo   domain.OutboxRepository
+	sinks  []Sink
+	config RelayConfig
+	logger *logger.Logger
+}
+
+func NewRelay(repo domain.OutboxRepository, sinks []Sink, config RelayConfig, logger *logger.Logger) *Relay {
+	if config.Interval <= 0 {
+		config.Interval = time.Second
+	}
+	if config.BatchSize <= 0 {
+		config.BatchSize = 100
+	}
+	if config.MinBackoff <= 0 {
+		config.MinBackoff = time.Second
+	}
+	if config.MaxBackoff < config.MinBackoff {
+		config.MaxBackoff = config.MinBackoff
+	}
+	if config.Lease <= 0 {
+		config.Lease = time.Minute
+	}
+
+	return &Relay{
+		repo:   repo,
+		sinks:  sinks,
+		config: config,
+		logger: logger,
+	}
+}
+
+// Run публикует события до отмены контекста
+func (r *Relay) Run(ctx context.Context) {
+	ticker := time.NewTicker(r.config.Interval)
+	defer ticker.Stop()
+
+	for {
+		if err := r.RunOnce(ctx); err != nil && ctx.Err() == nil {
+			r.logger.Error("Outbox relay batch failed", "error", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (r *Relay) RunOnce(ctx context.Context) error {
+	return r.repo.ProcessPending(ctx, r.config.BatchSize, r.config.Lease, func(ctx context.Context, events []*domain.Event) []domain.EventResult {
+		return r.publish(ctx, events)
+	})
+}
+
+func (r *Relay) publish(ctx context.Context, events []*domain.Event) []domain.EventResult {
+	results := make([]domain.EventResult, 0, len(events))
+	// Цитаты, чье событие не доставлено: их следующие события ждут повтора
+	blocked := make(map[int]bool)
+
+	for _, event := range events {
+		if blocked[event.QuoteID] {
+			continue
+		}
+
+		if err := r.publishToSinks(ctx, event); err != nil {
+			blocked[event.QuoteID] = true
+			retryAt := time.Now().Add(Backoff(event.Attempts, r.config.MinBackoff, r.config.MaxBackoff))
+			r.logger.Warn("Outbox event delivery failed",
+				"event_id", event.ID,
+				"type", event.Type,
+				"quote_id", event.QuoteID,
+				"attempts", event.Attempts+1,
+				"retry_at", retryAt,
+				"error", err,
+			)
+			results = append(results, domain.EventResult{EventID: event.ID, Err: err, RetryAt: retryAt})
+			continue
+		}
+
+		results = append(results, domain.EventResult{EventID: event.ID})
+	}
+
+	return results
+}
+
+func (r *Relay) publishToSinks(ctx context.Context, event *domain.Event) error {
+	var errs []error
+	for _, sink := range r.sinks {
+		if err := sink.Publish(ctx, event); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", sink.Name(), err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Backoff - экспоненциальная задержка перед повтором номер attempts+1
+func Backoff(attempts int, min, max time.Duration) time.Duration {
+	delay := min
+	for i := 0; i < attempts && delay < max; i++ {
+		delay *= 2
+	}
+	if delay > max {
+		delay = max
+	}
+	return delay
+}
@@ -0,0 +1,209 @@
+package ratelimit
+
+import (
+	"container/list"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Rule - лимит маршрута: Requests запросов за Period на клиента. Path -
+// шаблон маршрута mux без префикса версии, например /quotes/{id}
+type Rule struct {
+	Method   string
+	Path     string
+	Requests int
+	Period   time.Duration
+}
+
+func (r Rule) String() string {
+	return fmt.Sprintf("%s %s=%d/%s", r.Method, r.Path, r.Requests, r.Period)
+}
+
+// Config: MaxClients ограничивает число корзин в памяти
+type Config struct {
+	Rules      []Rule
+	MaxClients int
+}
+
+// ParseRules разбирает правила вида "GET /quotes/random=60/1m", через запятую
+func ParseRules(spec string) ([]Rule, error) {
+	var rules []Rule
+	for _, item := range strings.Split(spec, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+
+		route, limit, ok := strings.Cut(item, "=")
+		method, path, okRoute := strings.Cut(strings.TrimSpace(route), " ")
+		requests, period, okLimit := strings.Cut(strings.TrimSpace(limit), "/")
+		if !ok || !okRoute || !okLimit {
+			return nil, fmt.Errorf("invalid rate limit rule %q: want METHOD PATH=REQUESTS/PERIOD", item)
+		}
+
+		rule := Rule{Method: strings.ToUpper(method), Path: strings.TrimSpace(path)}
+		if err := rule.parseLimit(requests, period); err != nil {
+			return nil, fmt.Errorf("invalid %s in rule %q", err, item)
+		}
+		rules = append(rules, rule)
+	}
+	return rules, nil
+}
+
+// ParseLimit разбирает лимит без маршрута вида "20/1m"; "0" или пустая
+// строка - лимита нет (Requests == 0)
+func ParseLimit(spec string) (Rule, error) {
+	var rule Rule
+	spec = strings.TrimSpace(spec)
+	if spec == "" || spec == "0" {
+		return rule, nil
+	}
+	requests, period, ok := strings.Cut(spec, "/")
+	if !ok {
+		return rule, fmt.Errorf("invalid limit %q: want REQUESTS/PERIOD", spec)
+	}
+	if err := rule.parseLimit(requests, period); err != nil {
+		return rule, fmt.Errorf("invalid %s in limit %q", err, spec)
+	}
+	return rule, nil
+}
+
+// parseLimit заполняет Requests и Period; ошибка называет неверную часть
+func (r *Rule) parseLimit(requests, period string) error {
+	var err error
+	if r.Requests, err = strconv.Atoi(requests); err != nil || r.Requests <= 0 {
+		return errors.New("request count")
+	}
+	// Допускается сокращение "60/m" вместо "60/1m"
+	if period != "" && !strings.ContainsAny(period[:1], "0123456789") {
+		period = "1" + period
+	}
+	if r.Period, err = time.ParseDuration(period); err != nil || r.Period <= 0 {
+		return errors.New("period")
+	}
+	return nil
+}
+
+// Decision - итог проверки лимита для заголовков RateLimit-*
+type Decision struct {
+	Allowed   bool
+	Limit     int
+	Remaining int
+	// Reset - через сколько корзина наполнится полностью
+	Reset time.Duration
+	// RetryAfter - через сколько появится следующий запрос, если Allowed = false
+	RetryAfter time.Duration
+}
+
+// Limiter - token bucket на пару (клиент, правило). Корзины хранятся в LRU:
+// при превышении MaxClients вытесняются давно не обращавшиеся клиенты,
+// а полностью наполнившиеся корзины удаляются раньше, так как не отличаются от новых
+type Limiter struct {
+	mu         sync.Mutex
+	maxClients int
+	buckets    map[string]*list.Element
+	order      *list.List
+}
+
+type bucket struct {
+	key    string
+	tokens float64
+	last   time.Time
+	// full - момент, когда корзина наполнится без новых запросов
+	full time.Time
+}
+
+func NewLimiter(maxClients int) *Limiter {
+	if maxClients <= 0 {
+		maxClients = 1
+	}
+	return &Limiter{
+		maxClients: maxClients,
+		buckets:    make(map[string]*list.Element),
+		order:      list.New(),
+	}
+}
+
+// Allow списывает один запрос клиента client по правилу rule
+func (l *Limiter) Allow(client string, rule Rule) Decision {
+	return l.take(client, rule, true)
+}
+
+// Check сообщает, остался ли у клиента запрос по правилу, не списывая его.
+// Корзина для нового клиента не создается
+func (l *Limiter) Check(client string, rule Rule) Decision {
+	return l.take(client, rule, false)
+}
+
+func (l *Limiter) take(client string, rule Rule, consume bool) Decision {
+	now := time.Now()
+	key := rule.String() + "|" + client
+	rate := float64(rule.Requests) / rule.Period.Seconds()
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.evictFull(now)
+
+	var b *bucket
+	if elem, ok := l.buckets[key]; ok {
+		b = elem.Value.(*bucket)
+		b.tokens = math.Min(float64(rule.Requests), b.tokens+now.Sub(b.last).Seconds()*rate)
+		l.order.MoveToFront(elem)
+	} else if consume {
+		b = &bucket{key: key, tokens: float64(rule.Requests)}
+		l.buckets[key] = l.order.PushFront(b)
+		for l.order.Len() > l.maxClients {
+			l.remove(l.order.Back())
+		}
+	} else {
+		return Decision{Allowed: true, Limit: rule.Requests, Remaining: rule.Requests}
+	}
+	b.last = now
+
+	decision := Decision{Limit: rule.Requests}
+	if b.tokens >= 1 {
+		if consume {
+			b.tokens--
+		}
+		decision.Allowed = true
+	} else {
+		decision.RetryAfter = seconds((1 - b.tokens) / rate)
+	}
+	decision.Remaining = int(b.tokens)
+	decision.Reset = seconds((float64(rule.Requests) - b.tokens) / rate)
+	b.full = now.Add(decision.Reset)
+
+	return decision
+}
+
+// Len - число корзин в памяти
+func (l *Limiter) Len() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return l.order.Len()
+}
+
+func (l *Limiter) evictFull(now time.Time) {
+	for elem := l.order.Back(); elem != nil; elem = l.order.Back() {
+		if now.Before(elem.Value.(*bucket).full) {
+			return
+		}
+		l.remove(elem)
+	}
+}
+
+func (l *Limiter) remove(elem *list.Element) {
+	l.order.Remove(elem)
+	delete(l.buckets, elem.Value.(*bucket).key)
+}
+
+func seconds(s float64) time.Duration {
+	return time.Duration(s * float64(time.Second))
+}
@@ -0,0 +1,269 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	"quotes-service/internal/domain"
+	"quotes-service/internal/infrastructure/database"
+	"quotes-service/internal/infrastructure/logger"
+)
+
+// Списки и счетчики кэшируются под текущим поколением, записи цитат - под
+// поколением своей цитаты; Invalidate меняет поколение, и старые ключи просто
+// вытесняются по LRU/TTL. Запись через обертку сразу сбрасывает кэш, а сервис
+// сбрасывает его еще раз после коммита транзакции. Чтения внутри транзакции
+// идут мимо кэша.
+const generationKey = "quotes:generation"
+
+type quoteRepository struct {
+	next  domain.QuoteRepository
+	store Store
+	ttl   time.Duration
+	// loadTimeout ограничивает общую загрузку, отвязанную от отмены
+	// контекстов вызывающих
+	loadTimeout time.Duration
+	logger      *logger.Logger
+	group       flightGroup
+
+	hits      atomic.Uint64
+	misses    atomic.Uint64
+	coalesced atomic.Uint64
+	versions  atomic.Uint64
+}
+
+func NewQuoteRepository(next domain.QuoteRepository, store Store, ttl, loadTimeout time.Duration, logger *logger.Logger) domain.QuoteRepository {
+	return &quoteRepository{
+		next:        next,
+		store:       store,
+		ttl:         ttl,
+		loadTimeout: loadTimeout,
+		logger:      logger,
+	}
+}
+
+func (r *quoteRepository) Create(ctx context.Context, quote *domain.Quote) (*domain.Quote, error) {
+	created, err := r.next.Create(ctx, quote)
+	if err == nil {
+		r.invalidateLists(ctx)
+	}
+	return created, err
+}
+
+func (r *quoteRepository) GetAll(ctx context.Context, filter domain.QuoteFilter) ([]*domain.Quote, error) {
+	if bypass(ctx) {
+		return r.next.GetAll(ctx, filter)
+	}
+
+	key := fmt.Sprintf("quotes:%s:list:%q:%q:%q:%d:%d", r.generation(ctx), filter.Author, filter.Tag, filter.CreatedBy, filter.Limit, filter.Offset)
+
+	var quotes []*domain.Quote
+	err := r.load(ctx, key, &quotes, func(ctx context.Context) (interface{}, error) {
+		return r.next.GetAll(ctx, filter)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return quotes, nil
+}
+
+func (r *quoteRepository) GetByID(ctx context.Context, id int) (*domain.Quote, error) {
+	if bypass(ctx) {
+		return r.next.GetByID(ctx, id)
+	}
+
+	var quote domain.Quote
+	err := r.load(ctx, r.quoteKey(id), &quote, func(ctx context.Context) (interface{}, error) {
+		return r.next.GetByID(ctx, id)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &quote, nil
+}
+
+func (r *quoteRepository) GetRandom(ctx context.Context) (*domain.Quote, error) {
+	return r.next.GetRandom(ctx)
+}
+
+func (r *quoteRepository) Update(ctx context.Context, quote *domain.Quote) (*domain.Quote, error) {
+	updated, err := r.next.Update(ctx, quote)
+	if err == nil {
+		r.Invalidate(ctx, quote.ID)
+	}
+	return updated, err
+}
+
+func (r *quoteRepository) Delete(ctx context.Context, id int) error {
+	err := r.next.Delete(ctx, id)
+	if err == nil {
+		r.Invalidate(ctx, id)
+	}
+	return err
+}
+
+func (r *quoteRepository) Count(ctx context.Context, filter domain.QuoteFilter) (int, error) {
+	if bypass(ctx) {
+		return r.next.Count(ctx, filter)
+	}
+
+	key := fmt.Sprintf("quotes:%s:count:%q:%q:%q", r.generation(ctx), filter.Author, filter.Tag, filter.CreatedBy)
+
+	var count int
+	err := r.load(ctx, key, &count, func(ctx context.Context) (interface{}, error) {
+		return r.next.Count(ctx, filter)
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return count, nil
+}
+
+func (r *quoteRepository) ListAuthors(ctx context.Context, filter domain.AuthorFilter) ([]*domain.Author, error) {
+	if bypass(ctx) {
+		return r.next.ListAuthors(ctx, filter)
+	}
+
+	key := fmt.Sprintf("quotes:%s:authors:%q:%d:%d", r.generation(ctx), filter.Names, filter.Limit, filter.Offset)
+
+	var authors []*domain.Author
+	err := r.load(ctx, key, &authors, func(ctx context.Context) (interface{}, error) {
+		return r.next.ListAuthors(ctx, filter)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return authors, nil
+}
+
+func (r *quoteRepository) GetByAuthors(ctx context.Context, authors []string, limit int) ([]*domain.Quote, error) {
+	if bypass(ctx) {
+		return r.next.GetByAuthors(ctx, authors, limit)
+	}
+
+	key := fmt.Sprintf("quotes:%s:by-authors:%q:%d", r.generation(ctx), authors, limit)
+
+	var quotes []*domain.Quote
+	err := r.load(ctx, key, &quotes, func(ctx context.Context) (interface{}, error) {
+		return r.next.GetByAuthors(ctx, authors, limit)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return quotes, nil
+}
+
+func (r *quoteRepository) HealthCheck(ctx context.Context) error {
+	return r.next.HealthCheck(ctx)
+}
+
+// Invalidate сбрасывает списки, счетчики и записи цитат ids
+func (r *quoteRepository) Invalidate(ctx context.Context, ids ...int) {
+	for _, id := range ids {
+		r.store.Delete(r.quoteKey(id))
+		r.bump(quoteGenerationKey(id))
+	}
+	r.invalidateLists(ctx)
+}
+
+func (r *quoteRepository) CacheStats() domain.CacheStats {
+	stats := domain.CacheStats{
+		Hits:      r.hits.Load(),
+		Misses:    r.misses.Load(),
+		Coalesced: r.coalesced.Load(),
+		Entries:   r.store.Len(),
+	}
+	if total := stats.Hits + stats.Misses; total > 0 {
+		stats.HitRatio = float64(stats.Hits) / float64(total)
+	}
+	return stats
+}
+
+// load отдает значение из кэша или загружает его через fetch,
+// объединяя одновременные промахи по одному ключу. Общая загрузка идет
+// на контексте без отмены, чтобы уход одного клиента не ронял остальных.
+func (r *quoteRepository) load(ctx context.Context, key string, dest interface{}, fetch func(context.Context) (interface{}, error)) error {
+	if data, ok := r.store.Get(key); ok {
+		if err := json.Unmarshal(data, dest); err == nil {
+			r.hits.Add(1)
+			return nil
+		}
+		r.store.Delete(key)
+	}
+
+	r.misses.Add(1)
+
+	data, err, shared := r.group.Do(ctx, key, func() ([]byte, error) {
+		loadCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), r.loadTimeout)
+		defer cancel()
+
+		value, err := fetch(loadCtx)
+		if err != nil {
+			return nil, err
+		}
+
+		data, err := json.Marshal(value)
+		if err != nil {
+			return nil, fmt.Errorf("failed to encode cache entry: %w", err)
+		}
+
+		r.store.Set(key, data, r.ttl)
+		return data, nil
+	})
+	if shared {
+		r.coalesced.Add(1)
+	}
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal(data, dest)
+}
+
+func (r *quoteRepository) generation(ctx context.Context) string {
+	return r.version(generationKey)
+}
+
+func (r *quoteRepository) invalidateLists(ctx context.Context) {
+	r.bump(generationKey)
+	r.logger.DebugContext(ctx, "Quote cache invalidated")
+}
+
+// quoteKey включает поколение цитаты: промах, загруженный до Invalidate,
+// сохранится под старым ключом и не вернет устаревшую строку
+func (r *quoteRepository) quoteKey(id int) string {
+	return "quote:" + strconv.Itoa(id) + ":" + r.version(quoteGenerationKey(id))
+}
+
+// version возвращает текущее поколение под key, создавая его при отсутствии
+func (r *quoteRepository) version(key string) string {
+	if data, ok := r.store.Get(key); ok {
+		return string(data)
+	}
+	return r.bump(key)
+}
+
+func (r *quoteRepository) bump(key string) string {
+	version := strconv.FormatInt(time.Now().UnixNano(), 36) + "." + strconv.FormatUint(r.versions.Add(1), 36)
+	r.store.Set(key, []byte(version), 0)
+	return version
+}
+
+func quoteGenerationKey(id int) string {
+	return "quote:" + strconv.Itoa(id) + ":generation"
+}
+
+// bypass: чтения для read-your-writes и внутри транзакции (проверка
+// владельца перед изменением) должны видеть актуальную строку
+func bypass(ctx context.Context) bool {
+	return database.PrimaryRequested(ctx) || database.InTransaction(ctx)
+}
@@ -0,0 +1,62 @@
+package cache
+
+import (
+	"context"
+	"sync"
+)
+
+// flightGroup объединяет одновременные промахи по одному ключу
+// в единственный запрос к репозиторию.
+type flightGroup struct {
+	mu    sync.Mutex
+	calls map[string]*flightCall
+}
+
+type flightCall struct {
+	done      chan struct{}
+	value     []byte
+	err       error
+	recovered interface{}
+}
+
+// Do запускает fn один раз на ключ в отдельной горутине. Отмена ctx
+// одного из вызывающих не прерывает загрузку для остальных: он просто
+// перестает ждать. Паника в fn повторяется у всех дождавшихся.
+func (g *flightGroup) Do(ctx context.Context, key string, fn func() ([]byte, error)) ([]byte, error, bool) {
+	g.mu.Lock()
+	if g.calls == nil {
+		g.calls = make(map[string]*flightCall)
+	}
+	call, shared := g.calls[key]
+	if !shared {
+		call = &flightCall{done: make(chan struct{})}
+		g.calls[key] = call
+		go g.run(key, call, fn)
+	}
+	g.mu.Unlock()
+
+	select {
+	case <-call.done:
+	case <-ctx.Done():
+		return nil, ctx.Err(), shared
+	}
+
+	if call.recovered != nil {
+		panic(call.recovered)
+	}
+	return call.value, call.err, shared
+}
+
+func (g *flightGroup) run(key string, call *flightCall, fn func() ([]byte, error)) {
+	defer func() {
+		call.recovered = recover()
+
+		g.mu.Lock()
+		delete(g.calls, key)
+		g.mu.Unlock()
+
+		close(call.done)
+	}()
+
+	call.value, call.err = fn()
+}
@@ -0,0 +1,152 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"quotes-service/internal/domain"
+	"quotes-service/internal/infrastructure/database"
+	"quotes-service/internal/infrastructure/logger"
+
+	"github.com/lib/pq"
+)
+
+const apiKeyColumns = "id, name, prefix, key_hash, scopes, expires_at, last_used_at, revoked_at, created_at"
+
+type apiKeyRepository struct {
+	cluster *database.Cluster
+	logger  *logger.Logger
+}
+
+func NewAPIKeyRepository(cluster *database.Cluster, logger *logger.Logger) domain.APIKeyRepository {
+	return &apiKeyRepository{
+		cluster: cluster,
+		logger:  logger,
+	}
+}
+
+func (r *apiKeyRepository) Create(ctx context.Context, key *domain.APIKey) (*domain.APIKey, error) {
+	query := `
+		INSERT INTO api_keys (name, prefix, key_hash, scopes, expires_at, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING ` + apiKeyColumns
+
+	result, err := scanAPIKey(writer(ctx, r.cluster).QueryRowContext(ctx, query,
+		key.Name, key.Prefix, key.Hash, pq.Array(scopesToStrings(key.Scopes)), key.ExpiresAt, time.Now(),
+	))
+	if err != nil {
+		r.logger.ErrorContext(ctx, "Failed to create api key", "error", err, "name", key.Name)
+		return nil, fmt.Errorf("failed to create api key: %w", err)
+	}
+
+	r.logger.InfoContext(ctx, "API key created", "id", result.ID, "name", result.Name, "scopes", key.Scopes)
+	return result, nil
+}
+
+// GetByPrefix читает из primary, чтобы отзыв ключа действовал сразу
+func (r *apiKeyRepository) GetByPrefix(ctx context.Context, prefix string) (*domain.APIKey, error) {
+	query := "SELECT " + apiKeyColumns + " FROM api_keys WHERE prefix = $1"
+
+	key, err := scanAPIKey(r.cluster.Primary().QueryRowContext(ctx, query, prefix))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, domain.ErrAPIKeyNotFound
+		}
+		return nil, fmt.Errorf("failed to get api key: %w", err)
+	}
+
+	return key, nil
+}
+
+func (r *apiKeyRepository) List(ctx context.Context) ([]*domain.APIKey, error) {
+	query := "SELECT " + apiKeyColumns + " FROM api_keys ORDER BY id"
+
+	rows, err := r.cluster.Primary().QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list api keys: %w", err)
+	}
+	defer rows.Close()
+
+	var keys []*domain.APIKey
+	for rows.Next() {
+		key, err := scanAPIKey(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan api key: %w", err)
+		}
+		keys = append(keys, key)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating over api keys: %w", err)
+	}
+
+	return keys, nil
+}
+
+// Revoke идемпотентен: повторный отзыв сохраняет исходное время
+func (r *apiKeyRepository) Revoke(ctx context.Context, id int, at time.Time) (*domain.APIKey, error) {
+	query := `
+		UPDATE api_keys SET revoked_at = COALESCE(revoked_at, $1)
+		WHERE id = $2
+		RETURNING ` + apiKeyColumns
+
+	key, err := scanAPIKey(writer(ctx, r.cluster).QueryRowContext(ctx, query, at, id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, domain.ErrAPIKeyNotFound
+		}
+		r.logger.ErrorContext(ctx, "Failed to revoke api key", "error", err, "id", id)
+		return nil, fmt.Errorf("failed to revoke api key: %w", err)
+	}
+
+	r.logger.InfoContext(ctx, "API key revoked", "id", id, "name", key.Name)
+	return key, nil
+}
+
+func (r *apiKeyRepository) TouchLastUsed(ctx context.Context, id int, at time.Time) error {
+	if _, err := writer(ctx, r.cluster).ExecContext(ctx, "UPDATE api_keys SET last_used_at = $1 WHERE id = $2", at, id); err != nil {
+		return fmt.Errorf("failed to update api key usage: %w", err)
+	}
+	return nil
+}
+
+func (r *apiKeyRepository) CountActive(ctx context.Context, scope domain.Scope) (int, error) {
+	query := `
+		SELECT COUNT(*) FROM api_keys
+		WHERE $1 = ANY(scopes) AND revoked_at IS NULL AND (expires_at IS NULL OR expires_at > NOW())`
+
+	var count int
+	if err := r.cluster.Primary().QueryRowContext(ctx, query, string(scope)).Scan(&count); err != nil {
+		return 0, fmt.Errorf("failed to count api keys: %w", err)
+	}
+
+	return count, nil
+}
+
+func scanAPIKey(row rowScanner) (*domain.APIKey, error) {
+	var (
+		key    domain.APIKey
+		scopes []string
+	)
+	err := row.Scan(&key.ID, &key.Name, &key.Prefix, &key.Hash, pq.Array(&scopes),
+		&key.ExpiresAt, &key.LastUsedAt, &key.RevokedAt, &key.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, scope := range scopes {
+		key.Scopes = append(key.Scopes, domain.Scope(scope))
+	}
+	return &key, nil
+}
+
+func scopesToStrings(scopes []domain.Scope) []string {
+	values := make([]string, 0, len(scopes))
+	for _, scope := range scopes {
+		values = append(values, string(scope))
+	}
+	return values
+}
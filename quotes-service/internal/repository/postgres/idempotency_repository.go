@@ -0,0 +1,116 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"quotes-service/internal/domain"
+	"quotes-service/internal/infrastructure/database"
+	"quotes-service/internal/infrastructure/logger"
+)
+
+type idempotencyRepository struct {
+	cluster *database.Cluster
+	logger  *logger.Logger
+}
+
+func NewIdempotencyRepository(cluster *database.Cluster, logger *logger.Logger) domain.IdempotencyRepository {
+	return &idempotencyRepository{
+		cluster: cluster,
+		logger:  logger,
+	}
+}
+
+// Claim опирается на первичный ключ: из параллельных дубликатов вставку
+// выполняет только один, остальные получают его запись. Все чтения - из primary
+func (r *idempotencyRepository) Claim(ctx context.Context, record *domain.IdempotencyRecord) (*domain.IdempotencyRecord, bool, error) {
+	claim := `
+		INSERT INTO idempotency_keys (client, idempotency_key, fingerprint, created_at, expires_at)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (client, idempotency_key) DO UPDATE
+		SET fingerprint = EXCLUDED.fingerprint, status_code = NULL, content_type = NULL, response = NULL,
+		    created_at = EXCLUDED.created_at, expires_at = EXCLUDED.expires_at
+		WHERE idempotency_keys.expires_at <= NOW()`
+
+	existing := `
+		SELECT fingerprint, COALESCE(status_code, 0), COALESCE(content_type, ''), response, created_at, expires_at
+		FROM idempotency_keys
+		WHERE client = $1 AND idempotency_key = $2 AND expires_at > NOW()`
+
+	// Запись может истечь между вставкой и чтением - тогда попытка повторяется
+	for attempt := 0; attempt < 3; attempt++ {
+		result, err := r.cluster.Primary().ExecContext(ctx, claim,
+			record.Client, record.Key, record.Fingerprint, record.CreatedAt, record.ExpiresAt,
+		)
+		if err != nil {
+			return nil, false, fmt.Errorf("failed to claim idempotency key: %w", err)
+		}
+		if rows, err := result.RowsAffected(); err != nil {
+			return nil, false, fmt.Errorf("failed to get rows affected: %w", err)
+		} else if rows == 1 {
+			return record, true, nil
+		}
+
+		stored := domain.IdempotencyRecord{Client: record.Client, Key: record.Key}
+		err = r.cluster.Primary().QueryRowContext(ctx, existing, record.Client, record.Key).Scan(
+			&stored.Fingerprint, &stored.StatusCode, &stored.ContentType, &stored.Body, &stored.CreatedAt, &stored.ExpiresAt,
+		)
+		if errors.Is(err, sql.ErrNoRows) {
+			continue
+		}
+		if err != nil {
+			return nil, false, fmt.Errorf("failed to get idempotency key: %w", err)
+		}
+		return &stored, false, nil
+	}
+
+	return nil, false, domain.ErrIdempotencyInProgress
+}
+
+func (r *idempotencyRepository) Complete(ctx context.Context, record *domain.IdempotencyRecord) error {
+	query := `
+		UPDATE idempotency_keys
+		SET status_code = $3, content_type = $4, response = $5, expires_at = $6
+		WHERE client = $1 AND idempotency_key = $2 AND fingerprint = $7`
+
+	_, err := r.cluster.Primary().ExecContext(ctx, query,
+		record.Client, record.Key, record.StatusCode, record.ContentType, record.Body, record.ExpiresAt, record.Fingerprint,
+	)
+	if err != nil {
+		r.logger.Error("Failed to store idempotent response", "error", err, "client", record.Client)
+		return fmt.Errorf("failed to store idempotent response: %w", err)
+	}
+	return nil
+}
+
+func (r *idempotencyRepository) Extend(ctx context.Context, client, key, fingerprint string, expiresAt time.Time) error {
+	query := `
+		UPDATE idempotency_keys
+		SET expires_at = $4
+		WHERE client = $1 AND idempotency_key = $2 AND fingerprint = $3 AND status_code IS NULL`
+
+	if _, err := r.cluster.Primary().ExecContext(ctx, query, client, key, fingerprint, expiresAt); err != nil {
+		return fmt.Errorf("failed to extend idempotency key: %w", err)
+	}
+	return nil
+}
+
+func (r *idempotencyRepository) Release(ctx context.Context, client, key string) error {
+	query := "DELETE FROM idempotency_keys WHERE client = $1 AND idempotency_key = $2 AND status_code IS NULL"
+
+	if _, err := r.cluster.Primary().ExecContext(ctx, query, client, key); err != nil {
+		return fmt.Errorf("failed to release idempotency key: %w", err)
+	}
+	return nil
+}
+
+func (r *idempotencyRepository) DeleteExpired(ctx context.Context) (int64, error) {
+	result, err := r.cluster.Primary().ExecContext(ctx, "DELETE FROM idempotency_keys WHERE expires_at <= NOW()")
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete expired idempotency keys: %w", err)
+	}
+	return result.RowsAffected()
+}
@@ -0,0 +1,264 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"quotes-service/internal/domain"
+	"quotes-service/internal/infrastructure/database"
+	"quotes-service/internal/infrastructure/logger"
+
+	"github.com/lib/pq"
+)
+
+// Ключ advisory-лока: события выбирает только один релей за раз,
+// поэтому порядок событий одной цитаты сохраняется между инстансами
+const outboxRelayLockKey = 7_041_028
+
+type outboxRepository struct {
+	cluster *database.Cluster
+	logger  *logger.Logger
+}
+
+func NewOutboxRepository(cluster *database.Cluster, logger *logger.Logger) domain.OutboxRepository {
+	return &outboxRepository{
+		cluster: cluster,
+		logger:  logger,
+	}
+}
+
+func (r *outboxRepository) Append(ctx context.Context, events ...*domain.Event) error {
+	query := `
+		INSERT INTO outbox_events (aggregate_id, event_type, payload, actor, occurred_at)
+		VALUES ($1, $2, $3, NULLIF($4, ''), $5)
+		RETURNING id`
+
+	for _, event := range events {
+		payload, err := json.Marshal(event.Quote)
+		if err != nil {
+			return fmt.Errorf("failed to encode event payload: %w", err)
+		}
+
+		err = writer(ctx, r.cluster).QueryRowContext(ctx, query,
+			event.QuoteID, string(event.Type), payload, event.Actor, event.OccurredAt,
+		).Scan(&event.ID)
+		if err != nil {
+			r.logger.Error("Failed to append outbox event", "error", err, "type", event.Type, "quote_id", event.QuoteID)
+			return fmt.Errorf("failed to append outbox event: %w", err)
+		}
+	}
+
+	return nil
+}
+
+func (r *outboxRepository) PendingLag(ctx context.Context) (time.Duration, error) {
+	query := `
+		SELECT COALESCE(EXTRACT(EPOCH FROM NOW() - MIN(occurred_at)), 0)
+		FROM outbox_events
+		WHERE published_at IS NULL`
+
+	var seconds float64
+	if err := r.cluster.Primary().QueryRowContext(ctx, query).Scan(&seconds); err != nil {
+		return 0, fmt.Errorf("failed to get outbox lag: %w", err)
+	}
+	return time.Duration(seconds * float64(time.Second)), nil
+}
+
+func (r *outboxRepository) ListAfter(ctx context.Context, afterID int64, limit int) ([]*domain.Event, error) {
+	query := `
+		SELECT id, aggregate_id, event_type, payload, COALESCE(actor, ''), occurred_at, attempts
+		FROM outbox_events
+		WHERE id > $1
+		ORDER BY id
+		LIMIT $2`
+
+	rows, err := r.cluster.Primary().QueryContext(ctx, query, afterID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list outbox events: %w", err)
+	}
+	defer rows.Close()
+
+	var events []*domain.Event
+	for rows.Next() {
+		event, err := scanEvent(rows)
+		if err != nil {
+			return nil, err
+		}
+		events = append(events, event)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating over outbox events: %w", err)
+	}
+
+	return events, nil
+}
+
+func (r *outboxRepository) LastEventID(ctx context.Context) (int64, error) {
+	var id int64
+	if err := r.cluster.Primary().QueryRowContext(ctx, "SELECT COALESCE(MAX(id), 0) FROM outbox_events").Scan(&id); err != nil {
+		return 0, fmt.Errorf("failed to get last outbox event: %w", err)
+	}
+	return id, nil
+}
+
+// ProcessPending не держит транзакцию во время публикации: события
+// арендуются короткой транзакцией, публикуются вне ее, а результаты
+// сохраняются второй короткой транзакцией
+func (r *outboxRepository) ProcessPending(ctx context.Context, limit int, lease time.Duration, process func(ctx context.Context, events []*domain.Event) []domain.EventResult) error {
+	events, err := r.claim(ctx, limit, lease)
+	if err != nil || len(events) == 0 {
+		return err
+	}
+
+	// Публикация ограничена сроком аренды: после него события может
+	// взять другой релей
+	publishCtx, cancel := context.WithTimeout(ctx, lease)
+	results := process(publishCtx, events)
+	cancel()
+
+	return r.record(ctx, events, results)
+}
+
+// claim выбирает готовые события и сдвигает их next_attempt_at на срок
+// аренды. Advisory-лок держится только на время выборки, чтобы два релея
+// не взяли события одной цитаты вразнобой
+func (r *outboxRepository) claim(ctx context.Context, limit int, lease time.Duration) ([]*domain.Event, error) {
+	tx, err := r.cluster.Primary().BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin outbox transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var locked bool
+	if err := tx.QueryRowContext(ctx, "SELECT pg_try_advisory_xact_lock($1)", outboxRelayLockKey).Scan(&locked); err != nil {
+		return nil, fmt.Errorf("failed to acquire outbox lock: %w", err)
+	}
+	if !locked {
+		return nil, nil
+	}
+
+	// Событие не выдается, пока более раннее событие той же цитаты ждет
+	// повтора или арендовано другим релеем
+	query := `
+		SELECT id, aggregate_id, event_type, payload, COALESCE(actor, ''), occurred_at, attempts
+		FROM outbox_events o
+		WHERE published_at IS NULL
+		  AND next_attempt_at <= NOW()
+		  AND NOT EXISTS (
+			SELECT 1 FROM outbox_events p
+			WHERE p.aggregate_id = o.aggregate_id
+			  AND p.published_at IS NULL
+			  AND p.id < o.id
+			  AND p.next_attempt_at > NOW()
+		  )
+		ORDER BY id
+		LIMIT $1
+		FOR UPDATE SKIP LOCKED`
+
+	rows, err := tx.QueryContext(ctx, query, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch outbox events: %w", err)
+	}
+
+	var (
+		events []*domain.Event
+		ids    []int64
+	)
+	for rows.Next() {
+		event, err := scanEvent(rows)
+		if err != nil {
+			rows.Close()
+			return nil, err
+		}
+		events = append(events, event)
+		ids = append(ids, event.ID)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating over outbox events: %w", err)
+	}
+
+	if len(events) == 0 {
+		return nil, nil
+	}
+
+	_, err = tx.ExecContext(ctx,
+		"UPDATE outbox_events SET next_attempt_at = NOW() + make_interval(secs => $2) WHERE id = ANY($1)",
+		pq.Array(ids), lease.Seconds())
+	if err != nil {
+		return nil, fmt.Errorf("failed to lease outbox events: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit outbox claim: %w", err)
+	}
+
+	return events, nil
+}
+
+// record сохраняет результаты публикации. Арендованные события без
+// результата (их цитата заблокирована ошибкой) снова становятся доступны
+func (r *outboxRepository) record(ctx context.Context, events []*domain.Event, results []domain.EventResult) error {
+	tx, err := r.cluster.Primary().BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin outbox transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	processed := make(map[int64]bool, len(results))
+	for _, result := range results {
+		processed[result.EventID] = true
+		if result.Err == nil {
+			_, err = tx.ExecContext(ctx,
+				"UPDATE outbox_events SET published_at = NOW(), attempts = attempts + 1, last_error = NULL WHERE id = $1 AND published_at IS NULL",
+				result.EventID)
+		} else {
+			_, err = tx.ExecContext(ctx,
+				"UPDATE outbox_events SET attempts = attempts + 1, last_error = $2, next_attempt_at = $3 WHERE id = $1 AND published_at IS NULL",
+				result.EventID, result.Err.Error(), result.RetryAt)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to update outbox event %d: %w", result.EventID, err)
+		}
+	}
+
+	var released []int64
+	for _, event := range events {
+		if !processed[event.ID] {
+			released = append(released, event.ID)
+		}
+	}
+	if len(released) > 0 {
+		_, err = tx.ExecContext(ctx,
+			"UPDATE outbox_events SET next_attempt_at = NOW() WHERE id = ANY($1) AND published_at IS NULL",
+			pq.Array(released))
+		if err != nil {
+			return fmt.Errorf("failed to release outbox events: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit outbox results: %w", err)
+	}
+
+	return nil
+}
+
+func scanEvent(rows *sql.Rows) (*domain.Event, error) {
+	var (
+		event     domain.Event
+		eventType string
+		payload   []byte
+	)
+	if err := rows.Scan(&event.ID, &event.QuoteID, &eventType, &payload, &event.Actor, &event.OccurredAt, &event.Attempts); err != nil {
+		return nil, fmt.Errorf("failed to scan outbox event: %w", err)
+	}
+	event.Type = domain.EventType(eventType)
+	if err := json.Unmarshal(payload, &event.Quote); err != nil {
+		return nil, fmt.Errorf("failed to decode outbox event %d: %w", event.ID, err)
+	}
+	return &event, nil
+}
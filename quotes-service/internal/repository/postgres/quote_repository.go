@@ -9,58 +9,71 @@ import (
 	"time"
 
 	"quotes-service/internal/domain"
+	"quotes-service/internal/infrastructure/database"
 	"quotes-service/internal/infrastructure/logger"
+
+	"github.com/lib/pq"
 )
 
 type quoteRepository struct {
-	db     *sql.DB
-	logger *logger.Logger
+	cluster *database.Cluster
+	logger  *logger.Logger
 }
 
-func NewQuoteRepository(db *sql.DB, logger *logger.Logger) domain.QuoteRepository {
+func NewQuoteRepository(cluster *database.Cluster, logger *logger.Logger) domain.QuoteRepository {
 	return &quoteRepository{
-		db:     db,
-		logger: logger,
+		cluster: cluster,
+		logger:  logger,
+	}
+}
+
+// read выполняет запрос на реплике; при сбое запрос повторяется на primary,
+// а реплика исключается, если сбой был на уровне соединения.
+func (r *quoteRepository) read(ctx context.Context, query func(db querier) error) error {
+	if tx, ok := txFromContext(ctx); ok {
+		return query(tx)
 	}
+
+	db := r.cluster.Reader(ctx)
+	err := query(db)
+	if err == nil || db == r.cluster.Primary() || errors.Is(err, sql.ErrNoRows) || ctx.Err() != nil {
+		return err
+	}
+
+	r.cluster.ReportFailure(db, err)
+	return query(r.cluster.Primary())
 }
 
-func (r *quoteRepository) Create(ctx context.Context, quote *domain.Quote) (*domain.Quote, error) {
+func (r *quoteRepository) Create(ctx context.Context, quote *domain.Quote) (_ *domain.Quote, err error) {
 	query := `
-		INSERT INTO quotes (author, text, created_at, updated_at)
-		VALUES ($1, $2, $3, $4)
-		RETURNING id, author, text, created_at, updated_at`
+		INSERT INTO quotes (author, text, tags, created_by, created_at, updated_at)
+		VALUES ($1, $2, $3, NULLIF($4, ''), $5, $6)
+		RETURNING id, author, text, tags, COALESCE(created_by, ''), created_at, updated_at`
+
+	ctx, span := startQuery(ctx, "Create", "INSERT", "quotes", query)
+	defer func() { span.end(1, err) }()
 
 	now := time.Now()
 	quote.CreatedAt = now
 	quote.UpdatedAt = now
 
 	var result domain.Quote
-	err := r.db.QueryRowContext(ctx, query, quote.Author, quote.Text, now, now).Scan(
-		&result.ID, &result.Author, &result.Text, &result.CreatedAt, &result.UpdatedAt,
+	err = writer(ctx, r.cluster).QueryRowContext(ctx, query, quote.Author, quote.Text, pq.Array(tagsOrEmpty(quote.Tags)), quote.CreatedBy, now, now).Scan(
+		&result.ID, &result.Author, &result.Text, pq.Array(&result.Tags), &result.CreatedBy, &result.CreatedAt, &result.UpdatedAt,
 	)
 
 	if err != nil {
-		r.logger.Error("Failed to create quote", "error", err, "author", quote.Author)
+		r.logger.ErrorContext(ctx, "Failed to create quote", "error", err, "author", quote.Author)
 		return nil, fmt.Errorf("failed to create quote: %w", err)
 	}
 
-	r.logger.Info("Quote created", "id", result.ID, "author", result.Author)
+	r.logger.InfoContext(ctx, "Quote created", "id", result.ID, "author", result.Author)
 	return &result, nil
 }
 
-func (r *quoteRepository) GetAll(ctx context.Context, filter domain.QuoteFilter) ([]*domain.Quote, error) {
-	query := "SELECT id, author, text, created_at, updated_at FROM quotes"
-	args := []interface{}{}
-	conditions := []string{}
-
-	if filter.Author != "" {
-		conditions = append(conditions, "author ILIKE $"+fmt.Sprintf("%d", len(args)+1))
-		args = append(args, "%"+filter.Author+"%")
-	}
-
-	if len(conditions) > 0 {
-		query += " WHERE " + strings.Join(conditions, " AND ")
-	}
+func (r *quoteRepository) GetAll(ctx context.Context, filter domain.QuoteFilter) (_ []*domain.Quote, err error) {
+	where, args := quoteConditions(filter)
+	query := "SELECT id, author, text, tags, COALESCE(created_by, ''), created_at, updated_at FROM quotes" + where
 
 	query += " ORDER BY created_at DESC"
 
@@ -74,81 +87,132 @@ func (r *quoteRepository) GetAll(ctx context.Context, filter domain.QuoteFilter)
 		args = append(args, filter.Offset)
 	}
 
-	rows, err := r.db.QueryContext(ctx, query, args...)
-	if err != nil {
-		r.logger.Error("Failed to get quotes", "error", err, "filter", filter)
-		return nil, fmt.Errorf("failed to get quotes: %w", err)
-	}
-	defer rows.Close()
-
 	var quotes []*domain.Quote
-	for rows.Next() {
-		var quote domain.Quote
-		err := rows.Scan(&quote.ID, &quote.Author, &quote.Text, &quote.CreatedAt, &quote.UpdatedAt)
+	ctx, span := startQuery(ctx, "GetAll", "SELECT", "quotes", query)
+	defer func() { span.end(len(quotes), err) }()
+
+	err = r.read(ctx, func(db querier) error {
+		quotes = nil
+
+		rows, err := db.QueryContext(ctx, query, args...)
 		if err != nil {
-			r.logger.Error("Failed to scan quote", "error", err)
-			return nil, fmt.Errorf("failed to scan quote: %w", err)
+			r.logger.ErrorContext(ctx, "Failed to get quotes", "error", err, "filter", filter)
+			return fmt.Errorf("failed to get quotes: %w", err)
+		}
+		defer rows.Close()
+
+		for rows.Next() {
+			var quote domain.Quote
+			err := rows.Scan(&quote.ID, &quote.Author, &quote.Text, pq.Array(&quote.Tags), &quote.CreatedBy, &quote.CreatedAt, &quote.UpdatedAt)
+			if err != nil {
+				r.logger.ErrorContext(ctx, "Failed to scan quote", "error", err)
+				return fmt.Errorf("failed to scan quote: %w", err)
+			}
+			quotes = append(quotes, &quote)
 		}
-		quotes = append(quotes, &quote)
-	}
 
-	if err = rows.Err(); err != nil {
-		return nil, fmt.Errorf("error iterating over quotes: %w", err)
+		if err = rows.Err(); err != nil {
+			return fmt.Errorf("error iterating over quotes: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
 
-	r.logger.Debug("Retrieved quotes", "count", len(quotes), "filter", filter)
+	r.logger.DebugContext(ctx, "Retrieved quotes", "count", len(quotes), "filter", filter)
 	return quotes, nil
 }
 
-func (r *quoteRepository) GetByID(ctx context.Context, id int) (*domain.Quote, error) {
-	query := "SELECT id, author, text, created_at, updated_at FROM quotes WHERE id = $1"
+func (r *quoteRepository) GetByID(ctx context.Context, id int) (_ *domain.Quote, err error) {
+	query := "SELECT id, author, text, tags, COALESCE(created_by, ''), created_at, updated_at FROM quotes WHERE id = $1"
+
+	ctx, span := startQuery(ctx, "GetByID", "SELECT", "quotes", query)
+	defer func() { span.end(1, err) }()
 
 	var quote domain.Quote
-	err := r.db.QueryRowContext(ctx, query, id).Scan(
-		&quote.ID, &quote.Author, &quote.Text, &quote.CreatedAt, &quote.UpdatedAt,
-	)
+	err = r.read(ctx, func(db querier) error {
+		return db.QueryRowContext(ctx, query, id).Scan(
+			&quote.ID, &quote.Author, &quote.Text, pq.Array(&quote.Tags), &quote.CreatedBy, &quote.CreatedAt, &quote.UpdatedAt,
+		)
+	})
 
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return nil, domain.ErrQuoteNotFound
 		}
-		r.logger.Error("Failed to get quote by ID", "error", err, "id", id)
+		r.logger.ErrorContext(ctx, "Failed to get quote by ID", "error", err, "id", id)
 		return nil, fmt.Errorf("failed to get quote: %w", err)
 	}
 
 	return &quote, nil
 }
 
-func (r *quoteRepository) GetRandom(ctx context.Context) (*domain.Quote, error) {
-	query := "SELECT id, author, text, created_at, updated_at FROM quotes ORDER BY RANDOM() LIMIT 1"
+func (r *quoteRepository) GetRandom(ctx context.Context) (_ *domain.Quote, err error) {
+	query := "SELECT id, author, text, tags, COALESCE(created_by, ''), created_at, updated_at FROM quotes ORDER BY RANDOM() LIMIT 1"
+
+	ctx, span := startQuery(ctx, "GetRandom", "SELECT", "quotes", query)
+	defer func() { span.end(1, err) }()
 
 	var quote domain.Quote
-	err := r.db.QueryRowContext(ctx, query).Scan(
-		&quote.ID, &quote.Author, &quote.Text, &quote.CreatedAt, &quote.UpdatedAt,
-	)
+	err = r.read(ctx, func(db querier) error {
+		return db.QueryRowContext(ctx, query).Scan(
+			&quote.ID, &quote.Author, &quote.Text, pq.Array(&quote.Tags), &quote.CreatedBy, &quote.CreatedAt, &quote.UpdatedAt,
+		)
+	})
 
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return nil, domain.ErrQuoteNotFound
 		}
-		r.logger.Error("Failed to get random quote", "error", err)
+		r.logger.ErrorContext(ctx, "Failed to get random quote", "error", err)
 		return nil, fmt.Errorf("failed to get random quote: %w", err)
 	}
 
-	r.logger.Debug("Retrieved random quote", "id", quote.ID, "author", quote.Author)
+	r.logger.DebugContext(ctx, "Retrieved random quote", "id", quote.ID, "author", quote.Author)
 	return &quote, nil
 }
 
-func (r *quoteRepository) Delete(ctx context.Context, id int) error {
+func (r *quoteRepository) Update(ctx context.Context, quote *domain.Quote) (_ *domain.Quote, err error) {
+	query := `
+		UPDATE quotes SET author = $1, text = $2, tags = $3, updated_at = $4
+		WHERE id = $5
+		RETURNING id, author, text, tags, COALESCE(created_by, ''), created_at, updated_at`
+
+	ctx, span := startQuery(ctx, "Update", "UPDATE", "quotes", query)
+	defer func() { span.end(1, err) }()
+
+	var result domain.Quote
+	err = writer(ctx, r.cluster).QueryRowContext(ctx, query, quote.Author, quote.Text, pq.Array(tagsOrEmpty(quote.Tags)), time.Now(), quote.ID).Scan(
+		&result.ID, &result.Author, &result.Text, pq.Array(&result.Tags), &result.CreatedBy, &result.CreatedAt, &result.UpdatedAt,
+	)
+
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, domain.ErrQuoteNotFound
+		}
+		r.logger.ErrorContext(ctx, "Failed to update quote", "error", err, "id", quote.ID)
+		return nil, fmt.Errorf("failed to update quote: %w", err)
+	}
+
+	r.logger.InfoContext(ctx, "Quote updated", "id", result.ID, "author", result.Author)
+	return &result, nil
+}
+
+func (r *quoteRepository) Delete(ctx context.Context, id int) (err error) {
 	query := "DELETE FROM quotes WHERE id = $1"
 
-	result, err := r.db.ExecContext(ctx, query, id)
+	var rowsAffected int64
+	ctx, span := startQuery(ctx, "Delete", "DELETE", "quotes", query)
+	defer func() { span.end(int(rowsAffected), err) }()
+
+	result, err := writer(ctx, r.cluster).ExecContext(ctx, query, id)
 	if err != nil {
-		r.logger.Error("Failed to delete quote", "error", err, "id", id)
+		r.logger.ErrorContext(ctx, "Failed to delete quote", "error", err, "id", id)
 		return fmt.Errorf("failed to delete quote: %w", err)
 	}
 
-	rowsAffected, err := result.RowsAffected()
+	rowsAffected, err = result.RowsAffected()
 	if err != nil {
 		return fmt.Errorf("failed to get rows affected: %w", err)
 	}
@@ -157,34 +221,155 @@ func (r *quoteRepository) Delete(ctx context.Context, id int) error {
 		return domain.ErrQuoteNotFound
 	}
 
-	r.logger.Info("Quote deleted", "id", id)
+	r.logger.InfoContext(ctx, "Quote deleted", "id", id)
 	return nil
 }
 
-func (r *quoteRepository) Count(ctx context.Context, filter domain.QuoteFilter) (int, error) {
-	query := "SELECT COUNT(*) FROM quotes"
+func (r *quoteRepository) Count(ctx context.Context, filter domain.QuoteFilter) (_ int, err error) {
+	where, args := quoteConditions(filter)
+	query := "SELECT COUNT(*) FROM quotes" + where
+
+	ctx, span := startQuery(ctx, "Count", "SELECT", "quotes", query)
+	defer func() { span.end(1, err) }()
+
+	var count int
+	err = r.read(ctx, func(db querier) error {
+		return db.QueryRowContext(ctx, query, args...).Scan(&count)
+	})
+	if err != nil {
+		r.logger.ErrorContext(ctx, "Failed to count quotes", "error", err, "filter", filter)
+		return 0, fmt.Errorf("failed to count quotes: %w", err)
+	}
+
+	return count, nil
+}
+
+func (r *quoteRepository) ListAuthors(ctx context.Context, filter domain.AuthorFilter) (_ []*domain.Author, err error) {
+	query := "SELECT author, COUNT(*) FROM quotes"
+	args := []interface{}{}
+
+	if len(filter.Names) > 0 {
+		args = append(args, pq.Array(filter.Names))
+		query += " WHERE author = ANY($1)"
+	}
+
+	query += " GROUP BY author ORDER BY COUNT(*) DESC, author"
+
+	if filter.Limit > 0 {
+		args = append(args, filter.Limit)
+		query += fmt.Sprintf(" LIMIT $%d", len(args))
+	}
+
+	if filter.Offset > 0 {
+		args = append(args, filter.Offset)
+		query += fmt.Sprintf(" OFFSET $%d", len(args))
+	}
+
+	var authors []*domain.Author
+	ctx, span := startQuery(ctx, "ListAuthors", "SELECT", "quotes", query)
+	defer func() { span.end(len(authors), err) }()
+
+	err = r.read(ctx, func(db querier) error {
+		authors = nil
+
+		rows, err := db.QueryContext(ctx, query, args...)
+		if err != nil {
+			return fmt.Errorf("failed to list authors: %w", err)
+		}
+		defer rows.Close()
+
+		for rows.Next() {
+			var author domain.Author
+			if err := rows.Scan(&author.Name, &author.QuoteCount); err != nil {
+				return fmt.Errorf("failed to scan author: %w", err)
+			}
+			authors = append(authors, &author)
+		}
+
+		return rows.Err()
+	})
+	if err != nil {
+		r.logger.ErrorContext(ctx, "Failed to list authors", "error", err)
+		return nil, err
+	}
+
+	return authors, nil
+}
+
+func (r *quoteRepository) GetByAuthors(ctx context.Context, authors []string, limit int) (_ []*domain.Quote, err error) {
+	query := `
+		SELECT id, author, text, tags, COALESCE(created_by, ''), created_at, updated_at FROM (
+			SELECT *, ROW_NUMBER() OVER (PARTITION BY author ORDER BY created_at DESC) AS n
+			FROM quotes WHERE author = ANY($1)
+		) ranked
+		WHERE n <= $2
+		ORDER BY author, created_at DESC`
+
+	var quotes []*domain.Quote
+	ctx, span := startQuery(ctx, "GetByAuthors", "SELECT", "quotes", query)
+	defer func() { span.end(len(quotes), err) }()
+
+	err = r.read(ctx, func(db querier) error {
+		quotes = nil
+
+		rows, err := db.QueryContext(ctx, query, pq.Array(authors), limit)
+		if err != nil {
+			return fmt.Errorf("failed to get quotes by authors: %w", err)
+		}
+		defer rows.Close()
+
+		for rows.Next() {
+			var quote domain.Quote
+			err := rows.Scan(&quote.ID, &quote.Author, &quote.Text, pq.Array(&quote.Tags), &quote.CreatedBy, &quote.CreatedAt, &quote.UpdatedAt)
+			if err != nil {
+				return fmt.Errorf("failed to scan quote: %w", err)
+			}
+			quotes = append(quotes, &quote)
+		}
+
+		return rows.Err()
+	})
+	if err != nil {
+		r.logger.ErrorContext(ctx, "Failed to get quotes by authors", "error", err, "authors", len(authors))
+		return nil, err
+	}
+
+	return quotes, nil
+}
+
+func (r *quoteRepository) HealthCheck(ctx context.Context) error {
+	return r.cluster.PingContext(ctx)
+}
+
+// quoteConditions строит WHERE для фильтра; общий для GetAll и Count
+func quoteConditions(filter domain.QuoteFilter) (string, []interface{}) {
 	args := []interface{}{}
 	conditions := []string{}
 
 	if filter.Author != "" {
-		conditions = append(conditions, "author ILIKE $1")
 		args = append(args, "%"+filter.Author+"%")
+		conditions = append(conditions, fmt.Sprintf("author ILIKE $%d", len(args)))
 	}
 
-	if len(conditions) > 0 {
-		query += " WHERE " + strings.Join(conditions, " AND ")
+	if filter.Tag != "" {
+		args = append(args, strings.ToLower(filter.Tag))
+		conditions = append(conditions, fmt.Sprintf("$%d = ANY(tags)", len(args)))
 	}
 
-	var count int
-	err := r.db.QueryRowContext(ctx, query, args...).Scan(&count)
-	if err != nil {
-		r.logger.Error("Failed to count quotes", "error", err, "filter", filter)
-		return 0, fmt.Errorf("failed to count quotes: %w", err)
+	if filter.CreatedBy != "" {
+		args = append(args, filter.CreatedBy)
+		conditions = append(conditions, fmt.Sprintf("created_by = $%d", len(args)))
 	}
 
-	return count, nil
+	if len(conditions) == 0 {
+		return "", args
+	}
+	return " WHERE " + strings.Join(conditions, " AND "), args
 }
 
-func (r *quoteRepository) HealthCheck(ctx context.Context) error {
-	return r.db.PingContext(ctx)
+func tagsOrEmpty(tags []string) []string {
+	if tags == nil {
+		return []string{}
+	}
+	return tags
 }
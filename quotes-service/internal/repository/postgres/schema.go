@@ -0,0 +1,69 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+
+	"quotes-service/internal/infrastructure/database"
+
+	"github.com/lib/pq"
+)
+
+// Миграции применяются вне сервиса и не отмечаются в БД, поэтому для каждой
+// проверяется наличие созданной ею таблицы или столбца
+var migrationColumns = []struct {
+	migration string
+	table     string
+	column    string
+}{
+	{"001_create_quotes_table", "quotes", "id"},
+	{"002_create_outbox_table", "outbox_events", "id"},
+	{"003_create_webhooks_tables", "webhook_deliveries", "id"},
+	{"004_add_quote_tags", "quotes", "tags"},
+	{"005_create_api_keys_table", "api_keys", "id"},
+	{"006_add_outbox_actor", "outbox_events", "actor"},
+	{"007_add_quote_created_by", "quotes", "created_by"},
+	{"008_create_idempotency_keys_table", "idempotency_keys", "idempotency_key"},
+}
+
+// MissingMigrations возвращает миграции, объекты которых не найдены в схеме
+func MissingMigrations(ctx context.Context, cluster *database.Cluster) ([]string, error) {
+	tables := make([]string, len(migrationColumns))
+	columns := make([]string, len(migrationColumns))
+	for i, m := range migrationColumns {
+		tables[i], columns[i] = m.table, m.column
+	}
+
+	query := `
+		SELECT c.table_name || '.' || c.column_name
+		FROM information_schema.columns c
+		JOIN unnest($1::text[], $2::text[]) AS required(table_name, column_name)
+		  ON c.table_name = required.table_name AND c.column_name = required.column_name
+		WHERE c.table_schema = current_schema()`
+
+	rows, err := cluster.Primary().QueryContext(ctx, query, pq.Array(tables), pq.Array(columns))
+	if err != nil {
+		return nil, fmt.Errorf("failed to inspect schema: %w", err)
+	}
+	defer rows.Close()
+
+	present := make(map[string]bool)
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, fmt.Errorf("failed to scan schema column: %w", err)
+		}
+		present[name] = true
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating over schema columns: %w", err)
+	}
+
+	var missing []string
+	for _, m := range migrationColumns {
+		if !present[m.table+"."+m.column] {
+			missing = append(missing, m.migration)
+		}
+	}
+	return missing, nil
+}
@@ -0,0 +1,66 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"regexp"
+	"strings"
+
+	"quotes-service/internal/domain"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+var tracer = otel.Tracer("quotes-service/internal/repository/postgres")
+
+var (
+	sqlStringLiteral  = regexp.MustCompile(`'(?:[^']|'')*'`)
+	sqlNumericLiteral = regexp.MustCompile(`([^$\w])\d+(?:\.\d+)?\b`)
+)
+
+// querySpan - span одного обращения репозитория к БД
+type querySpan struct {
+	span trace.Span
+}
+
+// startQuery открывает клиентский span запроса с текстом SQL без литералов.
+// Значения параметров ($1, $2, ...) в трейс не попадают
+func startQuery(ctx context.Context, method, operation, table, query string) (context.Context, *querySpan) {
+	ctx, span := tracer.Start(ctx, operation+" "+table,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			semconv.DBSystemPostgreSQL,
+			semconv.DBOperationName(operation),
+			semconv.DBCollectionName(table),
+			semconv.DBQueryText(sanitizeQuery(query)),
+			semconv.CodeFunction(method),
+		),
+	)
+	return ctx, &querySpan{span: span}
+}
+
+// end записывает число строк результата; ненайденная строка - не ошибка
+func (q *querySpan) end(rows int, err error) {
+	switch {
+	case err == nil:
+		q.span.SetAttributes(attribute.Int("db.response.returned_rows", rows))
+	case errors.Is(err, sql.ErrNoRows), errors.Is(err, domain.ErrQuoteNotFound):
+		q.span.SetAttributes(attribute.Int("db.response.returned_rows", 0))
+	default:
+		q.span.RecordError(err)
+		q.span.SetStatus(codes.Error, err.Error())
+	}
+	q.span.End()
+}
+
+// sanitizeQuery заменяет строковые и числовые литералы на ? и схлопывает пробелы
+func sanitizeQuery(query string) string {
+	query = sqlStringLiteral.ReplaceAllString(query, "?")
+	query = sqlNumericLiteral.ReplaceAllString(query, "$1?")
+	return strings.Join(strings.Fields(query), " ")
+}
@@ -0,0 +1,63 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"quotes-service/internal/domain"
+	"quotes-service/internal/infrastructure/database"
+)
+
+type txKey struct{}
+
+// querier - общее подмножество *sql.DB и *sql.Tx
+type querier interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
+type transactor struct {
+	cluster *database.Cluster
+}
+
+func NewTransactor(cluster *database.Cluster) domain.Transactor {
+	return &transactor{cluster: cluster}
+}
+
+func (t *transactor) WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	// Вложенный вызов переиспользует внешнюю транзакцию
+	if _, ok := txFromContext(ctx); ok {
+		return fn(ctx)
+	}
+
+	tx, err := t.cluster.Primary().BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+
+	if err := fn(database.WithTransaction(context.WithValue(ctx, txKey{}, tx))); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+
+	return nil
+}
+
+func txFromContext(ctx context.Context) (*sql.Tx, bool) {
+	tx, ok := ctx.Value(txKey{}).(*sql.Tx)
+	return tx, ok
+}
+
+// writer возвращает текущую транзакцию или пул primary
+func writer(ctx context.Context, cluster *database.Cluster) querier {
+	if tx, ok := txFromContext(ctx); ok {
+		return tx
+	}
+	return cluster.Primary()
+}
@@ -0,0 +1,304 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"quotes-service/internal/domain"
+	"quotes-service/internal/infrastructure/database"
+	"quotes-service/internal/infrastructure/logger"
+
+	"github.com/lib/pq"
+)
+
+const (
+	subscriptionColumns = "id, url, secret, event_types, active, created_at, updated_at"
+	deliveryColumns     = "id, subscription_id, event_id, event_type, payload, status, attempts, " +
+		"COALESCE(response_status, 0), COALESCE(last_error, ''), next_attempt_at, last_attempt_at, delivered_at, created_at"
+)
+
+type webhookRepository struct {
+	cluster *database.Cluster
+	logger  *logger.Logger
+}
+
+func NewWebhookRepository(cluster *database.Cluster, logger *logger.Logger) domain.WebhookRepository {
+	return &webhookRepository{
+		cluster: cluster,
+		logger:  logger,
+	}
+}
+
+func (r *webhookRepository) CreateSubscription(ctx context.Context, sub *domain.WebhookSubscription) (*domain.WebhookSubscription, error) {
+	query := `
+		INSERT INTO webhook_subscriptions (url, secret, event_types, active, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $5)
+		RETURNING ` + subscriptionColumns
+
+	result, err := scanSubscription(writer(ctx, r.cluster).QueryRowContext(ctx, query,
+		sub.URL, sub.Secret, pq.Array(eventTypesToStrings(sub.EventTypes)), sub.Active, time.Now(),
+	))
+	if err != nil {
+		r.logger.ErrorContext(ctx, "Failed to create webhook subscription", "error", err, "url", sub.URL)
+		return nil, fmt.Errorf("failed to create webhook subscription: %w", err)
+	}
+
+	r.logger.InfoContext(ctx, "Webhook subscription created", "id", result.ID, "url", result.URL)
+	return result, nil
+}
+
+func (r *webhookRepository) GetSubscription(ctx context.Context, id int) (*domain.WebhookSubscription, error) {
+	query := "SELECT " + subscriptionColumns + " FROM webhook_subscriptions WHERE id = $1"
+
+	sub, err := scanSubscription(r.cluster.Primary().QueryRowContext(ctx, query, id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, domain.ErrWebhookNotFound
+		}
+		return nil, fmt.Errorf("failed to get webhook subscription: %w", err)
+	}
+
+	return sub, nil
+}
+
+func (r *webhookRepository) ListSubscriptions(ctx context.Context) ([]*domain.WebhookSubscription, error) {
+	query := "SELECT " + subscriptionColumns + " FROM webhook_subscriptions ORDER BY id"
+
+	rows, err := r.cluster.Primary().QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list webhook subscriptions: %w", err)
+	}
+	defer rows.Close()
+
+	var subs []*domain.WebhookSubscription
+	for rows.Next() {
+		sub, err := scanSubscription(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan webhook subscription: %w", err)
+		}
+		subs = append(subs, sub)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating over webhook subscriptions: %w", err)
+	}
+
+	return subs, nil
+}
+
+func (r *webhookRepository) UpdateSubscription(ctx context.Context, sub *domain.WebhookSubscription) (*domain.WebhookSubscription, error) {
+	query := `
+		UPDATE webhook_subscriptions
+		SET url = $1, secret = $2, event_types = $3, active = $4, updated_at = $5
+		WHERE id = $6
+		RETURNING ` + subscriptionColumns
+
+	result, err := scanSubscription(writer(ctx, r.cluster).QueryRowContext(ctx, query,
+		sub.URL, sub.Secret, pq.Array(eventTypesToStrings(sub.EventTypes)), sub.Active, time.Now(), sub.ID,
+	))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, domain.ErrWebhookNotFound
+		}
+		r.logger.ErrorContext(ctx, "Failed to update webhook subscription", "error", err, "id", sub.ID)
+		return nil, fmt.Errorf("failed to update webhook subscription: %w", err)
+	}
+
+	return result, nil
+}
+
+func (r *webhookRepository) DeleteSubscription(ctx context.Context, id int) error {
+	result, err := writer(ctx, r.cluster).ExecContext(ctx, "DELETE FROM webhook_subscriptions WHERE id = $1", id)
+	if err != nil {
+		r.logger.ErrorContext(ctx, "Failed to delete webhook subscription", "error", err, "id", id)
+		return fmt.Errorf("failed to delete webhook subscription: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	if rowsAffected == 0 {
+		return domain.ErrWebhookNotFound
+	}
+
+	r.logger.InfoContext(ctx, "Webhook subscription deleted", "id", id)
+	return nil
+}
+
+func (r *webhookRepository) EnqueueDeliveries(ctx context.Context, deliveries ...*domain.WebhookDelivery) error {
+	query := `
+		INSERT INTO webhook_deliveries (subscription_id, event_id, event_type, payload, next_attempt_at)
+		VALUES ($1, $2, $3, $4, NOW())
+		ON CONFLICT (subscription_id, event_id) DO NOTHING`
+
+	for _, d := range deliveries {
+		_, err := writer(ctx, r.cluster).ExecContext(ctx, query,
+			d.SubscriptionID, d.EventID, string(d.EventType), []byte(d.Payload),
+		)
+		if err != nil {
+			return fmt.Errorf("failed to enqueue webhook delivery: %w", err)
+		}
+	}
+
+	return nil
+}
+
+func (r *webhookRepository) ClaimDueDeliveries(ctx context.Context, limit int, lease time.Duration) ([]*domain.WebhookDelivery, error) {
+	// Сдвиг next_attempt_at на время lease скрывает доставку от других
+	// воркеров; если воркер упадет, она снова станет готовой
+	query := `
+		UPDATE webhook_deliveries SET next_attempt_at = NOW() + $2 * INTERVAL '1 millisecond'
+		WHERE id IN (
+			SELECT id FROM webhook_deliveries
+			WHERE status = 'pending' AND next_attempt_at <= NOW()
+			ORDER BY next_attempt_at, id
+			LIMIT $1
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING ` + deliveryColumns
+
+	return r.queryDeliveries(ctx, r.cluster.Primary(), query, limit, lease.Milliseconds())
+}
+
+func (r *webhookRepository) SaveDeliveryResult(ctx context.Context, d *domain.WebhookDelivery) error {
+	query := `
+		UPDATE webhook_deliveries
+		SET status = $2, attempts = $3, response_status = NULLIF($4, 0), last_error = NULLIF($5, ''),
+			next_attempt_at = $6, last_attempt_at = $7, delivered_at = $8
+		WHERE id = $1`
+
+	_, err := r.cluster.Primary().ExecContext(ctx, query,
+		d.ID, string(d.Status), d.Attempts, d.ResponseStatus, d.LastError, d.NextAttemptAt, d.LastAttemptAt, d.DeliveredAt,
+	)
+	if err != nil {
+		r.logger.ErrorContext(ctx, "Failed to save webhook delivery", "error", err, "id", d.ID)
+		return fmt.Errorf("failed to save webhook delivery: %w", err)
+	}
+
+	return nil
+}
+
+func (r *webhookRepository) ListDeliveries(ctx context.Context, filter domain.DeliveryFilter) ([]*domain.WebhookDelivery, error) {
+	query := "SELECT " + deliveryColumns + " FROM webhook_deliveries"
+	args := []interface{}{}
+	conditions := []string{}
+
+	if filter.SubscriptionID > 0 {
+		args = append(args, filter.SubscriptionID)
+		conditions = append(conditions, fmt.Sprintf("subscription_id = $%d", len(args)))
+	}
+	if filter.Status != "" {
+		args = append(args, string(filter.Status))
+		conditions = append(conditions, fmt.Sprintf("status = $%d", len(args)))
+	}
+
+	if len(conditions) > 0 {
+		query += " WHERE " + strings.Join(conditions, " AND ")
+	}
+
+	query += " ORDER BY id DESC"
+
+	if filter.Limit > 0 {
+		args = append(args, filter.Limit)
+		query += fmt.Sprintf(" LIMIT $%d", len(args))
+	}
+
+	return r.queryDeliveries(ctx, r.cluster.Reader(ctx), query, args...)
+}
+
+// RequeueDelivery возвращает в очередь только dead-letter: повтор доставленной
+// или ожидающей доставки отправил бы подписчику дубль. Счетчик попыток
+// сбрасывается, иначе доставка снова ушла бы в dead после первой ошибки
+func (r *webhookRepository) RequeueDelivery(ctx context.Context, id int64) (*domain.WebhookDelivery, error) {
+	query := `
+		UPDATE webhook_deliveries
+		SET status = 'pending', attempts = 0, last_error = NULL, response_status = NULL, next_attempt_at = NOW()
+		WHERE id = $1 AND status = 'dead'
+		RETURNING ` + deliveryColumns
+
+	db := writer(ctx, r.cluster)
+	deliveries, err := r.queryDeliveries(ctx, db, query, id)
+	if err != nil {
+		return nil, err
+	}
+	if len(deliveries) == 0 {
+		var exists bool
+		if err := db.QueryRowContext(ctx, `SELECT EXISTS (SELECT 1 FROM webhook_deliveries WHERE id = $1)`, id).Scan(&exists); err != nil {
+			return nil, fmt.Errorf("failed to check webhook delivery: %w", err)
+		}
+		if exists {
+			return nil, domain.ErrDeliveryNotDead
+		}
+		return nil, domain.ErrDeliveryNotFound
+	}
+
+	r.logger.InfoContext(ctx, "Webhook delivery requeued", "id", id)
+	return deliveries[0], nil
+}
+
+func (r *webhookRepository) queryDeliveries(ctx context.Context, db querier, query string, args ...interface{}) ([]*domain.WebhookDelivery, error) {
+	rows, err := db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query webhook deliveries: %w", err)
+	}
+	defer rows.Close()
+
+	var deliveries []*domain.WebhookDelivery
+	for rows.Next() {
+		var (
+			d         domain.WebhookDelivery
+			eventType string
+			status    string
+			payload   []byte
+		)
+		err := rows.Scan(&d.ID, &d.SubscriptionID, &d.EventID, &eventType, &payload, &status, &d.Attempts,
+			&d.ResponseStatus, &d.LastError, &d.NextAttemptAt, &d.LastAttemptAt, &d.DeliveredAt, &d.CreatedAt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan webhook delivery: %w", err)
+		}
+		d.EventType = domain.EventType(eventType)
+		d.Status = domain.DeliveryStatus(status)
+		d.Payload = payload
+		deliveries = append(deliveries, &d)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating over webhook deliveries: %w", err)
+	}
+
+	return deliveries, nil
+}
+
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanSubscription(row rowScanner) (*domain.WebhookSubscription, error) {
+	var (
+		sub        domain.WebhookSubscription
+		eventTypes []string
+	)
+	err := row.Scan(&sub.ID, &sub.URL, &sub.Secret, pq.Array(&eventTypes), &sub.Active, &sub.CreatedAt, &sub.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, t := range eventTypes {
+		sub.EventTypes = append(sub.EventTypes, domain.EventType(t))
+	}
+	return &sub, nil
+}
+
+func eventTypesToStrings(types []domain.EventType) []string {
+	values := make([]string, 0, len(types))
+	for _, t := range types {
+		values = append(values, string(t))
+	}
+	return values
+}
@@ -0,0 +1,60 @@
+package rpc
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	quotesv1 "quotes-service/api/proto/quotes/v1"
+	"quotes-service/internal/auth"
+	"quotes-service/internal/domain"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// methodPermissions - требуемое право для методов quotes.v1; health и reflection открыты
+var methodPermissions = map[string]domain.Permission{
+	quotesv1.QuoteService_CreateQuote_FullMethodName:    domain.PermissionCreate,
+	quotesv1.QuoteService_UpdateQuote_FullMethodName:    domain.PermissionUpdate,
+	quotesv1.QuoteService_DeleteQuote_FullMethodName:    domain.PermissionDelete,
+	quotesv1.QuoteService_GetQuote_FullMethodName:       domain.PermissionRead,
+	quotesv1.QuoteService_ListQuotes_FullMethodName:     domain.PermissionRead,
+	quotesv1.QuoteService_GetRandomQuote_FullMethodName: domain.PermissionRead,
+}
+
+// AuthUnaryInterceptor проверяет JWT или API-ключ из metadata authorization: Bearer
+// или x-api-key так же, как HTTP-middleware
+func AuthUnaryInterceptor(authn *auth.Authenticator, protectReads bool) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		permission, ok := methodPermissions[info.FullMethod]
+		if !ok || (permission == domain.PermissionRead && !protectReads) {
+			return handler(ctx, req)
+		}
+
+		principal, err := authn.Authenticate(ctx, credentialsFromMetadata(ctx))
+		switch {
+		case errors.Is(err, domain.ErrUnauthenticated):
+			return nil, status.Error(codes.Unauthenticated, "missing or invalid credentials")
+		case err != nil:
+			return nil, status.Error(codes.Internal, "failed to authenticate request")
+		case !principal.Can(permission):
+			return nil, status.Error(codes.PermissionDenied, "insufficient permissions")
+		}
+
+		return handler(domain.ContextWithPrincipal(ctx, principal), req)
+	}
+}
+
+func credentialsFromMetadata(ctx context.Context) string {
+	md, _ := metadata.FromIncomingContext(ctx)
+	if values := md.Get("x-api-key"); len(values) > 0 {
+		return values[0]
+	}
+	if values := md.Get("authorization"); len(values) > 0 && len(values[0]) > 7 && strings.EqualFold(values[0][:7], "bearer ") {
+		return strings.TrimSpace(values[0][7:])
+	}
+	return ""
+}
@@ -0,0 +1,74 @@
+package rpc
+
+import (
+	"context"
+	"time"
+
+	"quotes-service/internal/infrastructure/logger"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+)
+
+// Интерцепторы повторяют HTTP-middleware: логирование каждого вызова
+// и перехват паники с ответом Internal
+
+func loggingUnaryInterceptor(log *logger.Logger) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		start := time.Now()
+		resp, err := handler(ctx, req)
+		logCall(ctx, log, info.FullMethod, start, err)
+		return resp, err
+	}
+}
+
+func loggingStreamInterceptor(log *logger.Logger) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		start := time.Now()
+		err := handler(srv, ss)
+		logCall(ss.Context(), log, info.FullMethod, start, err)
+		return err
+	}
+}
+
+func recoveryUnaryInterceptor(log *logger.Logger) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp interface{}, err error) {
+		defer func() {
+			if r := recover(); r != nil {
+				log.Error("Panic recovered", "error", r, "method", info.FullMethod)
+				err = status.Error(codes.Internal, "Internal server error")
+			}
+		}()
+
+		return handler(ctx, req)
+	}
+}
+
+func recoveryStreamInterceptor(log *logger.Logger) grpc.StreamServerInterceptor {
+	return func(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) (err error) {
+		defer func() {
+			if r := recover(); r != nil {
+				log.Error("Panic recovered", "error", r, "method", info.FullMethod)
+				err = status.Error(codes.Internal, "Internal server error")
+			}
+		}()
+
+		return handler(srv, ss)
+	}
+}
+
+func logCall(ctx context.Context, log *logger.Logger, method string, start time.Time, err error) {
+	remoteAddr := ""
+	if p, ok := peer.FromContext(ctx); ok {
+		remoteAddr = p.Addr.String()
+	}
+
+	log.Info("gRPC request",
+		"method", method,
+		"code", status.Code(err).String(),
+		"duration", time.Since(start).String(),
+		"remote_addr", remoteAddr,
+	)
+}
@@ -0,0 +1,164 @@
+package rpc
+
+import (
+	"context"
+	"errors"
+	"strconv"
+
+	quotesv1 "quotes-service/api/proto/quotes/v1"
+	"quotes-service/internal/domain"
+	"quotes-service/internal/infrastructure/logger"
+	"quotes-service/internal/service"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/emptypb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+const defaultPageSize = 100
+
+// QuoteServer - gRPC-реализация quotes.v1.QuoteService поверх того же
+// service.QuoteService, что использует HTTP API
+type QuoteServer struct {
+	quotesv1.UnimplementedQuoteServiceServer
+
+	service *service.QuoteService
+	logger  *logger.Logger
+}
+
+func NewQuoteServer(service *service.QuoteService, logger *logger.Logger) *QuoteServer {
+	return &QuoteServer{
+		service: service,
+		logger:  logger,
+	}
+}
+
+func (s *QuoteServer) CreateQuote(ctx context.Context, req *quotesv1.CreateQuoteRequest) (*quotesv1.Quote, error) {
+	quote, err := s.service.CreateQuote(ctx, domain.CreateQuoteRequest{
+		Author: req.GetAuthor(),
+		Quote:  req.GetQuote(),
+		Tags:   req.GetTags(),
+	})
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	return toProto(quote), nil
+}
+
+func (s *QuoteServer) GetQuote(ctx context.Context, req *quotesv1.GetQuoteRequest) (*quotesv1.Quote, error) {
+	quote, err := s.service.GetQuote(ctx, int(req.GetId()))
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	return toProto(quote), nil
+}
+
+func (s *QuoteServer) ListQuotes(ctx context.Context, req *quotesv1.ListQuotesRequest) (*quotesv1.ListQuotesResponse, error) {
+	offset := 0
+	if token := req.GetPageToken(); token != "" {
+		var err error
+		if offset, err = strconv.Atoi(token); err != nil || offset < 0 {
+			return nil, status.Error(codes.InvalidArgument, "invalid page_token")
+		}
+	}
+
+	pageSize := int(req.GetPageSize())
+	if pageSize <= 0 {
+		pageSize = defaultPageSize
+	}
+
+	filter := domain.QuoteFilter{
+		Author: req.GetAuthor(),
+		Tag:    req.GetTag(),
+		Limit:  pageSize,
+		Offset: offset,
+	}
+
+	quotes, err := s.service.GetAllQuotes(ctx, filter)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	total, err := s.service.CountQuotes(ctx, filter)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	resp := &quotesv1.ListQuotesResponse{
+		Quotes:    make([]*quotesv1.Quote, 0, len(quotes)),
+		TotalSize: int32(total),
+	}
+	for _, quote := range quotes {
+		resp.Quotes = append(resp.Quotes, toProto(quote))
+	}
+	if next := offset + len(quotes); len(quotes) > 0 && next < total {
+		resp.NextPageToken = strconv.Itoa(next)
+	}
+
+	return resp, nil
+}
+
+func (s *QuoteServer) GetRandomQuote(ctx context.Context, _ *quotesv1.GetRandomQuoteRequest) (*quotesv1.Quote, error) {
+	quote, err := s.service.GetRandomQuote(ctx)
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	return toProto(quote), nil
+}
+
+func (s *QuoteServer) UpdateQuote(ctx context.Context, req *quotesv1.UpdateQuoteRequest) (*quotesv1.Quote, error) {
+	quote, err := s.service.UpdateQuote(ctx, int(req.GetId()), domain.UpdateQuoteRequest{
+		Author: req.GetAuthor(),
+		Quote:  req.GetQuote(),
+		Tags:   req.GetTags(),
+	})
+	if err != nil {
+		return nil, toStatus(err)
+	}
+
+	return toProto(quote), nil
+}
+
+func (s *QuoteServer) DeleteQuote(ctx context.Context, req *quotesv1.DeleteQuoteRequest) (*emptypb.Empty, error) {
+	if err := s.service.DeleteQuote(ctx, int(req.GetId())); err != nil {
+		return nil, toStatus(err)
+	}
+
+	return &emptypb.Empty{}, nil
+}
+
+// toStatus переводит доменные ошибки в коды gRPC так же, как HTTP-хендлеры
+// переводят их в статусы ответа
+func toStatus(err error) error {
+	switch {
+	case errors.Is(err, domain.ErrQuoteNotFound):
+		return status.Error(codes.NotFound, "Quote not found")
+	case errors.Is(err, domain.ErrInvalidQuote):
+		return status.Error(codes.InvalidArgument, err.Error())
+	case errors.Is(err, domain.ErrQuoteForbidden):
+		return status.Error(codes.PermissionDenied, "Only the owner or an admin can modify this quote")
+	case errors.Is(err, domain.ErrUnauthenticated):
+		return status.Error(codes.Unauthenticated, "Authentication required")
+	case errors.Is(err, context.DeadlineExceeded):
+		return status.Error(codes.DeadlineExceeded, "Request timed out")
+	case errors.Is(err, context.Canceled):
+		return status.Error(codes.Canceled, "Request canceled")
+	default:
+		return status.Error(codes.Internal, "Internal server error")
+	}
+}
+
+func toProto(quote *domain.Quote) *quotesv1.Quote {
+	return &quotesv1.Quote{
+		Id:        int64(quote.ID),
+		Author:    quote.Author,
+		Quote:     quote.Text,
+		Tags:      quote.Tags,
+		CreatedAt: timestamppb.New(quote.CreatedAt),
+		UpdatedAt: timestamppb.New(quote.UpdatedAt),
+	}
+}
@@ -0,0 +1,98 @@
+package rpc
+
+import (
+	"context"
+	"net"
+	"time"
+
+	quotesv1 "quotes-service/api/proto/quotes/v1"
+	"quotes-service/internal/infrastructure/logger"
+	"quotes-service/internal/service"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/reflection"
+)
+
+// Server объединяет quotes.v1.QuoteService, grpc.health.v1 и reflection
+type Server struct {
+	grpc    *grpc.Server
+	health  *health.Server
+	service *service.QuoteService
+	logger  *logger.Logger
+}
+
+// NewServer: интерцепторы из opts выполняются после логирования и recovery
+func NewServer(service *service.QuoteService, logger *logger.Logger, opts ...grpc.ServerOption) *Server {
+	opts = append([]grpc.ServerOption{
+		grpc.ChainUnaryInterceptor(loggingUnaryInterceptor(logger), recoveryUnaryInterceptor(logger)),
+		grpc.ChainStreamInterceptor(loggingStreamInterceptor(logger), recoveryStreamInterceptor(logger)),
+	}, opts...)
+
+	s := &Server{
+		grpc:    grpc.NewServer(opts...),
+		health:  health.NewServer(),
+		service: service,
+		logger:  logger,
+	}
+
+	quotesv1.RegisterQuoteServiceServer(s.grpc, NewQuoteServer(service, logger))
+	healthpb.RegisterHealthServer(s.grpc, s.health)
+	reflection.Register(s.grpc)
+
+	return s
+}
+
+func (s *Server) Serve(listener net.Listener) error {
+	return s.grpc.Serve(listener)
+}
+
+// RunHealthChecks обновляет статус grpc.health.v1 по проверке БД,
+// как это делает GET /health
+func (s *Server) RunHealthChecks(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		s.CheckHealth(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *Server) CheckHealth(ctx context.Context) {
+	status := healthpb.HealthCheckResponse_SERVING
+	if err := s.service.HealthCheck(ctx); err != nil {
+		if ctx.Err() != nil {
+			return
+		}
+		s.logger.Warn("gRPC health check failed", "error", err)
+		status = healthpb.HealthCheckResponse_NOT_SERVING
+	}
+
+	s.health.SetServingStatus("", status)
+	s.health.SetServingStatus(quotesv1.QuoteService_ServiceDesc.ServiceName, status)
+}
+
+// Shutdown переводит health в NOT_SERVING и дожидается активных вызовов,
+// пока не истечет ctx
+func (s *Server) Shutdown(ctx context.Context) {
+	s.health.Shutdown()
+
+	stopped := make(chan struct{})
+	go func() {
+		s.grpc.GracefulStop()
+		close(stopped)
+	}()
+
+	select {
+	case <-stopped:
+	case <-ctx.Done():
+		s.grpc.Stop()
+	}
+}
@@ -0,0 +1,155 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"quotes-service/internal/domain"
+	"quotes-service/internal/infrastructure/logger"
+)
+
+const (
+	apiKeyPrefix = "qs_"
+
+	// last_used_at обновляется не чаще раза в минуту, чтобы чтение не превращалось в запись
+	lastUsedResolution = time.Minute
+)
+
+type APIKeyService struct {
+	repo     domain.APIKeyRepository
+	logger   *logger.Logger
+	timeouts atomic.Pointer[Timeouts]
+}
+
+func NewAPIKeyService(repo domain.APIKeyRepository, logger *logger.Logger) *APIKeyService {
+	s := &APIKeyService{
+		repo:   repo,
+		logger: logger,
+	}
+	s.SetTimeouts(DefaultTimeouts())
+	return s
+}
+
+// SetTimeouts меняет дедлайн операций (Timeouts.APIKey) на лету
+func (s *APIKeyService) SetTimeouts(timeouts Timeouts) {
+	s.timeouts.Store(&timeouts)
+}
+
+// IssueKey создает ключ и возвращает его открытым текстом; сохраняется только хеш
+func (s *APIKeyService) IssueKey(ctx context.Context, req domain.APIKeyRequest) (*domain.APIKey, string, error) {
+	if err := req.Validate(time.Now()); err != nil {
+		return nil, "", fmt.Errorf("%w: %s", domain.ErrInvalidAPIKey, err.Error())
+	}
+
+	prefix, secret, err := generateAPIKey()
+	if err != nil {
+		return nil, "", err
+	}
+	raw := prefix + "_" + secret
+
+	dbCtx, cancel := context.WithTimeout(ctx, s.timeouts.Load().APIKey)
+	defer cancel()
+
+	key, err := s.repo.Create(dbCtx, &domain.APIKey{
+		Name:      req.Name,
+		Prefix:    prefix,
+		Hash:      hashAPIKey(raw),
+		Scopes:    req.Scopes,
+		ExpiresAt: req.ExpiresAt,
+	})
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to issue api key: %w", err)
+	}
+
+	return key, raw, nil
+}
+
+// BootstrapAdminKey выпускает первый admin-ключ; при наличии активного admin-ключа отказывает
+func (s *APIKeyService) BootstrapAdminKey(ctx context.Context, name string) (*domain.APIKey, string, error) {
+	dbCtx, cancel := context.WithTimeout(ctx, s.timeouts.Load().APIKey)
+	defer cancel()
+
+	count, err := s.repo.CountActive(dbCtx, domain.ScopeAdmin)
+	if err != nil {
+		return nil, "", err
+	}
+	if count > 0 {
+		return nil, "", domain.ErrAdminKeyExists
+	}
+
+	return s.IssueKey(ctx, domain.APIKeyRequest{Name: name, Scopes: []domain.Scope{domain.ScopeAdmin}})
+}
+
+func (s *APIKeyService) ListKeys(ctx context.Context) ([]*domain.APIKey, error) {
+	dbCtx, cancel := context.WithTimeout(ctx, s.timeouts.Load().APIKey)
+	defer cancel()
+
+	keys, err := s.repo.List(dbCtx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list api keys: %w", err)
+	}
+
+	return keys, nil
+}
+
+func (s *APIKeyService) RevokeKey(ctx context.Context, id int) (*domain.APIKey, error) {
+	dbCtx, cancel := context.WithTimeout(ctx, s.timeouts.Load().APIKey)
+	defer cancel()
+
+	return s.repo.Revoke(dbCtx, id, time.Now())
+}
+
+// Authenticate проверяет ключ из запроса. Любая причина отказа сводится к
+// ErrUnauthenticated, чтобы не подсказывать, существует ли префикс
+func (s *APIKeyService) Authenticate(ctx context.Context, raw string) (*domain.APIKey, error) {
+	sep := strings.LastIndexByte(raw, '_')
+	if !strings.HasPrefix(raw, apiKeyPrefix) || sep <= len(apiKeyPrefix) {
+		return nil, domain.ErrUnauthenticated
+	}
+
+	dbCtx, cancel := context.WithTimeout(ctx, s.timeouts.Load().APIKey)
+	defer cancel()
+
+	key, err := s.repo.GetByPrefix(dbCtx, raw[:sep])
+	if err != nil {
+		if errors.Is(err, domain.ErrAPIKeyNotFound) {
+			return nil, domain.ErrUnauthenticated
+		}
+		return nil, fmt.Errorf("failed to authenticate api key: %w", err)
+	}
+
+	now := time.Now()
+	if subtle.ConstantTimeCompare([]byte(key.Hash), []byte(hashAPIKey(raw))) != 1 || !key.Active(now) {
+		return nil, domain.ErrUnauthenticated
+	}
+
+	if key.LastUsedAt == nil || now.Sub(*key.LastUsedAt) >= lastUsedResolution {
+		if err := s.repo.TouchLastUsed(dbCtx, key.ID, now); err != nil {
+			s.logger.WarnContext(ctx, "Failed to record api key usage", "error", err, "id", key.ID)
+		}
+		key.LastUsedAt = &now
+	}
+
+	return key, nil
+}
+
+func generateAPIKey() (prefix, secret string, err error) {
+	buf := make([]byte, 30)
+	if _, err := rand.Read(buf); err != nil {
+		return "", "", fmt.Errorf("failed to generate api key: %w", err)
+	}
+	return apiKeyPrefix + hex.EncodeToString(buf[:6]), hex.EncodeToString(buf[6:]), nil
+}
+
+func hashAPIKey(raw string) string {
+	sum := sha256.Sum256([]byte(raw))
+	return hex.EncodeToString(sum[:])
+}
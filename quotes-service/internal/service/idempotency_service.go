@@ -0,0 +1,157 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"quotes-service/internal/domain"
+	"quotes-service/internal/infrastructure/logger"
+)
+
+// IdempotencyConfig: Window - сколько хранится ответ, LockTimeout - сколько
+// ключ остается занятым, если инстанс упал, не ответив; пока запрос
+// выполняется, блокировка продлевается
+type IdempotencyConfig struct {
+	Window      time.Duration
+	LockTimeout time.Duration
+}
+
+type IdempotencyService struct {
+	repo     domain.IdempotencyRepository
+	config   IdempotencyConfig
+	logger   *logger.Logger
+	timeouts atomic.Pointer[Timeouts]
+}
+
+func NewIdempotencyService(repo domain.IdempotencyRepository, config IdempotencyConfig, logger *logger.Logger) *IdempotencyService {
+	s := &IdempotencyService{
+		repo:   repo,
+		config: config,
+		logger: logger,
+	}
+	s.SetTimeouts(DefaultTimeouts())
+	return s
+}
+
+// SetTimeouts меняет дедлайн операций (Timeouts.Idempotency) на лету
+func (s *IdempotencyService) SetTimeouts(timeouts Timeouts) {
+	s.timeouts.Store(&timeouts)
+}
+
+// Begin занимает ключ для нового запроса и возвращает nil, либо возвращает
+// сохраненный ответ для повтора. Ключ с другим отпечатком запроса -
+// ErrIdempotencyKeyReused, незавершенный запрос - ErrIdempotencyInProgress
+func (s *IdempotencyService) Begin(ctx context.Context, client, key, fingerprint string) (*domain.IdempotencyRecord, error) {
+	if key == "" || len(key) > 255 {
+		return nil, fmt.Errorf("%w: must be 1-255 characters", domain.ErrInvalidIdempotencyKey)
+	}
+
+	dbCtx, cancel := context.WithTimeout(ctx, s.timeouts.Load().Idempotency)
+	defer cancel()
+
+	now := time.Now()
+	stored, claimed, err := s.repo.Claim(dbCtx, &domain.IdempotencyRecord{
+		Client:      client,
+		Key:         key,
+		Fingerprint: fingerprint,
+		CreatedAt:   now,
+		ExpiresAt:   now.Add(s.config.LockTimeout),
+	})
+	switch {
+	case err != nil:
+		return nil, err
+	case claimed:
+		return nil, nil
+	case stored.Fingerprint != fingerprint:
+		s.logger.InfoContext(ctx, "Idempotency key reused with different request", "client", client)
+		return nil, domain.ErrIdempotencyKeyReused
+	case !stored.Completed():
+		return nil, domain.ErrIdempotencyInProgress
+	}
+
+	s.logger.DebugContext(ctx, "Replaying idempotent response", "client", client, "status", stored.StatusCode)
+	return stored, nil
+}
+
+// Complete сохраняет ответ на окно Window
+func (s *IdempotencyService) Complete(ctx context.Context, record *domain.IdempotencyRecord) error {
+	record.ExpiresAt = time.Now().Add(s.config.Window)
+
+	dbCtx, cancel := context.WithTimeout(ctx, s.timeouts.Load().Idempotency)
+	defer cancel()
+
+	return s.repo.Complete(dbCtx, record)
+}
+
+// Hold продлевает занятый ключ каждые LockTimeout/3, пока выполняется запрос:
+// повтор не займет ключ заново, даже если запрос идет дольше LockTimeout, а
+// после падения инстанса ключ освободится через LockTimeout. Возвращенная
+// функция останавливает продление
+func (s *IdempotencyService) Hold(ctx context.Context, client, key, fingerprint string) (stop func()) {
+	ctx, cancel := context.WithCancel(ctx)
+	done := make(chan struct{})
+
+	go func() {
+		defer close(done)
+
+		ticker := time.NewTicker(max(s.config.LockTimeout/3, time.Millisecond))
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if err := s.extend(ctx, client, key, fingerprint); err != nil && ctx.Err() == nil {
+					s.logger.ErrorContext(ctx, "Failed to extend idempotency key", "error", err, "client", client)
+				}
+			}
+		}
+	}()
+
+	return func() {
+		cancel()
+		<-done
+	}
+}
+
+func (s *IdempotencyService) extend(ctx context.Context, client, key, fingerprint string) error {
+	dbCtx, cancel := context.WithTimeout(ctx, s.timeouts.Load().Idempotency)
+	defer cancel()
+
+	return s.repo.Extend(dbCtx, client, key, fingerprint, time.Now().Add(s.config.LockTimeout))
+}
+
+// Release снимает блокировку ключа, если ответ не сохраняется
+func (s *IdempotencyService) Release(ctx context.Context, client, key string) error {
+	dbCtx, cancel := context.WithTimeout(ctx, s.timeouts.Load().Idempotency)
+	defer cancel()
+
+	return s.repo.Release(dbCtx, client, key)
+}
+
+// RunCleanup периодически удаляет истекшие ключи
+func (s *IdempotencyService) RunCleanup(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			deleted, err := s.repo.DeleteExpired(ctx)
+			if err != nil {
+				if ctx.Err() == nil {
+					s.logger.Error("Failed to delete expired idempotency keys", "error", err)
+				}
+				continue
+			}
+			if deleted > 0 {
+				s.logger.Debug("Expired idempotency keys deleted", "count", deleted)
+			}
+		}
+	}
+}
@@ -2,7 +2,9 @@ package service
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"sync/atomic"
 	"time"
 
 	"quotes-service/internal/domain"
@@ -10,44 +12,144 @@ import (
 )
 
 type QuoteService struct {
-	repo   domain.QuoteRepository
-	logger *logger.Logger
+	repo      domain.QuoteRepository
+	logger    *logger.Logger
+	tx        domain.Transactor
+	outbox    domain.OutboxRepository
+	listeners []domain.EventListener
+	metrics   domain.QuoteMetrics
+	timeouts  atomic.Pointer[Timeouts]
 }
 
-func NewQuoteService(repo domain.QuoteRepository, logger *logger.Logger) *QuoteService {
-	return &QuoteService{
-		repo:   repo,
-		logger: logger,
+// Timeouts - дедлайны операций с БД; если дедлайн запроса короче, раньше
+// срабатывает он. List действует на списки, подсчет, авторов и пакетную загрузку
+type Timeouts struct {
+	Create time.Duration
+	Get    time.Duration
+	List   time.Duration
+	Random time.Duration
+	Update time.Duration
+	Delete time.Duration
+	Health time.Duration
+	// APIKey - выпуск, проверка и отзыв API-ключей
+	APIKey time.Duration
+	// Idempotency - захват и сохранение ключей идемпотентности
+	Idempotency time.Duration
+}
+
+func DefaultTimeouts() Timeouts {
+	return Timeouts{
+		Create:      5 * time.Second,
+		Get:         5 * time.Second,
+		List:        10 * time.Second,
+		Random:      5 * time.Second,
+		Update:      5 * time.Second,
+		Delete:      5 * time.Second,
+		Health:      3 * time.Second,
+		APIKey:      5 * time.Second,
+		Idempotency: 5 * time.Second,
+	}
+}
+
+type Option func(*QuoteService)
+
+// WithOutbox включает запись доменных событий в outbox в той же
+// транзакции, что и изменение цитаты
+func WithOutbox(tx domain.Transactor, outbox domain.OutboxRepository) Option {
+	return func(s *QuoteService) {
+		s.tx = tx
+		s.outbox = outbox
+	}
+}
+
+// WithEventListeners подписывает in-process получателей на события,
+// уведомление приходит после коммита изменения
+func WithEventListeners(listeners ...domain.EventListener) Option {
+	return func(s *QuoteService) {
+		s.listeners = append(s.listeners, listeners...)
+	}
+}
+
+// WithMetrics подключает счетчики созданных и удаленных цитат, случайных
+// цитат и обращений к несуществующим цитатам
+func WithMetrics(metrics domain.QuoteMetrics) Option {
+	return func(s *QuoteService) {
+		s.metrics = metrics
 	}
 }
 
-func (s *QuoteService) CreateQuote(ctx context.Context, req domain.CreateQuoteRequest) (*domain.Quote, error) {
+// WithTimeouts задает дедлайны операций вместо DefaultTimeouts
+func WithTimeouts(timeouts Timeouts) Option {
+	return func(s *QuoteService) {
+		s.SetTimeouts(timeouts)
+	}
+}
+
+func NewQuoteService(repo domain.QuoteRepository, logger *logger.Logger, opts ...Option) *QuoteService {
+	s := &QuoteService{
+		repo:    repo,
+		logger:  logger,
+		tx:      noTransaction{},
+		metrics: noMetrics{},
+	}
+	s.SetTimeouts(DefaultTimeouts())
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+func (s *QuoteService) CreateQuote(ctx context.Context, req domain.CreateQuoteRequest) (_ *domain.Quote, err error) {
+	ctx, span := startSpan(ctx, "QuoteService.CreateQuote")
+	defer func() { endSpan(span, err) }()
+
 	// Validate request
 	if err := req.Validate(); err != nil {
-		s.logger.Debug("Invalid quote request", "error", err, "request", req)
+		s.logger.DebugContext(ctx, "Invalid quote request", "error", err, "request", req)
 		return nil, fmt.Errorf("%w: %s", domain.ErrInvalidQuote, err.Error())
 	}
 
 	quote := &domain.Quote{
 		Author: req.Author,
 		Text:   req.Quote,
+		Tags:   req.Tags,
+		// Автор изменения становится владельцем цитаты
+		CreatedBy: domain.ActorFromContext(ctx),
 	}
 
 	// Добавление метаданных
-	dbCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	dbCtx, cancel := context.WithTimeout(ctx, s.timeouts.Load().Create)
 	defer cancel()
 
-	createdQuote, err := s.repo.Create(dbCtx, quote)
+	var (
+		createdQuote *domain.Quote
+		event        *domain.Event
+	)
+	err = s.tx.WithinTransaction(dbCtx, func(ctx context.Context) error {
+		var err error
+		createdQuote, err = s.repo.Create(ctx, quote)
+		if err != nil {
+			return err
+		}
+		event = newEvent(ctx, domain.EventQuoteCreated, createdQuote.ID, createdQuote)
+		return s.emit(ctx, event)
+	})
 	if err != nil {
-		s.logger.Error("Failed to create quote", "error", err, "author", req.Author)
-		return nil, fmt.Errorf("failed to create quote: %w", err)
+		s.logger.ErrorContext(ctx, "Failed to create quote", "error", err, "author", req.Author)
+		return nil, fmt.Errorf("failed to create quote: %w", timeoutError(dbCtx, err))
 	}
+	s.invalidate(ctx)
+	s.notify(event)
+	s.metrics.QuoteCreated()
 
-	s.logger.Info("Quote created successfully", "id", createdQuote.ID, "author", createdQuote.Author)
+	s.logger.InfoContext(ctx, "Quote created successfully", "id", createdQuote.ID, "author", createdQuote.Author, "actor", event.Actor)
 	return createdQuote, nil
 }
 
-func (s *QuoteService) GetAllQuotes(ctx context.Context, filter domain.QuoteFilter) ([]*domain.Quote, error) {
+func (s *QuoteService) GetAllQuotes(ctx context.Context, filter domain.QuoteFilter) (_ []*domain.Quote, err error) {
+	ctx, span := startSpan(ctx, "QuoteService.GetAllQuotes")
+	defer func() { endSpan(span, err) }()
+
 	// Установка значений по умолчанию для фильтра
 	if filter.Limit <= 0 {
 		filter.Limit = 100 // Default limit
@@ -55,55 +157,317 @@ func (s *QuoteService) GetAllQuotes(ctx context.Context, filter domain.QuoteFilt
 	if filter.Limit > 1000 {
 		filter.Limit = 1000 // Max limit
 	}
+	if err := resolveCreatedBy(ctx, &filter); err != nil {
+		return nil, err
+	}
 
-	dbCtx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	dbCtx, cancel := context.WithTimeout(ctx, s.timeouts.Load().List)
 	defer cancel()
 
 	quotes, err := s.repo.GetAll(dbCtx, filter)
 	if err != nil {
-		s.logger.Error("Failed to get quotes", "error", err, "filter", filter)
-		return nil, fmt.Errorf("failed to get quotes: %w", err)
+		s.logger.ErrorContext(ctx, "Failed to get quotes", "error", err, "filter", filter)
+		return nil, fmt.Errorf("failed to get quotes: %w", timeoutError(dbCtx, err))
 	}
 
-	s.logger.Debug("Retrieved quotes", "count", len(quotes), "filter", filter)
+	s.logger.DebugContext(ctx, "Retrieved quotes", "count", len(quotes), "filter", filter)
 	return quotes, nil
 }
 
-func (s *QuoteService) GetRandomQuote(ctx context.Context) (*domain.Quote, error) {
-	dbCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+func (s *QuoteService) GetQuote(ctx context.Context, id int) (_ *domain.Quote, err error) {
+	ctx, span := startSpan(ctx, "QuoteService.GetQuote")
+	defer func() { endSpan(span, err) }()
+
+	if id <= 0 {
+		return nil, fmt.Errorf("%w: invalid quote ID", domain.ErrInvalidQuote)
+	}
+
+	dbCtx, cancel := context.WithTimeout(ctx, s.timeouts.Load().Get)
+	defer cancel()
+
+	quote, err := s.repo.GetByID(dbCtx, id)
+	if err != nil {
+		s.countNotFound("get", err)
+		return nil, fmt.Errorf("failed to get quote: %w", timeoutError(dbCtx, err))
+	}
+
+	return quote, nil
+}
+
+// CountQuotes возвращает общее число цитат под фильтром (без учета limit/offset)
+func (s *QuoteService) CountQuotes(ctx context.Context, filter domain.QuoteFilter) (_ int, err error) {
+	ctx, span := startSpan(ctx, "QuoteService.CountQuotes")
+	defer func() { endSpan(span, err) }()
+
+	if err := resolveCreatedBy(ctx, &filter); err != nil {
+		return 0, err
+	}
+
+	dbCtx, cancel := context.WithTimeout(ctx, s.timeouts.Load().List)
+	defer cancel()
+
+	count, err := s.repo.Count(dbCtx, filter)
+	if err != nil {
+		s.logger.ErrorContext(ctx, "Failed to count quotes", "error", err, "filter", filter)
+		return 0, fmt.Errorf("failed to count quotes: %w", timeoutError(dbCtx, err))
+	}
+
+	return count, nil
+}
+
+func (s *QuoteService) ListAuthors(ctx context.Context, filter domain.AuthorFilter) (_ []*domain.Author, err error) {
+	ctx, span := startSpan(ctx, "QuoteService.ListAuthors")
+	defer func() { endSpan(span, err) }()
+
+	if filter.Limit <= 0 {
+		filter.Limit = 100
+	}
+	if filter.Limit > 1000 {
+		filter.Limit = 1000
+	}
+
+	dbCtx, cancel := context.WithTimeout(ctx, s.timeouts.Load().List)
+	defer cancel()
+
+	authors, err := s.repo.ListAuthors(dbCtx, filter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list authors: %w", timeoutError(dbCtx, err))
+	}
+
+	return authors, nil
+}
+
+// GetQuotesByAuthors загружает цитаты нескольких авторов одним запросом,
+// сгруппированные по имени автора
+func (s *QuoteService) GetQuotesByAuthors(ctx context.Context, authors []string, limit int) (_ map[string][]*domain.Quote, err error) {
+	ctx, span := startSpan(ctx, "QuoteService.GetQuotesByAuthors")
+	defer func() { endSpan(span, err) }()
+
+	if limit <= 0 || limit > 100 {
+		limit = 100
+	}
+
+	dbCtx, cancel := context.WithTimeout(ctx, s.timeouts.Load().List)
+	defer cancel()
+
+	quotes, err := s.repo.GetByAuthors(dbCtx, authors, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get quotes by authors: %w", timeoutError(dbCtx, err))
+	}
+
+	result := make(map[string][]*domain.Quote, len(authors))
+	for _, quote := range quotes {
+		result[quote.Author] = append(result[quote.Author], quote)
+	}
+	return result, nil
+}
+
+func (s *QuoteService) GetRandomQuote(ctx context.Context) (_ *domain.Quote, err error) {
+	ctx, span := startSpan(ctx, "QuoteService.GetRandomQuote")
+	defer func() { endSpan(span, err) }()
+
+	dbCtx, cancel := context.WithTimeout(ctx, s.timeouts.Load().Random)
 	defer cancel()
 
 	quote, err := s.repo.GetRandom(dbCtx)
 	if err != nil {
-		s.logger.Error("Failed to get random quote", "error", err)
-		return nil, fmt.Errorf("failed to get random quote: %w", err)
+		s.countNotFound("random", err)
+		s.logger.ErrorContext(ctx, "Failed to get random quote", "error", err)
+		return nil, fmt.Errorf("failed to get random quote: %w", timeoutError(dbCtx, err))
 	}
+	s.metrics.RandomQuoteServed()
 
-	s.logger.Debug("Retrieved random quote", "id", quote.ID, "author", quote.Author)
+	s.logger.DebugContext(ctx, "Retrieved random quote", "id", quote.ID, "author", quote.Author)
 	return quote, nil
 }
 
-func (s *QuoteService) DeleteQuote(ctx context.Context, id int) error {
+func (s *QuoteService) UpdateQuote(ctx context.Context, id int, req domain.UpdateQuoteRequest) (_ *domain.Quote, err error) {
+	ctx, span := startSpan(ctx, "QuoteService.UpdateQuote")
+	defer func() { endSpan(span, err) }()
+
+	if id <= 0 {
+		return nil, fmt.Errorf("%w: invalid quote ID", domain.ErrInvalidQuote)
+	}
+	if err := req.Validate(); err != nil {
+		s.logger.DebugContext(ctx, "Invalid quote request", "error", err, "request", req)
+		return nil, fmt.Errorf("%w: %s", domain.ErrInvalidQuote, err.Error())
+	}
+
+	dbCtx, cancel := context.WithTimeout(ctx, s.timeouts.Load().Update)
+	defer cancel()
+
+	var (
+		updatedQuote *domain.Quote
+		event        *domain.Event
+	)
+	err = s.tx.WithinTransaction(dbCtx, func(ctx context.Context) error {
+		current, err := s.repo.GetByID(ctx, id)
+		if err != nil {
+			return err
+		}
+		if err := authorizeChange(ctx, current); err != nil {
+			return err
+		}
+
+		updatedQuote, err = s.repo.Update(ctx, &domain.Quote{ID: id, Author: req.Author, Text: req.Quote, Tags: req.Tags})
+		if err != nil {
+			return err
+		}
+		event = newEvent(ctx, domain.EventQuoteUpdated, updatedQuote.ID, updatedQuote)
+		return s.emit(ctx, event)
+	})
+	if err != nil {
+		s.countNotFound("update", err)
+		s.logger.ErrorContext(ctx, "Failed to update quote", "id", id, "error", err)
+		return nil, fmt.Errorf("failed to update quote: %w", timeoutError(dbCtx, err))
+	}
+	s.invalidate(ctx, id)
+	s.notify(event)
+
+	s.logger.InfoContext(ctx, "Quote updated successfully", "id", updatedQuote.ID, "author", updatedQuote.Author, "actor", event.Actor)
+	return updatedQuote, nil
+}
+
+func (s *QuoteService) DeleteQuote(ctx context.Context, id int) (err error) {
+	ctx, span := startSpan(ctx, "QuoteService.DeleteQuote")
+	defer func() { endSpan(span, err) }()
+
 	if id <= 0 {
 		return fmt.Errorf("%w: invalid quote ID", domain.ErrInvalidQuote)
 	}
 
-	dbCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	dbCtx, cancel := context.WithTimeout(ctx, s.timeouts.Load().Delete)
 	defer cancel()
 
-	err := s.repo.Delete(dbCtx, id)
+	var event *domain.Event
+	err = s.tx.WithinTransaction(dbCtx, func(ctx context.Context) error {
+		// Удаленная цитата попадает в событие, чтобы получатели могли фильтровать
+		quote, err := s.repo.GetByID(ctx, id)
+		if err != nil {
+			return err
+		}
+		if err := authorizeChange(ctx, quote); err != nil {
+			return err
+		}
+		if err := s.repo.Delete(ctx, id); err != nil {
+			return err
+		}
+		event = newEvent(ctx, domain.EventQuoteDeleted, id, quote)
+		return s.emit(ctx, event)
+	})
 	if err != nil {
-		s.logger.Error("Failed to delete quote", "id", id, "error", err)
-		return fmt.Errorf("failed to delete quote: %w", err)
+		s.countNotFound("delete", err)
+		s.logger.ErrorContext(ctx, "Failed to delete quote", "id", id, "error", err)
+		return fmt.Errorf("failed to delete quote: %w", timeoutError(dbCtx, err))
 	}
 
-	s.logger.Info("Quote deleted successfully", "id", id)
+	s.invalidate(ctx, id)
+	s.notify(event)
+	s.metrics.QuoteDeleted()
+
+	s.logger.InfoContext(ctx, "Quote deleted successfully", "id", id, "actor", event.Actor)
 	return nil
 }
 
 func (s *QuoteService) HealthCheck(ctx context.Context) error {
-	dbCtx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	dbCtx, cancel := context.WithTimeout(ctx, s.timeouts.Load().Health)
 	defer cancel()
 
 	return s.repo.HealthCheck(dbCtx)
 }
+
+// SetTimeouts меняет дедлайны на лету; операции в работе сохраняют прежние
+func (s *QuoteService) SetTimeouts(timeouts Timeouts) {
+	s.timeouts.Store(&timeouts)
+}
+
+// CacheStats возвращает статистику кэша, если репозиторий обернут кэшем
+func (s *QuoteService) CacheStats() (domain.CacheStats, bool) {
+	provider, ok := s.repo.(domain.CacheStatsProvider)
+	if !ok {
+		return domain.CacheStats{}, false
+	}
+	return provider.CacheStats(), true
+}
+
+// authorizeChange пропускает изменение чужой цитаты только с правом
+// modify_any. Без автора в контексте (аутентификация выключена) проверки нет
+func authorizeChange(ctx context.Context, quote *domain.Quote) error {
+	principal, ok := domain.PrincipalFromContext(ctx)
+	if !ok || principal.CanModify(quote) {
+		return nil
+	}
+	return fmt.Errorf("%w: quote %d", domain.ErrQuoteForbidden, quote.ID)
+}
+
+// resolveCreatedBy подставляет автора запроса вместо created_by=me
+func resolveCreatedBy(ctx context.Context, filter *domain.QuoteFilter) error {
+	if filter.CreatedBy != domain.CreatedByMe {
+		return nil
+	}
+
+	actor := domain.ActorFromContext(ctx)
+	if actor == "" {
+		return fmt.Errorf("%w: created_by=me requires authentication", domain.ErrUnauthenticated)
+	}
+	filter.CreatedBy = actor
+	return nil
+}
+
+// newEvent фиксирует в событии, кто выполнил изменение
+func newEvent(ctx context.Context, eventType domain.EventType, quoteID int, quote *domain.Quote) *domain.Event {
+	event := domain.NewQuoteEvent(eventType, quoteID, quote)
+	event.Actor = domain.ActorFromContext(ctx)
+	return event
+}
+
+func (s *QuoteService) emit(ctx context.Context, events ...*domain.Event) error {
+	if s.outbox == nil {
+		return nil
+	}
+	return s.outbox.Append(ctx, events...)
+}
+
+// invalidate сбрасывает кэш после коммита, если репозиторий обернут кэшем
+func (s *QuoteService) invalidate(ctx context.Context, ids ...int) {
+	if invalidator, ok := s.repo.(domain.CacheInvalidator); ok {
+		invalidator.Invalidate(ctx, ids...)
+	}
+}
+
+func (s *QuoteService) notify(event *domain.Event) {
+	for _, listener := range s.listeners {
+		listener.OnEvent(event)
+	}
+}
+
+func (s *QuoteService) countNotFound(operation string, err error) {
+	if errors.Is(err, domain.ErrQuoteNotFound) {
+		s.metrics.QuoteNotFound(operation)
+	}
+}
+
+// timeoutError помечает ошибку истекшим дедлайном: драйвер БД при отмене
+// запроса возвращает собственную ошибку (pq: canceling statement), а
+// обработчикам нужно отличать тайм-аут от сбоя
+func timeoutError(ctx context.Context, err error) error {
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) && !errors.Is(err, context.DeadlineExceeded) {
+		return fmt.Errorf("%w: %w", context.DeadlineExceeded, err)
+	}
+	return err
+}
+
+// noTransaction используется, когда outbox не подключен
+type noTransaction struct{}
+
+func (noTransaction) WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	return fn(ctx)
+}
+
+// noMetrics используется, когда метрики не подключены
+type noMetrics struct{}
+
+func (noMetrics) QuoteCreated()        {}
+func (noMetrics) QuoteDeleted()        {}
+func (noMetrics) RandomQuoteServed()   {}
+func (noMetrics) QuoteNotFound(string) {}
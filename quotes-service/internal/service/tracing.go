@@ -0,0 +1,38 @@
+package service
+
+import (
+	"context"
+	"errors"
+
+	"quotes-service/internal/domain"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+var tracer = otel.Tracer("quotes-service/internal/service")
+
+func startSpan(ctx context.Context, name string) (context.Context, trace.Span) {
+	return tracer.Start(ctx, name)
+}
+
+// endSpan завершает span операции. Ожидаемые ошибки клиента (не найдено,
+// невалидный запрос, нет прав) записываются событием и не помечают span
+// ошибкой, чтобы сбои было видно по статусу
+func endSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		if !isClientError(err) {
+			span.SetStatus(codes.Error, err.Error())
+		}
+	}
+	span.End()
+}
+
+func isClientError(err error) bool {
+	return errors.Is(err, domain.ErrQuoteNotFound) ||
+		errors.Is(err, domain.ErrInvalidQuote) ||
+		errors.Is(err, domain.ErrQuoteForbidden) ||
+		errors.Is(err, domain.ErrUnauthenticated)
+}
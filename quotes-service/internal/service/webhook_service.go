@@ -0,0 +1,161 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"time"
+
+	"quotes-service/internal/domain"
+	"quotes-service/internal/infrastructure/logger"
+)
+
+type WebhookService struct {
+	repo   domain.WebhookRepository
+	logger *logger.Logger
+}
+
+func NewWebhookService(repo domain.WebhookRepository, logger *logger.Logger) *WebhookService {
+	return &WebhookService{
+		repo:   repo,
+		logger: logger,
+	}
+}
+
+// CreateSubscription возвращает подписку с секретом; позже секрет не отдается
+func (s *WebhookService) CreateSubscription(ctx context.Context, req domain.WebhookRequest) (*domain.WebhookSubscription, error) {
+	if err := req.Validate(); err != nil {
+		return nil, fmt.Errorf("%w: %s", domain.ErrInvalidWebhook, err.Error())
+	}
+
+	secret := req.Secret
+	if secret == "" {
+		var err error
+		if secret, err = generateSecret(); err != nil {
+			return nil, err
+		}
+	}
+
+	sub := &domain.WebhookSubscription{
+		URL:        req.URL,
+		Secret:     secret,
+		EventTypes: req.Events,
+		Active:     req.Active == nil || *req.Active,
+	}
+
+	dbCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	created, err := s.repo.CreateSubscription(dbCtx, sub)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create webhook subscription: %w", err)
+	}
+
+	return created, nil
+}
+
+func (s *WebhookService) GetSubscription(ctx context.Context, id int) (*domain.WebhookSubscription, error) {
+	dbCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	return s.repo.GetSubscription(dbCtx, id)
+}
+
+func (s *WebhookService) ListSubscriptions(ctx context.Context) ([]*domain.WebhookSubscription, error) {
+	dbCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	subs, err := s.repo.ListSubscriptions(dbCtx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list webhook subscriptions: %w", err)
+	}
+
+	return subs, nil
+}
+
+// UpdateSubscription заменяет поля подписки; пустой секрет сохраняет прежний
+func (s *WebhookService) UpdateSubscription(ctx context.Context, id int, req domain.WebhookRequest) (*domain.WebhookSubscription, error) {
+	if err := req.Validate(); err != nil {
+		return nil, fmt.Errorf("%w: %s", domain.ErrInvalidWebhook, err.Error())
+	}
+
+	dbCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	sub, err := s.repo.GetSubscription(dbCtx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	sub.URL = req.URL
+	sub.EventTypes = req.Events
+	if req.Secret != "" {
+		sub.Secret = req.Secret
+	}
+	if req.Active != nil {
+		sub.Active = *req.Active
+	}
+
+	updated, err := s.repo.UpdateSubscription(dbCtx, sub)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update webhook subscription: %w", err)
+	}
+
+	s.logger.InfoContext(ctx, "Webhook subscription updated", "id", id)
+	return updated, nil
+}
+
+func (s *WebhookService) DeleteSubscription(ctx context.Context, id int) error {
+	dbCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	if err := s.repo.DeleteSubscription(dbCtx, id); err != nil {
+		return fmt.Errorf("failed to delete webhook subscription: %w", err)
+	}
+
+	return nil
+}
+
+func (s *WebhookService) ListDeliveries(ctx context.Context, filter domain.DeliveryFilter) ([]*domain.WebhookDelivery, error) {
+	if filter.Limit <= 0 || filter.Limit > 500 {
+		filter.Limit = 100
+	}
+
+	dbCtx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	if filter.SubscriptionID > 0 {
+		if _, err := s.repo.GetSubscription(dbCtx, filter.SubscriptionID); err != nil {
+			return nil, err
+		}
+	}
+
+	deliveries, err := s.repo.ListDeliveries(dbCtx, filter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list webhook deliveries: %w", err)
+	}
+
+	return deliveries, nil
+}
+
+// Redeliver возвращает доставку из dead-letter в очередь
+func (s *WebhookService) Redeliver(ctx context.Context, id int64) (*domain.WebhookDelivery, error) {
+	dbCtx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	delivery, err := s.repo.RequeueDelivery(dbCtx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to requeue webhook delivery: %w", err)
+	}
+
+	return delivery, nil
+}
+
+func generateSecret() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("failed to generate webhook secret: %w", err)
+	}
+	return hex.EncodeToString(buf), nil
+}
@@ -0,0 +1,193 @@
+package stream
+
+import (
+	"strings"
+	"sync"
+
+	"quotes-service/internal/domain"
+)
+
+// Message - событие с порядковым номером потока. Номер монотонен в пределах
+// процесса и используется как SSE id для возобновления по Last-Event-ID.
+type Message struct {
+	ID    uint64
+	Event *domain.Event
+}
+
+type Filter struct {
+	Author string
+	Tag    string
+	Types  []domain.EventType
+}
+
+// Matches: author - подстрока без учета регистра (как в GET /quotes),
+// tag - точное совпадение с одним из тегов цитаты
+func (f Filter) Matches(event *domain.Event) bool {
+	if len(f.Types) > 0 {
+		matched := false
+		for _, t := range f.Types {
+			if t == event.Type {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return false
+		}
+	}
+
+	if f.Author == "" && f.Tag == "" {
+		return true
+	}
+	if event.Quote == nil {
+		return false
+	}
+
+	if f.Author != "" && !strings.Contains(strings.ToLower(event.Quote.Author), strings.ToLower(f.Author)) {
+		return false
+	}
+
+	if f.Tag != "" {
+		tag := strings.ToLower(f.Tag)
+		for _, t := range event.Quote.Tags {
+			if t == tag {
+				return true
+			}
+		}
+		return false
+	}
+
+	return true
+}
+
+// Subscription получает сообщения в буферизованный канал. Если подписчик
+// не успевает читать и буфер переполнен, канал закрывается: клиент
+// переподключается и догоняет пропущенное из буфера повтора.
+type Subscription struct {
+	C      <-chan Message
+	ch     chan Message
+	filter Filter
+	closed bool
+}
+
+// Broker рассылает события подписчикам и хранит последние события
+// в кольцевом буфере для повтора
+type Broker struct {
+	mu         sync.Mutex
+	replay     []Message
+	replaySize int
+	bufferSize int
+	lastID     uint64
+	subs       map[*Subscription]struct{}
+}
+
+func NewBroker(replaySize, bufferSize int) *Broker {
+	if replaySize < 0 {
+		replaySize = 0
+	}
+	if bufferSize <= 0 {
+		bufferSize = 1
+	}
+	return &Broker{
+		replaySize: replaySize,
+		bufferSize: bufferSize,
+		subs:       make(map[*Subscription]struct{}),
+	}
+}
+
+// OnEvent реализует domain.EventListener
+func (b *Broker) OnEvent(event *domain.Event) {
+	b.Publish(event)
+}
+
+func (b *Broker) Publish(event *domain.Event) uint64 {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.lastID++
+	msg := Message{ID: b.lastID, Event: event}
+
+	if b.replaySize > 0 {
+		if len(b.replay) == b.replaySize {
+			copy(b.replay, b.replay[1:])
+			b.replay = b.replay[:len(b.replay)-1]
+		}
+		b.replay = append(b.replay, msg)
+	}
+
+	for sub := range b.subs {
+		if !sub.filter.Matches(event) {
+			continue
+		}
+		select {
+		case sub.ch <- msg:
+		default:
+			b.closeLocked(sub)
+		}
+	}
+
+	return msg.ID
+}
+
+// Subscribe регистрирует подписчика и возвращает сообщения после lastID
+// из буфера повтора. complete=false, если часть событий уже вытеснена.
+func (b *Broker) Subscribe(lastID uint64, filter Filter) (sub *Subscription, missed []Message, complete bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	complete = true
+	// Номер из будущего - клиент видел поток до перезапуска сервиса
+	if lastID > b.lastID {
+		lastID = 0
+		complete = false
+	}
+	if lastID > 0 {
+		if len(b.replay) > 0 && b.replay[0].ID > lastID+1 {
+			complete = false
+		}
+		if len(b.replay) == 0 && b.lastID > lastID {
+			complete = false
+		}
+		for _, msg := range b.replay {
+			if msg.ID > lastID && filter.Matches(msg.Event) {
+				missed = append(missed, msg)
+			}
+		}
+	}
+
+	ch := make(chan Message, b.bufferSize)
+	sub = &Subscription{C: ch, ch: ch, filter: filter}
+	b.subs[sub] = struct{}{}
+
+	return sub, missed, complete
+}
+
+func (b *Broker) Unsubscribe(sub *Subscription) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.closeLocked(sub)
+}
+
+func (b *Broker) LastID() uint64 {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.lastID
+}
+
+func (b *Broker) Subscribers() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return len(b.subs)
+}
+
+func (b *Broker) closeLocked(sub *Subscription) {
+	if sub.closed {
+		return
+	}
+	sub.closed = true
+	delete(b.subs, sub)
+	close(sub.ch)
+}
@@ -0,0 +1,201 @@
+package tlsconfig
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"os"
+	"sync/atomic"
+	"time"
+
+	"quotes-service/internal/infrastructure/logger"
+)
+
+// Проверка клиентских сертификатов (mTLS)
+const (
+	ClientAuthNone     = "none"
+	ClientAuthOptional = "optional"
+	ClientAuthRequire  = "require"
+)
+
+// Config: пустой CertFile - сервер работает без TLS. ClientCAFile - бандл CA,
+// которым проверяются клиентские сертификаты при ClientAuth optional/require
+type Config struct {
+	CertFile     string
+	KeyFile      string
+	ClientCAFile string
+	ClientAuth   string
+	MinVersion   string
+}
+
+func (c Config) Enabled() bool {
+	return c.CertFile != ""
+}
+
+// ParseMinVersion разбирает минимальную версию протокола: 1.2 или 1.3
+func ParseMinVersion(version string) (uint16, error) {
+	switch version {
+	case "1.2":
+		return tls.VersionTLS12, nil
+	case "1.3":
+		return tls.VersionTLS13, nil
+	}
+	return 0, fmt.Errorf("unsupported TLS version %q", version)
+}
+
+// Certificates хранит сертификат сервера и бандл CA клиентов. Новые
+// соединения получают текущую пару через GetConfigForClient, поэтому
+// ротация файлов не требует перезапуска, а открытые соединения не рвутся
+type Certificates struct {
+	config Config
+	logger *logger.Logger
+	state  atomic.Pointer[certificatesState]
+}
+
+type certificatesState struct {
+	config *tls.Config
+	// files - содержимое cert, key и CA, из которого собран config
+	files [][]byte
+}
+
+// Load читает файлы сразу, чтобы ошибка конфигурации была видна при старте
+func Load(config Config, logger *logger.Logger) (*Certificates, error) {
+	c := &Certificates{
+		config: config,
+		logger: logger,
+	}
+	if _, err := c.Refresh(); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// Refresh перечитывает файлы и применяет их, если содержимое изменилось.
+// При ошибке (например, ключ еще не дописан) остается прежняя пара
+func (c *Certificates) Refresh() (bool, error) {
+	files, err := c.readFiles()
+	if err != nil {
+		return false, err
+	}
+	if current := c.state.Load(); current != nil && sameFiles(current.files, files) {
+		return false, nil
+	}
+
+	config, err := c.build(files)
+	if err != nil {
+		return false, err
+	}
+	c.state.Store(&certificatesState{config: config, files: files})
+
+	leaf := config.Certificates[0].Leaf
+	c.logger.Info("TLS certificate loaded",
+		"subject", leaf.Subject.String(),
+		"not_after", leaf.NotAfter.Format(time.RFC3339),
+		"client_auth", c.config.ClientAuth,
+	)
+	return true, nil
+}
+
+// Run периодически проверяет файлы, чтобы подхватывать ротацию сертификатов
+// (cert-manager, обновление секрета Kubernetes)
+func (c *Certificates) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if _, err := c.Refresh(); err != nil {
+				c.logger.Warn("TLS certificate reload failed, keeping previous certificate", "error", err)
+			}
+		}
+	}
+}
+
+// TLSConfig - конфигурация для http.Server; с HTTP/2 (ALPN h2)
+func (c *Certificates) TLSConfig() *tls.Config {
+	return &tls.Config{
+		NextProtos: []string{"h2", "http/1.1"},
+		GetCertificate: func(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+			return &c.state.Load().config.Certificates[0], nil
+		},
+		GetConfigForClient: func(*tls.ClientHelloInfo) (*tls.Config, error) {
+			return c.state.Load().config, nil
+		},
+	}
+}
+
+func (c *Certificates) readFiles() ([][]byte, error) {
+	paths := []string{c.config.CertFile, c.config.KeyFile}
+	if c.config.ClientCAFile != "" {
+		paths = append(paths, c.config.ClientCAFile)
+	}
+
+	files := make([][]byte, 0, len(paths))
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("read TLS file: %w", err)
+		}
+		files = append(files, data)
+	}
+	return files, nil
+}
+
+func (c *Certificates) build(files [][]byte) (*tls.Config, error) {
+	certificate, err := tls.X509KeyPair(files[0], files[1])
+	if err != nil {
+		return nil, fmt.Errorf("load TLS key pair %s, %s: %w", c.config.CertFile, c.config.KeyFile, err)
+	}
+	if certificate.Leaf == nil {
+		if certificate.Leaf, err = x509.ParseCertificate(certificate.Certificate[0]); err != nil {
+			return nil, fmt.Errorf("parse TLS certificate %s: %w", c.config.CertFile, err)
+		}
+	}
+
+	minVersion, err := ParseMinVersion(c.config.MinVersion)
+	if err != nil {
+		return nil, err
+	}
+	config := &tls.Config{
+		Certificates: []tls.Certificate{certificate},
+		MinVersion:   minVersion,
+		NextProtos:   []string{"h2", "http/1.1"},
+	}
+
+	switch c.config.ClientAuth {
+	case ClientAuthNone, "":
+		return config, nil
+	case ClientAuthOptional:
+		config.ClientAuth = tls.VerifyClientCertIfGiven
+	case ClientAuthRequire:
+		config.ClientAuth = tls.RequireAndVerifyClientCert
+	default:
+		return nil, fmt.Errorf("unknown client auth mode %q", c.config.ClientAuth)
+	}
+
+	if len(files) < 3 {
+		return nil, fmt.Errorf("client CA file is required for client auth %q", c.config.ClientAuth)
+	}
+	config.ClientCAs = x509.NewCertPool()
+	if !config.ClientCAs.AppendCertsFromPEM(files[2]) {
+		return nil, fmt.Errorf("no certificates found in client CA file %s", c.config.ClientCAFile)
+	}
+	return config, nil
+}
+
+func sameFiles(a, b [][]byte) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if !bytes.Equal(a[i], b[i]) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,230 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"quotes-service/internal/domain"
+	"quotes-service/internal/infrastructure/logger"
+	"quotes-service/internal/outbox"
+)
+
+type Config struct {
+	PollInterval time.Duration
+	BatchSize    int
+	Timeout      time.Duration
+	MaxAttempts  int
+	MinBackoff   time.Duration
+	MaxBackoff   time.Duration
+}
+
+// Dispatcher принимает события от outbox-релея, ставит доставки в очередь
+// по подпискам и отправляет их подписанными POST-запросами с повторами.
+// Исчерпавшие попытки доставки получают статус dead (dead-letter).
+type Dispatcher struct {
+	repo   domain.WebhookRepository
+	client *http.Client
+	config Config
+	logger *logger.Logger
+}
+
+func NewDispatcher(repo domain.WebhookRepository, config Config, logger *logger.Logger) *Dispatcher {
+	if config.PollInterval <= 0 {
+		config.PollInterval = time.Second
+	}
+	if config.BatchSize <= 0 {
+		config.BatchSize = 50
+	}
+	if config.Timeout <= 0 {
+		config.Timeout = 10 * time.Second
+	}
+	if config.MaxAttempts <= 0 {
+		config.MaxAttempts = 8
+	}
+	if config.MinBackoff <= 0 {
+		config.MinBackoff = 5 * time.Second
+	}
+	if config.MaxBackoff < config.MinBackoff {
+		config.MaxBackoff = config.MinBackoff
+	}
+
+	return &Dispatcher{
+		repo:   repo,
+		client: &http.Client{Timeout: config.Timeout},
+		config: config,
+		logger: logger,
+	}
+}
+
+func (d *Dispatcher) Name() string {
+	return "webhooks"
+}
+
+// Publish реализует outbox.Sink: событие превращается в доставки для
+// каждой подходящей подписки
+func (d *Dispatcher) Publish(ctx context.Context, event *domain.Event) error {
+	subs, err := d.repo.ListSubscriptions(ctx)
+	if err != nil {
+		return err
+	}
+
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to encode event: %w", err)
+	}
+
+	var deliveries []*domain.WebhookDelivery
+	for _, sub := range subs {
+		if !sub.Matches(event.Type) {
+			continue
+		}
+		deliveries = append(deliveries, &domain.WebhookDelivery{
+			SubscriptionID: sub.ID,
+			EventID:        event.ID,
+			EventType:      event.Type,
+			Payload:        payload,
+		})
+	}
+
+	if len(deliveries) == 0 {
+		return nil
+	}
+
+	return d.repo.EnqueueDeliveries(ctx, deliveries...)
+}
+
+// Run отправляет доставки до отмены контекста
+func (d *Dispatcher) Run(ctx context.Context) {
+	ticker := time.NewTicker(d.config.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		if err := d.RunOnce(ctx); err != nil && ctx.Err() == nil {
+			d.logger.Error("Webhook delivery batch failed", "error", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (d *Dispatcher) RunOnce(ctx context.Context) error {
+	deliveries, err := d.repo.ClaimDueDeliveries(ctx, d.config.BatchSize, d.claimLease())
+	if err != nil {
+		return err
+	}
+
+	subs := make(map[int]*domain.WebhookSubscription)
+	for _, delivery := range deliveries {
+		sub, ok := subs[delivery.SubscriptionID]
+		if !ok {
+			sub, err = d.repo.GetSubscription(ctx, delivery.SubscriptionID)
+			if err != nil && !errors.Is(err, domain.ErrWebhookNotFound) {
+				return err
+			}
+			subs[delivery.SubscriptionID] = sub
+		}
+
+		d.deliver(ctx, delivery, sub)
+
+		if err := d.repo.SaveDeliveryResult(ctx, delivery); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// claimLease покрывает весь батч: доставки отправляются по очереди, каждая
+// до Timeout, и доставка с истекшей арендой была бы захвачена повторно и
+// отправлена подписчику дважды. Лишний Timeout - запас на запись результатов
+func (d *Dispatcher) claimLease() time.Duration {
+	return time.Duration(d.config.BatchSize+1) * d.config.Timeout
+}
+
+func (d *Dispatcher) deliver(ctx context.Context, delivery *domain.WebhookDelivery, sub *domain.WebhookSubscription) {
+	now := time.Now()
+	delivery.Attempts++
+	delivery.LastAttemptAt = &now
+	delivery.ResponseStatus = 0
+
+	var err error
+	switch {
+	case sub == nil:
+		err = domain.ErrWebhookNotFound
+	case !sub.Active:
+		err = errors.New("subscription is inactive")
+	default:
+		delivery.ResponseStatus, err = d.send(ctx, delivery, sub)
+	}
+
+	if err == nil {
+		delivery.Status = domain.DeliveryDelivered
+		delivery.LastError = ""
+		delivery.DeliveredAt = &now
+		d.logger.Debug("Webhook delivered", "delivery_id", delivery.ID, "subscription_id", delivery.SubscriptionID)
+		return
+	}
+
+	delivery.LastError = err.Error()
+
+	if sub == nil || !sub.Active || delivery.Attempts >= d.config.MaxAttempts {
+		delivery.Status = domain.DeliveryDead
+		d.logger.Warn("Webhook delivery moved to dead letters",
+			"delivery_id", delivery.ID,
+			"subscription_id", delivery.SubscriptionID,
+			"attempts", delivery.Attempts,
+			"error", err,
+		)
+		return
+	}
+
+	delivery.Status = domain.DeliveryPending
+	delivery.NextAttemptAt = now.Add(outbox.Backoff(delivery.Attempts-1, d.config.MinBackoff, d.config.MaxBackoff))
+	d.logger.Info("Webhook delivery failed, will retry",
+		"delivery_id", delivery.ID,
+		"subscription_id", delivery.SubscriptionID,
+		"attempts", delivery.Attempts,
+		"next_attempt_at", delivery.NextAttemptAt,
+		"error", err,
+	)
+}
+
+func (d *Dispatcher) send(ctx context.Context, delivery *domain.WebhookDelivery, sub *domain.WebhookSubscription) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, sub.URL, bytes.NewReader(delivery.Payload))
+	if err != nil {
+		return 0, fmt.Errorf("failed to build request: %w", err)
+	}
+
+	timestamp := time.Now()
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "quotes-service-webhooks")
+	req.Header.Set("X-Webhook-ID", strconv.FormatInt(delivery.ID, 10))
+	req.Header.Set("X-Event-ID", strconv.FormatInt(delivery.EventID, 10))
+	req.Header.Set("X-Event-Type", string(delivery.EventType))
+	req.Header.Set(TimestampHeader, strconv.FormatInt(timestamp.Unix(), 10))
+	req.Header.Set(SignatureHeader, Sign(sub.Secret, timestamp, delivery.Payload))
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return 0, fmt.Errorf("failed to send webhook: %w", err)
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return resp.StatusCode, fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+
+	return resp.StatusCode, nil
+}
@@ -0,0 +1,41 @@
+package webhook
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	SignatureHeader = "X-Webhook-Signature"
+	TimestampHeader = "X-Webhook-Timestamp"
+)
+
+// Sign подписывает "<timestamp>.<body>" секретом подписки. Метка времени
+// входит в подпись, чтобы получатель мог отвергать повторы старых запросов.
+func Sign(secret string, timestamp time.Time, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp.Unix(), 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify - проверка подписи на стороне получателя
+func Verify(secret, signature, timestamp string, body []byte, tolerance time.Duration) bool {
+	unix, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return false
+	}
+
+	signedAt := time.Unix(unix, 0)
+	if tolerance > 0 && (time.Since(signedAt) > tolerance || time.Until(signedAt) > tolerance) {
+		return false
+	}
+
+	expected := Sign(secret, signedAt, body)
+	return hmac.Equal([]byte(expected), []byte(strings.TrimSpace(signature)))
+}
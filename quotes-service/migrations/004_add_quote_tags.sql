@@ -0,0 +1,4 @@
+ALTER TABLE quotes ADD COLUMN IF NOT EXISTS tags TEXT[] NOT NULL DEFAULT '{}';
+
+-- Индексация для фильтра по тегу
+CREATE INDEX IF NOT EXISTS idx_quotes_tags ON quotes USING GIN (tags);
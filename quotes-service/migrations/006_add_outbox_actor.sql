@@ -0,0 +1,2 @@
+-- Кто выполнил изменение: субъект API-ключа или JWT
+ALTER TABLE outbox_events ADD COLUMN IF NOT EXISTS actor VARCHAR(255);
@@ -0,0 +1,4 @@
+-- Владелец цитаты: субъект API-ключа или JWT; NULL у цитат, созданных до аутентификации
+ALTER TABLE quotes ADD COLUMN IF NOT EXISTS created_by VARCHAR(255);
+
+CREATE INDEX IF NOT EXISTS idx_quotes_created_by ON quotes(created_by);
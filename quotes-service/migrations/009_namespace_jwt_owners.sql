@@ -0,0 +1,8 @@
+-- Субъекты JWT получают префикс jwt:, чтобы sub из IdP не совпал с api-key:<id>
+-- или cert:<cn>. Владельцы, записанные до этого, переводятся в новый формат
+UPDATE quotes
+SET created_by = 'jwt:' || created_by
+WHERE created_by IS NOT NULL
+  AND created_by NOT LIKE 'api-key:%'
+  AND created_by NOT LIKE 'cert:%'
+  AND created_by NOT LIKE 'jwt:%';
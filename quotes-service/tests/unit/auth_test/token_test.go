@@ -0,0 +1,154 @@
+package auth_test
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"quotes-service/internal/auth"
+	"quotes-service/internal/domain"
+	"quotes-service/internal/infrastructure/logger"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const issuer = "https://sso.example.com"
+
+func writeJWKS(t *testing.T, key *rsa.PrivateKey, kid string) string {
+	t.Helper()
+
+	jwks, _ := json.Marshal(map[string]interface{}{"keys": []map[string]string{{
+		"kty": "RSA",
+		"kid": kid,
+		"use": "sig",
+		"n":   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+		"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+	}}})
+	path := filepath.Join(t.TempDir(), "jwks.json")
+	if err := os.WriteFile(path, jwks, 0o600); err != nil {
+		t.Fatalf("Failed to write JWKS: %v", err)
+	}
+	return path
+}
+
+func newVerifier(t *testing.T, config auth.Config) (*auth.TokenVerifier, *rsa.PrivateKey) {
+	t.Helper()
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("Failed to generate key: %v", err)
+	}
+	keys, err := auth.LoadKeySet(context.Background(), "file://"+writeJWKS(t, key, "k1"), logger.New("error"))
+	if err != nil {
+		t.Fatalf("Failed to load JWKS: %v", err)
+	}
+	return auth.NewTokenVerifier(keys, config), key
+}
+
+func sign(t *testing.T, key interface{}, method jwt.SigningMethod, kid string, claims jwt.MapClaims) string {
+	t.Helper()
+
+	token := jwt.NewWithClaims(method, claims)
+	token.Header["kid"] = kid
+	signed, err := token.SignedString(key)
+	if err != nil {
+		t.Fatalf("Failed to sign token: %v", err)
+	}
+	return signed
+}
+
+func claims(extra jwt.MapClaims) jwt.MapClaims {
+	claims := jwt.MapClaims{
+		"iss": issuer,
+		"aud": "quotes",
+		"sub": "42",
+		"exp": time.Now().Add(time.Hour).Unix(),
+	}
+	// nil удаляет стандартный claim
+	for name, value := range extra {
+		if value == nil {
+			delete(claims, name)
+			continue
+		}
+		claims[name] = value
+	}
+	return claims
+}
+
+func TestVerify_MapsClaimsToPrincipal(t *testing.T) {
+	verifier, key := newVerifier(t, auth.Config{
+		Issuer:      issuer,
+		Audience:    "quotes",
+		RolesClaim:  "realm_access.roles",
+		RoleMapping: map[string]domain.Role{"quotes-writers": domain.RoleEditor},
+	})
+
+	token := sign(t, key, jwt.SigningMethodRS256, "k1", claims(jwt.MapClaims{
+		"email":        "ada@example.com",
+		"realm_access": map[string]interface{}{"roles": []string{"offline_access", "quotes-writers", "viewer"}},
+	}))
+	principal, err := verifier.Verify(context.Background(), token)
+	if err != nil {
+		t.Fatalf("Expected valid token, got %v", err)
+	}
+
+	if principal.Subject != "jwt:42" || principal.Name != "ada@example.com" || principal.Method != domain.AuthMethodJWT {
+		t.Errorf("Unexpected principal: %+v", principal)
+	}
+	if len(principal.Roles) != 2 || !principal.Can(domain.PermissionUpdate) || principal.Can(domain.PermissionModifyAny) {
+		t.Errorf("Expected editor and viewer roles, got %v", principal.Roles)
+	}
+}
+
+func TestVerify_RejectsInvalidTokens(t *testing.T) {
+	verifier, key := newVerifier(t, auth.Config{Issuer: issuer, Audience: "quotes", Leeway: time.Second})
+	otherKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+
+	cases := map[string]string{
+		"expired":      sign(t, key, jwt.SigningMethodRS256, "k1", claims(jwt.MapClaims{"exp": time.Now().Add(-time.Minute).Unix()})),
+		"no expiry":    sign(t, key, jwt.SigningMethodRS256, "k1", claims(jwt.MapClaims{"exp": nil})),
+		"wrong issuer": sign(t, key, jwt.SigningMethodRS256, "k1", claims(jwt.MapClaims{"iss": "https://evil.example.com"})),
+		"wrong aud":    sign(t, key, jwt.SigningMethodRS256, "k1", claims(jwt.MapClaims{"aud": "billing"})),
+		"no subject":   sign(t, key, jwt.SigningMethodRS256, "k1", claims(jwt.MapClaims{"sub": ""})),
+		"unknown kid":  sign(t, otherKey, jwt.SigningMethodRS256, "k2", claims(nil)),
+		"foreign key":  sign(t, otherKey, jwt.SigningMethodRS256, "k1", claims(nil)),
+		"hmac":         sign(t, []byte("secret"), jwt.SigningMethodHS256, "k1", claims(nil)),
+		"not a jwt":    "a.b.c",
+	}
+	for name, token := range cases {
+		if _, err := verifier.Verify(context.Background(), token); !errors.Is(err, domain.ErrUnauthenticated) {
+			t.Errorf("%s: expected ErrUnauthenticated, got %v", name, err)
+		}
+	}
+}
+
+func TestKeySet_PicksUpRotatedKeys(t *testing.T) {
+	key, _ := rsa.GenerateKey(rand.Reader, 2048)
+	path := writeJWKS(t, key, "k1")
+	keys, err := auth.LoadKeySet(context.Background(), path, logger.New("error"))
+	if err != nil {
+		t.Fatalf("Failed to load JWKS: %v", err)
+	}
+
+	rotated, _ := rsa.GenerateKey(rand.Reader, 2048)
+	data, _ := os.ReadFile(writeJWKS(t, rotated, "k2"))
+	os.WriteFile(path, data, 0o600)
+
+	if _, err := keys.Key(context.Background(), "k2"); !errors.Is(err, auth.ErrUnknownKey) {
+		t.Errorf("Expected unknown kid before refresh, got %v", err)
+	}
+	if err := keys.Refresh(context.Background()); err != nil {
+		t.Fatalf("Failed to refresh: %v", err)
+	}
+	if _, err := keys.Key(context.Background(), "k2"); err != nil {
+		t.Errorf("Expected rotated key after refresh, got %v", err)
+	}
+}
@@ -0,0 +1,381 @@
+package cache_test
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"quotes-service/internal/domain"
+	"quotes-service/internal/infrastructure/database"
+	"quotes-service/internal/infrastructure/logger"
+	"quotes-service/internal/repository/cache"
+)
+
+// Mock repository counting calls that reach the database
+type countingRepository struct {
+	mu     sync.Mutex
+	quotes []*domain.Quote
+	nextID int
+	delay  time.Duration
+	calls  atomic.Int64
+}
+
+func (m *countingRepository) Create(ctx context.Context, quote *domain.Quote) (*domain.Quote, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.nextID++
+	created := &domain.Quote{ID: m.nextID, Author: quote.Author, Text: quote.Text}
+	m.quotes = append(m.quotes, created)
+	return created, nil
+}
+
+func (m *countingRepository) GetAll(ctx context.Context, filter domain.QuoteFilter) ([]*domain.Quote, error) {
+	m.calls.Add(1)
+	time.Sleep(m.delay)
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]*domain.Quote(nil), m.quotes...), nil
+}
+
+func (m *countingRepository) GetByID(ctx context.Context, id int) (*domain.Quote, error) {
+	m.calls.Add(1)
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, quote := range m.quotes {
+		if quote.ID == id {
+			return quote, nil
+		}
+	}
+	return nil, domain.ErrQuoteNotFound
+}
+
+func (m *countingRepository) GetRandom(ctx context.Context) (*domain.Quote, error) {
+	return nil, domain.ErrQuoteNotFound
+}
+
+func (m *countingRepository) Update(ctx context.Context, quote *domain.Quote) (*domain.Quote, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, existing := range m.quotes {
+		if existing.ID == quote.ID {
+			existing.Author, existing.Text = quote.Author, quote.Text
+			return existing, nil
+		}
+	}
+	return nil, domain.ErrQuoteNotFound
+}
+
+func (m *countingRepository) Delete(ctx context.Context, id int) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for i, quote := range m.quotes {
+		if quote.ID == id {
+			m.quotes = append(m.quotes[:i], m.quotes[i+1:]...)
+			return nil
+		}
+	}
+	return domain.ErrQuoteNotFound
+}
+
+func (m *countingRepository) Count(ctx context.Context, filter domain.QuoteFilter) (int, error) {
+	m.calls.Add(1)
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return len(m.quotes), nil
+}
+
+func (m *countingRepository) ListAuthors(ctx context.Context, filter domain.AuthorFilter) ([]*domain.Author, error) {
+	m.calls.Add(1)
+	return nil, nil
+}
+
+func (m *countingRepository) GetByAuthors(ctx context.Context, authors []string, limit int) ([]*domain.Quote, error) {
+	m.calls.Add(1)
+	return nil, nil
+}
+
+func (m *countingRepository) HealthCheck(ctx context.Context) error {
+	return nil
+}
+
+func newCachedRepository(next domain.QuoteRepository) domain.QuoteRepository {
+	return cache.NewQuoteRepository(next, cache.NewLRUStore(100), time.Minute, time.Second, logger.New("error"))
+}
+
+func TestCachedRepository_GetByIDHits(t *testing.T) {
+	mockRepo := &countingRepository{}
+	repo := newCachedRepository(mockRepo)
+	ctx := context.Background()
+
+	created, _ := repo.Create(ctx, &domain.Quote{Author: "Author", Text: "Quote"})
+
+	for i := 0; i < 3; i++ {
+		quote, err := repo.GetByID(ctx, created.ID)
+		if err != nil {
+			t.Fatalf("Expected no error but got: %v", err)
+		}
+		if quote.Author != "Author" {
+			t.Errorf("Expected author 'Author', got '%s'", quote.Author)
+		}
+	}
+
+	if calls := mockRepo.calls.Load(); calls != 1 {
+		t.Errorf("Expected 1 repository call, got %d", calls)
+	}
+
+	stats := repo.(domain.CacheStatsProvider).CacheStats()
+	if stats.Hits != 2 || stats.Misses != 1 {
+		t.Errorf("Expected 2 hits and 1 miss, got %d hits and %d misses", stats.Hits, stats.Misses)
+	}
+}
+
+func TestCachedRepository_NotFoundIsNotCached(t *testing.T) {
+	mockRepo := &countingRepository{}
+	repo := newCachedRepository(mockRepo)
+	ctx := context.Background()
+
+	if _, err := repo.GetByID(ctx, 42); err != domain.ErrQuoteNotFound {
+		t.Fatalf("Expected ErrQuoteNotFound, got %v", err)
+	}
+	repo.GetByID(ctx, 42)
+
+	if calls := mockRepo.calls.Load(); calls != 2 {
+		t.Errorf("Expected 2 repository calls, got %d", calls)
+	}
+}
+
+func TestCachedRepository_InvalidatesOnWrites(t *testing.T) {
+	mockRepo := &countingRepository{}
+	repo := newCachedRepository(mockRepo)
+	invalidator := repo.(domain.CacheInvalidator)
+	ctx := context.Background()
+
+	first, _ := repo.Create(ctx, &domain.Quote{Author: "Author 1", Text: "Quote 1"})
+
+	quotes, _ := repo.GetAll(ctx, domain.QuoteFilter{Limit: 10})
+	count, _ := repo.Count(ctx, domain.QuoteFilter{})
+	if len(quotes) != 1 || count != 1 {
+		t.Fatalf("Expected 1 quote, got %d (count %d)", len(quotes), count)
+	}
+
+	repo.Create(ctx, &domain.Quote{Author: "Author 2", Text: "Quote 2"})
+	quotes, _ = repo.GetAll(ctx, domain.QuoteFilter{Limit: 10})
+	count, _ = repo.Count(ctx, domain.QuoteFilter{})
+	if len(quotes) != 2 || count != 2 {
+		t.Errorf("Expected 2 quotes after create, got %d (count %d)", len(quotes), count)
+	}
+
+	repo.GetByID(ctx, first.ID)
+	if _, err := repo.Update(ctx, &domain.Quote{ID: first.ID, Author: "Author 1", Text: "Edited"}); err != nil {
+		t.Fatalf("Expected no error but got: %v", err)
+	}
+	if quote, _ := repo.GetByID(ctx, first.ID); quote == nil || quote.Text != "Edited" {
+		t.Errorf("Expected updated quote, got %+v", quote)
+	}
+
+	if err := repo.Delete(ctx, first.ID); err != nil {
+		t.Fatalf("Expected no error but got: %v", err)
+	}
+	if _, err := repo.GetByID(ctx, first.ID); err != domain.ErrQuoteNotFound {
+		t.Errorf("Expected deleted quote to be evicted, got %v", err)
+	}
+	quotes, _ = repo.GetAll(ctx, domain.QuoteFilter{Limit: 10})
+	if len(quotes) != 1 {
+		t.Errorf("Expected 1 quote after delete, got %d", len(quotes))
+	}
+
+	// Повторный сброс после коммита ничего не ломает
+	invalidator.Invalidate(ctx, first.ID)
+	if quotes, _ = repo.GetAll(ctx, domain.QuoteFilter{Limit: 10}); len(quotes) != 1 {
+		t.Errorf("Expected 1 quote after invalidation, got %d", len(quotes))
+	}
+}
+
+func TestCachedRepository_BypassInsideTransaction(t *testing.T) {
+	mockRepo := &countingRepository{}
+	repo := newCachedRepository(mockRepo)
+	created, _ := repo.Create(context.Background(), &domain.Quote{Author: "Author", Text: "Quote"})
+
+	repo.GetByID(context.Background(), created.ID)
+	ctx := database.WithTransaction(context.Background())
+	repo.GetByID(ctx, created.ID)
+	repo.GetByID(ctx, created.ID)
+
+	if calls := mockRepo.calls.Load(); calls != 3 {
+		t.Errorf("Expected cache bypass inside transaction, got %d repository calls", calls)
+	}
+}
+
+// gatedRepository задерживает первое чтение до сигнала
+type gatedRepository struct {
+	*countingRepository
+	started chan struct{}
+	release chan struct{}
+	once    sync.Once
+}
+
+func (m *gatedRepository) GetByID(ctx context.Context, id int) (*domain.Quote, error) {
+	quote, err := m.countingRepository.GetByID(ctx, id)
+	m.once.Do(func() {
+		stale := *quote
+		quote = &stale
+		close(m.started)
+		<-m.release
+	})
+	return quote, err
+}
+
+func TestCachedRepository_StaleLoadDoesNotOutliveInvalidate(t *testing.T) {
+	mockRepo := &gatedRepository{
+		countingRepository: &countingRepository{},
+		started:            make(chan struct{}),
+		release:            make(chan struct{}),
+	}
+	repo := newCachedRepository(mockRepo)
+	ctx := context.Background()
+	created, _ := repo.Create(ctx, &domain.Quote{Author: "Author", Text: "Old"})
+
+	loaded := make(chan struct{})
+	go func() {
+		defer close(loaded)
+		repo.GetByID(ctx, created.ID)
+	}()
+
+	<-mockRepo.started
+	repo.Update(ctx, &domain.Quote{ID: created.ID, Author: "Author", Text: "New"})
+	close(mockRepo.release)
+	<-loaded
+
+	if quote, _ := repo.GetByID(ctx, created.ID); quote == nil || quote.Text != "New" {
+		t.Errorf("Expected load started before invalidation to be discarded, got %+v", quote)
+	}
+}
+
+func TestCachedRepository_BypassForReadYourWrites(t *testing.T) {
+	mockRepo := &countingRepository{}
+	repo := newCachedRepository(mockRepo)
+	ctx := database.WithPrimary(context.Background())
+
+	repo.Count(ctx, domain.QuoteFilter{})
+	repo.Count(ctx, domain.QuoteFilter{})
+
+	if calls := mockRepo.calls.Load(); calls != 2 {
+		t.Errorf("Expected cache bypass, got %d repository calls", calls)
+	}
+}
+
+func TestCachedRepository_CoalescesConcurrentMisses(t *testing.T) {
+	mockRepo := &countingRepository{delay: 50 * time.Millisecond}
+	repo := newCachedRepository(mockRepo)
+	ctx := context.Background()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := repo.GetAll(ctx, domain.QuoteFilter{Limit: 10}); err != nil {
+				t.Errorf("Expected no error but got: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if calls := mockRepo.calls.Load(); calls != 1 {
+		t.Errorf("Expected 1 repository call, got %d", calls)
+	}
+}
+
+func TestCachedRepository_CanceledCallerDoesNotFailOthers(t *testing.T) {
+	mockRepo := &countingRepository{delay: 50 * time.Millisecond}
+	repo := newCachedRepository(mockRepo)
+
+	canceled, cancel := context.WithCancel(context.Background())
+	leaderDone := make(chan error, 1)
+	go func() {
+		_, err := repo.GetAll(canceled, domain.QuoteFilter{Limit: 10})
+		leaderDone <- err
+	}()
+
+	time.Sleep(10 * time.Millisecond)
+	cancel()
+
+	if _, err := repo.GetAll(context.Background(), domain.QuoteFilter{Limit: 10}); err != nil {
+		t.Errorf("Expected no error for waiting caller but got: %v", err)
+	}
+	if err := <-leaderDone; err != context.Canceled {
+		t.Errorf("Expected canceled caller to get context.Canceled, got %v", err)
+	}
+	if calls := mockRepo.calls.Load(); calls != 1 {
+		t.Errorf("Expected 1 repository call, got %d", calls)
+	}
+}
+
+// Mock repository panicking on reads
+type panickingRepository struct {
+	domain.QuoteRepository
+}
+
+func (m *panickingRepository) GetByID(ctx context.Context, id int) (*domain.Quote, error) {
+	time.Sleep(20 * time.Millisecond)
+	panic("boom")
+}
+
+func TestCachedRepository_PanicReachesAllCallers(t *testing.T) {
+	repo := newCachedRepository(&panickingRepository{})
+
+	get := func() (recovered interface{}) {
+		defer func() { recovered = recover() }()
+		repo.GetByID(context.Background(), 1)
+		return nil
+	}
+
+	for round := 0; round < 2; round++ {
+		results := make(chan interface{}, 3)
+		for i := 0; i < 3; i++ {
+			go func() { results <- get() }()
+		}
+
+		for i := 0; i < 3; i++ {
+			select {
+			case recovered := <-results:
+				if recovered != "boom" {
+					t.Errorf("Expected panic to reach caller, got %v", recovered)
+				}
+			case <-time.After(time.Second):
+				t.Fatalf("Caller blocked after panic in round %d", round)
+			}
+		}
+	}
+}
+
+func TestLRUStore_EvictionAndTTL(t *testing.T) {
+	store := cache.NewLRUStore(2)
+
+	store.Set("a", []byte("1"), 0)
+	store.Set("b", []byte("2"), 0)
+	store.Get("a")
+	store.Set("c", []byte("3"), 0)
+
+	if _, ok := store.Get("b"); ok {
+		t.Errorf("Expected least recently used key to be evicted")
+	}
+	if _, ok := store.Get("a"); !ok {
+		t.Errorf("Expected recently used key to stay")
+	}
+
+	store.Set("d", []byte("4"), time.Millisecond)
+	time.Sleep(5 * time.Millisecond)
+	if _, ok := store.Get("d"); ok {
+		t.Errorf("Expected expired key to be dropped")
+	}
+}
@@ -0,0 +1,213 @@
+package config_test
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"quotes-service/internal/config"
+)
+
+func writeFile(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatalf("Failed to write config file: %v", err)
+	}
+	return path
+}
+
+func TestLoad_Precedence(t *testing.T) {
+	path := writeFile(t, "config.yaml", `
+server:
+  address: ":8081"
+db:
+  max_open_conns: 40
+  max_idle_conns: 10
+cache_ttl: 1m
+LOG_LEVEL: warn
+`)
+	t.Setenv("DB_MAX_IDLE_CONNS", "5")
+	t.Setenv("LOG_LEVEL", "error")
+
+	cfg, err := config.Load([]string{"-config", path, "--log-level=debug"})
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+
+	if cfg.ServerAddress != ":8081" || cfg.DatabaseConfig.MaxOpenConns != 40 || cfg.CacheConfig.TTL != time.Minute {
+		t.Errorf("Expected file values over defaults, got %s %d %s", cfg.ServerAddress, cfg.DatabaseConfig.MaxOpenConns, cfg.CacheConfig.TTL)
+	}
+	if cfg.DatabaseConfig.MaxIdleConns != 5 {
+		t.Errorf("Expected env over file, got %d", cfg.DatabaseConfig.MaxIdleConns)
+	}
+	if cfg.Logging.Level != "debug" {
+		t.Errorf("Expected flag over env, got %s", cfg.Logging.Level)
+	}
+	if cfg.CacheConfig.Size != 1000 {
+		t.Errorf("Expected default for unset key, got %d", cfg.CacheConfig.Size)
+	}
+
+	sources := make(map[string]string)
+	for _, setting := range cfg.Settings() {
+		sources[setting.Key] = setting.Source
+	}
+	for key, source := range map[string]string{
+		"SERVER_ADDRESS":    config.SourceFile,
+		"DB_MAX_IDLE_CONNS": config.SourceEnv,
+		"LOG_LEVEL":         config.SourceFlag,
+		"CACHE_SIZE":        config.SourceDefault,
+	} {
+		if sources[key] != source {
+			t.Errorf("Expected %s from %s, got %s", key, source, sources[key])
+		}
+	}
+}
+
+func TestLoad_TOML(t *testing.T) {
+	path := writeFile(t, "config.toml", `
+outbox_sinks = ["log", "webhook"]
+
+[outbox]
+webhook_url = "https://hooks.example.com/events"
+
+[api]
+sunset = 2027-06-01
+`)
+
+	cfg, err := config.Load([]string{"-config", path})
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+	if strings.Join(cfg.OutboxConfig.Sinks, ",") != "log,webhook" || cfg.OutboxConfig.WebhookURL == "" {
+		t.Errorf("Unexpected outbox config: %+v", cfg.OutboxConfig)
+	}
+	if cfg.APIConfig.Sunset.Format(time.DateOnly) != "2027-06-01" {
+		t.Errorf("Unexpected sunset: %s", cfg.APIConfig.Sunset)
+	}
+}
+
+func TestLoad_ReportsAllErrors(t *testing.T) {
+	path := writeFile(t, "config.yaml", `
+log_levle: debug
+tracing_exporter: zipkin
+`)
+	t.Setenv("DB_MAX_OPEN_CONNS", "many")
+	t.Setenv("OUTBOX_SINKS", "log,nats")
+	t.Setenv("OUTBOX_LEASE", "1s")
+
+	_, err := config.Load([]string{"-config", path, "-cache-ttl", "0"})
+	if err == nil {
+		t.Fatal("Expected validation error")
+	}
+	for _, want := range []string{
+		`DB_MAX_OPEN_CONNS: invalid integer "many"`,
+		"OUTBOX_NATS_URL: is required for nats sink",
+		`TRACING_EXPORTER: unknown exporter "zipkin"`,
+		"CACHE_TTL: must be positive",
+		"OUTBOX_LEASE: must be at least OUTBOX_PUBLISH_TIMEOUT",
+		"LOG_LEVLE: unknown key in config file",
+	} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("Expected %q in error:\n%v", want, err)
+		}
+	}
+}
+
+func TestLoad_Timeouts(t *testing.T) {
+	path := writeFile(t, "config.yaml", `
+server:
+  write_timeout: 20s
+  max_header_bytes: 65536
+http:
+  request_timeout: 8s
+  route_timeouts: GET /quotes=12s
+quote_timeout:
+  list: 7s
+apikey_timeout: 2s
+`)
+
+	cfg, err := config.Load([]string{"-config", path})
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+	if cfg.Server.WriteTimeout != 20*time.Second || cfg.Server.MaxHeaderBytes != 65536 {
+		t.Errorf("Unexpected server config: %+v", cfg.Server)
+	}
+	if cfg.Timeouts.Default != 8*time.Second || cfg.Timeouts.Routes["GET /quotes"] != 12*time.Second {
+		t.Errorf("Unexpected request timeouts: %+v", cfg.Timeouts)
+	}
+	if cfg.QuoteTimeouts.List != 7*time.Second || cfg.QuoteTimeouts.Create != 5*time.Second ||
+		cfg.QuoteTimeouts.APIKey != 2*time.Second || cfg.QuoteTimeouts.Idempotency != 5*time.Second {
+		t.Errorf("Unexpected operation timeouts: %+v", cfg.QuoteTimeouts)
+	}
+
+	_, err = config.Load([]string{"-config", path, "-http-route-timeouts", "GET /quotes=30s", "-quote-timeout-get", "0", "-idempotency-timeout", "0"})
+	if err == nil {
+		t.Fatal("Expected validation error")
+	}
+	for _, want := range []string{
+		"HTTP_ROUTE_TIMEOUTS: GET /quotes: must be positive and not exceed SERVER_WRITE_TIMEOUT",
+		"QUOTE_TIMEOUT_GET: must be positive",
+		"IDEMPOTENCY_TIMEOUT: must be positive",
+	} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("Expected %q in error:\n%v", want, err)
+		}
+	}
+}
+
+func TestLoad_TLSRequiresCertificate(t *testing.T) {
+	_, err := config.Load([]string{
+		"-tls-key-file", "tls.key",
+		"-tls-client-auth", "require",
+		"-tls-client-role-mapping", "billing=owner",
+		"-tls-redirect-address", ":8080",
+	})
+	if err == nil {
+		t.Fatal("Expected validation error")
+	}
+	for _, want := range []string{
+		"TLS_CERT_FILE: is required with TLS_KEY_FILE",
+		"TLS_CLIENT_AUTH: requires TLS_CERT_FILE",
+		`TLS_CLIENT_CA_FILE: is required for client auth "require"`,
+		`TLS_CLIENT_ROLE_MAPPING: unknown role "owner"`,
+		"TLS_REDIRECT_ADDRESS: must differ from SERVER_ADDRESS",
+	} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("Expected %q in error:\n%v", want, err)
+		}
+	}
+}
+
+func TestLoad_RejectsUnknownFlags(t *testing.T) {
+	if _, err := config.Load([]string{"-no-such-flag=1"}); err == nil {
+		t.Error("Expected error for unknown flag")
+	}
+	if _, err := config.Load([]string{"-config", writeFile(t, "config.json", "{}")}); err == nil {
+		t.Error("Expected error for unsupported file format")
+	}
+}
+
+func TestPrint_MasksSecrets(t *testing.T) {
+	t.Setenv("DATABASE_URL", "postgres://quotes_user:s3cret@db:5432/quotes_db")
+
+	cfg, err := config.Load(nil)
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+	var out bytes.Buffer
+	if err := cfg.Print(&out); err != nil {
+		t.Fatalf("Failed to print config: %v", err)
+	}
+
+	if strings.Contains(out.String(), "s3cret") {
+		t.Errorf("Secret leaked in output:\n%s", out.String())
+	}
+	if !strings.Contains(out.String(), `"postgres://quotes_user:xxxxx@db:5432/quotes_db" # env`) {
+		t.Errorf("Expected masked URL with source, got:\n%s", out.String())
+	}
+}
@@ -0,0 +1,116 @@
+package config_test
+
+import (
+	"os"
+	"testing"
+
+	"quotes-service/internal/config"
+	"quotes-service/internal/infrastructure/logger"
+)
+
+func TestReloader_AppliesChangedKeys(t *testing.T) {
+	path := writeFile(t, "config.yaml", "log_level: info\nrate_limit_rules: GET /quotes=10/1m\n")
+	args := []string{"-config", path}
+	cfg, err := config.Load(args)
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+
+	reloader := config.NewReloader(args, cfg, logger.New("error"))
+	var levels, rules []string
+	reloader.OnChange([]string{"LOG_LEVEL"}, func(next *config.Config) {
+		levels = append(levels, next.Logging.Level)
+	})
+	reloader.OnChange([]string{"RATE_LIMIT_RULES"}, func(next *config.Config) {
+		rules = append(rules, next.RateLimit.Limits.Rules[0].String())
+	})
+
+	if err := os.WriteFile(path, []byte("log_level: debug\nrate_limit_rules: GET /quotes=10/1m\ndb_max_open_conns: 50\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := reloader.Reload(); err != nil {
+		t.Fatalf("Reload failed: %v", err)
+	}
+
+	if len(levels) != 1 || levels[0] != "debug" {
+		t.Errorf("Expected LOG_LEVEL handler to run once with debug, got %v", levels)
+	}
+	if len(rules) != 0 {
+		t.Errorf("Expected unchanged keys not to be applied, got %v", rules)
+	}
+	if reloader.Current().Logging.Level != "debug" {
+		t.Errorf("Expected applied key to be updated in current config")
+	}
+
+	changes := config.Diff(cfg, reloader.Current())
+	if len(changes) != 1 || changes[0].Key != "LOG_LEVEL" {
+		t.Errorf("Unexpected diff: %+v", changes)
+	}
+}
+
+func TestReloader_KeepsRestartRequiredValues(t *testing.T) {
+	path := writeFile(t, "config.yaml", "log_level: info\n")
+	args := []string{"-config", path}
+	cfg, err := config.Load(args)
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+
+	reloader := config.NewReloader(args, cfg, logger.New("error"))
+	applied := 0
+	reloader.OnChange([]string{"LOG_LEVEL"}, func(*config.Config) { applied++ })
+
+	if err := os.WriteFile(path, []byte("log_level: info\ndb_max_open_conns: 50\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	// Повторное перечитывание снова видит ключ, требующий перезапуска
+	for i := 0; i < 2; i++ {
+		if err := reloader.Reload(); err != nil {
+			t.Fatalf("Reload failed: %v", err)
+		}
+		current := reloader.Current()
+		if current.DatabaseConfig.MaxOpenConns != 25 {
+			t.Errorf("Expected running value 25 to stay in current config, got %d", current.DatabaseConfig.MaxOpenConns)
+		}
+		next, err := config.Load(args)
+		if err != nil {
+			t.Fatalf("Failed to load config: %v", err)
+		}
+		if changes := config.Diff(current, next); len(changes) != 1 || changes[0].Key != "DB_MAX_OPEN_CONNS" {
+			t.Errorf("Expected pending restart-required change, got %+v", changes)
+		}
+	}
+	if applied != 0 {
+		t.Errorf("Expected unchanged reloadable keys not to be applied, got %d calls", applied)
+	}
+
+	for _, setting := range reloader.Current().Settings() {
+		if setting.Key == "DB_MAX_OPEN_CONNS" && (setting.Value != "25" || setting.Source != config.SourceDefault) {
+			t.Errorf("Expected running setting with its source, got %+v", setting)
+		}
+	}
+}
+
+func TestReloader_RejectsInvalidConfig(t *testing.T) {
+	path := writeFile(t, "config.yaml", "log_level: info\n")
+	args := []string{"-config", path}
+	cfg, err := config.Load(args)
+	if err != nil {
+		t.Fatalf("Failed to load config: %v", err)
+	}
+
+	reloader := config.NewReloader(args, cfg, logger.New("error"))
+	applied := false
+	reloader.OnChange([]string{"LOG_LEVEL", "CACHE_TTL"}, func(*config.Config) { applied = true })
+
+	// Один ключ корректен, другой нет: не применяется ничего
+	if err := os.WriteFile(path, []byte("log_level: debug\ncache_ttl: soon\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := reloader.Reload(); err == nil {
+		t.Fatal("Expected invalid config to be rejected")
+	}
+	if applied || reloader.Current() != cfg {
+		t.Error("Invalid reload must not change running configuration")
+	}
+}
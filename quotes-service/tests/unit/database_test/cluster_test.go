@@ -0,0 +1,139 @@
+package database_test
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"syscall"
+	"testing"
+
+	"quotes-service/internal/infrastructure/database"
+	"quotes-service/internal/infrastructure/logger"
+
+	"github.com/lib/pq"
+)
+
+// Пулы открываются без подключения; порт 1 гарантирует отказ при пинге
+func openUnreachable(t *testing.T) *sql.DB {
+	t.Helper()
+	db, err := sql.Open("postgres", "host=127.0.0.1 port=1 sslmode=disable connect_timeout=1")
+	if err != nil {
+		t.Fatalf("Failed to open pool: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+func TestCluster_ReaderRoundRobin(t *testing.T) {
+	primary := openUnreachable(t)
+	replicaA := openUnreachable(t)
+	replicaB := openUnreachable(t)
+	cluster := database.NewCluster(primary, logger.New("debug"), replicaA, replicaB)
+
+	ctx := context.Background()
+	first := cluster.Reader(ctx)
+	second := cluster.Reader(ctx)
+	third := cluster.Reader(ctx)
+
+	if first == primary || second == primary {
+		t.Fatalf("Expected reads to go to replicas")
+	}
+	if first == second {
+		t.Errorf("Expected consecutive reads to use different replicas")
+	}
+	if first != third {
+		t.Errorf("Expected round-robin to wrap around")
+	}
+}
+
+func TestCluster_ReaderWithoutReplicas(t *testing.T) {
+	primary := openUnreachable(t)
+	cluster := database.NewCluster(primary, logger.New("debug"))
+
+	if cluster.Reader(context.Background()) != primary {
+		t.Errorf("Expected primary when no replicas configured")
+	}
+}
+
+func TestCluster_ReadYourWrites(t *testing.T) {
+	primary := openUnreachable(t)
+	cluster := database.NewCluster(primary, logger.New("debug"), openUnreachable(t))
+
+	ctx := database.WithPrimary(context.Background())
+	if cluster.Reader(ctx) != primary {
+		t.Errorf("Expected primary for read-your-writes context")
+	}
+}
+
+func TestCluster_ReportFailureEvictsReplica(t *testing.T) {
+	primary := openUnreachable(t)
+	replicaA := openUnreachable(t)
+	replicaB := openUnreachable(t)
+	cluster := database.NewCluster(primary, logger.New("debug"), replicaA, replicaB)
+
+	cluster.ReportFailure(replicaA, &net.OpError{Op: "read", Net: "tcp", Err: syscall.ECONNRESET})
+	if cluster.HealthyReplicas() != 1 {
+		t.Fatalf("Expected 1 healthy replica, got %d", cluster.HealthyReplicas())
+	}
+
+	for i := 0; i < 4; i++ {
+		if db := cluster.Reader(context.Background()); db != replicaB {
+			t.Errorf("Expected reads to skip evicted replica")
+		}
+	}
+
+	cluster.ReportFailure(replicaB, sql.ErrNoRows)
+	if cluster.HealthyReplicas() != 1 {
+		t.Errorf("Expected ErrNoRows not to evict replica")
+	}
+}
+
+func TestCluster_ReportFailureEvictsOnlyOnConnectionErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		err   error
+		evict bool
+	}{
+		{"bad conn", fmt.Errorf("query: %w", driver.ErrBadConn), true},
+		{"unexpected EOF", io.ErrUnexpectedEOF, true},
+		{"net error", &net.OpError{Op: "dial", Net: "tcp", Err: syscall.ECONNREFUSED}, true},
+		{"connection failure", &pq.Error{Code: "08006"}, true},
+		{"serialization conflict", &pq.Error{Code: "40001"}, false},
+		{"recovery conflict", &pq.Error{Code: "40P01"}, false},
+		{"undefined column", &pq.Error{Code: "42703"}, false},
+		{"no rows", sql.ErrNoRows, false},
+		{"canceled", context.Canceled, false},
+		{"plain error", errors.New("scan failed"), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			replica := openUnreachable(t)
+			cluster := database.NewCluster(openUnreachable(t), logger.New("debug"), replica)
+
+			cluster.ReportFailure(replica, tt.err)
+
+			if evicted := cluster.HealthyReplicas() == 0; evicted != tt.evict {
+				t.Errorf("Expected evicted=%v for %v, got %v", tt.evict, tt.err, evicted)
+			}
+		})
+	}
+}
+
+func TestCluster_CheckReplicasFallsBackToPrimary(t *testing.T) {
+	primary := openUnreachable(t)
+	cluster := database.NewCluster(primary, logger.New("debug"), openUnreachable(t))
+
+	cluster.CheckReplicas(context.Background())
+
+	if cluster.HealthyReplicas() != 0 {
+		t.Fatalf("Expected unreachable replica to be evicted")
+	}
+	if cluster.Reader(context.Background()) != primary {
+		t.Errorf("Expected primary when all replicas are evicted")
+	}
+}
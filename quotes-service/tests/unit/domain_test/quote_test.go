@@ -109,3 +109,25 @@ func TestCreateQuoteRequest_Validate(t *testing.T) {
 		})
 	}
 }
+
+func TestNormalizeTags(t *testing.T) {
+	tags, err := domain.NormalizeTags([]string{" Wisdom ", "life", "wisdom", ""})
+	if err != nil {
+		t.Fatalf("Expected no error but got: %v", err)
+	}
+	if len(tags) != 2 || tags[0] != "wisdom" || tags[1] != "life" {
+		t.Errorf("Expected [wisdom life], got %v", tags)
+	}
+
+	tooMany := make([]string, 11)
+	for i := range tooMany {
+		tooMany[i] = string(rune('a' + i))
+	}
+	if _, err := domain.NormalizeTags(tooMany); err == nil {
+		t.Errorf("Expected error for more than 10 tags")
+	}
+
+	if _, err := domain.NormalizeTags([]string{string(make([]byte, 51))}); err == nil {
+		t.Errorf("Expected error for too long tag")
+	}
+}
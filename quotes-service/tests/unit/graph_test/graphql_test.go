@@ -0,0 +1,414 @@
+package graph_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"quotes-service/internal/domain"
+	"quotes-service/internal/graph"
+	"quotes-service/internal/handler"
+	"quotes-service/internal/infrastructure/logger"
+	"quotes-service/internal/ratelimit"
+	"quotes-service/internal/service"
+
+	"github.com/gorilla/mux"
+)
+
+// batchRepo считает обращения к "базе" для проверки N+1
+type batchRepo struct {
+	mu     sync.Mutex
+	quotes []*domain.Quote
+	nextID int
+
+	authorCalls      atomic.Int64
+	byAuthorsCalls   atomic.Int64
+	requestedAuthors atomic.Int64
+}
+
+func (m *batchRepo) Create(ctx context.Context, quote *domain.Quote) (*domain.Quote, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.nextID++
+	created := *quote
+	created.ID = m.nextID
+	created.CreatedAt = time.Now()
+	m.quotes = append(m.quotes, &created)
+	return &created, nil
+}
+
+func (m *batchRepo) GetAll(ctx context.Context, filter domain.QuoteFilter) ([]*domain.Quote, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var result []*domain.Quote
+	for _, quote := range m.quotes {
+		if filter.Author == "" || quote.Author == filter.Author {
+			result = append(result, quote)
+		}
+	}
+	if filter.Offset >= len(result) {
+		return nil, nil
+	}
+	result = result[filter.Offset:]
+	if filter.Limit < len(result) {
+		result = result[:filter.Limit]
+	}
+	return result, nil
+}
+
+func (m *batchRepo) GetByID(ctx context.Context, id int) (*domain.Quote, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, quote := range m.quotes {
+		if quote.ID == id {
+			return quote, nil
+		}
+	}
+	return nil, domain.ErrQuoteNotFound
+}
+
+func (m *batchRepo) GetRandom(ctx context.Context) (*domain.Quote, error) {
+	return nil, domain.ErrQuoteNotFound
+}
+
+func (m *batchRepo) Update(ctx context.Context, quote *domain.Quote) (*domain.Quote, error) {
+	return nil, domain.ErrQuoteNotFound
+}
+
+func (m *batchRepo) Delete(ctx context.Context, id int) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for i, quote := range m.quotes {
+		if quote.ID == id {
+			m.quotes = append(m.quotes[:i], m.quotes[i+1:]...)
+			return nil
+		}
+	}
+	return domain.ErrQuoteNotFound
+}
+
+func (m *batchRepo) Count(ctx context.Context, filter domain.QuoteFilter) (int, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	count := 0
+	for _, quote := range m.quotes {
+		if filter.Author == "" || quote.Author == filter.Author {
+			count++
+		}
+	}
+	return count, nil
+}
+
+func (m *batchRepo) ListAuthors(ctx context.Context, filter domain.AuthorFilter) ([]*domain.Author, error) {
+	m.authorCalls.Add(1)
+	m.requestedAuthors.Add(int64(len(filter.Names)))
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	counts := make(map[string]int)
+	for _, quote := range m.quotes {
+		counts[quote.Author]++
+	}
+
+	var authors []*domain.Author
+	for name, count := range counts {
+		authors = append(authors, &domain.Author{Name: name, QuoteCount: count})
+	}
+	sort.Slice(authors, func(i, j int) bool { return authors[i].Name < authors[j].Name })
+	return authors, nil
+}
+
+func (m *batchRepo) GetByAuthors(ctx context.Context, authors []string, limit int) ([]*domain.Quote, error) {
+	m.byAuthorsCalls.Add(1)
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var result []*domain.Quote
+	for _, quote := range m.quotes {
+		for _, author := range authors {
+			if quote.Author == author {
+				result = append(result, quote)
+			}
+		}
+	}
+	return result, nil
+}
+
+func (m *batchRepo) HealthCheck(ctx context.Context) error {
+	return nil
+}
+
+type gqlResponse struct {
+	Data   json.RawMessage `json:"data"`
+	Errors []struct {
+		Message    string                 `json:"message"`
+		Extensions map[string]interface{} `json:"extensions"`
+	} `json:"errors"`
+}
+
+func newServer(t *testing.T, repo *batchRepo, config graph.Config) *httptest.Server {
+	t.Helper()
+
+	log := logger.New("error")
+	executor, err := graph.NewExecutor(service.NewQuoteService(repo, log), config, log)
+	if err != nil {
+		t.Fatalf("Failed to build schema: %v", err)
+	}
+
+	router := mux.NewRouter()
+	handler.NewGraphQLHandler(executor, nil, nil, log).RegisterRoutes(router)
+	server := httptest.NewServer(router)
+	t.Cleanup(server.Close)
+	return server
+}
+
+func post(t *testing.T, server *httptest.Server, query string, variables map[string]interface{}) (int, gqlResponse) {
+	t.Helper()
+
+	body, _ := json.Marshal(map[string]interface{}{"query": query, "variables": variables})
+	resp, err := http.Post(server.URL+"/graphql", "application/json", bytes.NewReader(body))
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	var result gqlResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	return resp.StatusCode, result
+}
+
+func TestGraphQL_BatchesAuthorLoads(t *testing.T) {
+	repo := &batchRepo{}
+	for i := 0; i < 30; i++ {
+		author := []string{"Seneca", "Confucius", "Marcus Aurelius"}[i%3]
+		repo.Create(context.Background(), &domain.Quote{Author: author, Text: "text"})
+	}
+	server := newServer(t, repo, graph.Config{MaxDepth: 10, MaxComplexity: 10000})
+
+	status, resp := post(t, server, `
+		query {
+			quotes(first: 30) {
+				totalCount
+				nodes { id author { name quoteCount quotes(first: 2) { id } } }
+			}
+		}`, nil)
+	if status != http.StatusOK || len(resp.Errors) > 0 {
+		t.Fatalf("Expected success, got %d %+v", status, resp.Errors)
+	}
+
+	var data struct {
+		Quotes struct {
+			TotalCount int
+			Nodes      []struct {
+				Author struct {
+					Name       string
+					QuoteCount int
+					Quotes     []struct{ ID string }
+				}
+			}
+		}
+	}
+	json.Unmarshal(resp.Data, &data)
+
+	if data.Quotes.TotalCount != 30 || len(data.Quotes.Nodes) != 30 {
+		t.Fatalf("Expected 30 quotes, got %+v", data.Quotes)
+	}
+	if data.Quotes.Nodes[0].Author.QuoteCount != 10 {
+		t.Errorf("Expected quote count 10, got %d", data.Quotes.Nodes[0].Author.QuoteCount)
+	}
+
+	// 30 цитат трех авторов: по одному запросу на счетчики и на цитаты авторов
+	if calls := repo.authorCalls.Load(); calls != 1 {
+		t.Errorf("Expected 1 ListAuthors call, got %d", calls)
+	}
+	if calls := repo.byAuthorsCalls.Load(); calls != 1 {
+		t.Errorf("Expected 1 GetByAuthors call, got %d", calls)
+	}
+	if requested := repo.requestedAuthors.Load(); requested != 3 {
+		t.Errorf("Expected 3 distinct authors in batch, got %d", requested)
+	}
+}
+
+func TestGraphQL_Mutations(t *testing.T) {
+	server := newServer(t, &batchRepo{}, graph.Config{MaxDepth: 10, MaxComplexity: 1000})
+
+	_, resp := post(t, server, `mutation($input: CreateQuoteInput!) { createQuote(input: $input) { id text tags } }`,
+		map[string]interface{}{"input": map[string]interface{}{"author": "Seneca", "quote": "Luck is preparation.", "tags": []string{"Stoicism"}}})
+	if len(resp.Errors) > 0 {
+		t.Fatalf("Expected no errors, got %+v", resp.Errors)
+	}
+	if string(resp.Data) != `{"createQuote":{"id":"1","text":"Luck is preparation.","tags":["stoicism"]}}` {
+		t.Errorf("Unexpected data: %s", resp.Data)
+	}
+
+	_, resp = post(t, server, `mutation { createQuote(input: {author: "", quote: "x"}) { id } }`, nil)
+	if len(resp.Errors) == 0 || resp.Errors[0].Extensions["code"] != "BAD_USER_INPUT" {
+		t.Errorf("Expected BAD_USER_INPUT, got %+v", resp.Errors)
+	}
+
+	_, resp = post(t, server, `mutation { deleteQuote(id: "1") }`, nil)
+	if string(resp.Data) != `{"deleteQuote":true}` {
+		t.Errorf("Expected deletion, got %s %+v", resp.Data, resp.Errors)
+	}
+
+	_, resp = post(t, server, `{ quote(id: "1") { id } }`, nil)
+	if string(resp.Data) != `{"quote":null}` {
+		t.Errorf("Expected null for deleted quote, got %s", resp.Data)
+	}
+
+	// Мутация через GET запрещена
+	getResp, err := http.Get(server.URL + "/graphql?query=" + url.QueryEscape(`mutation { deleteQuote(id: "1") }`))
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	getResp.Body.Close()
+	if getResp.StatusCode != http.StatusMethodNotAllowed {
+		t.Errorf("Expected 405 for GET mutation, got %d", getResp.StatusCode)
+	}
+}
+
+func TestGraphQL_Limits(t *testing.T) {
+	server := newServer(t, &batchRepo{}, graph.Config{MaxDepth: 4, MaxComplexity: 500})
+
+	status, resp := post(t, server, `{ authors { quotes { author { quotes { id } } } } }`, nil)
+	if status != http.StatusBadRequest || len(resp.Errors) == 0 || resp.Errors[0].Extensions["code"] != "QUERY_TOO_DEEP" {
+		t.Errorf("Expected QUERY_TOO_DEEP, got %d %+v", status, resp.Errors)
+	}
+
+	status, resp = post(t, server, `query($n: Int) { quotes(first: $n) { nodes { id text tags author { name } } } }`, map[string]interface{}{"n": 100})
+	if status != http.StatusBadRequest || len(resp.Errors) == 0 || resp.Errors[0].Extensions["code"] != "QUERY_TOO_COMPLEX" {
+		t.Errorf("Expected QUERY_TOO_COMPLEX, got %d %+v", status, resp.Errors)
+	}
+
+	status, resp = post(t, server, `{ quotes(first: 10) { nodes { ...fields } } } fragment fields on Quote { id text }`, nil)
+	if status != http.StatusOK || len(resp.Errors) > 0 {
+		t.Errorf("Expected small query to pass, got %d %+v", status, resp.Errors)
+	}
+}
+
+func TestAnalyze(t *testing.T) {
+	stats := graph.Analyze(`
+		query Q { quotes(first: 5) { nodes { ...F } } }
+		fragment F on Quote { id author { name } }`, "Q", nil)
+
+	// quotes: 1 + 5 * (nodes: 1 + id 1 + author (1 + name 1))
+	if stats.Depth != 4 || stats.Complexity != 21 || stats.Mutation {
+		t.Errorf("Unexpected stats: %+v", stats)
+	}
+}
+
+// slowRepo ждет отмены контекста, как запрос к БД после дедлайна
+type slowRepo struct {
+	batchRepo
+}
+
+func (m *slowRepo) GetAll(ctx context.Context, filter domain.QuoteFilter) ([]*domain.Quote, error) {
+	<-ctx.Done()
+	return nil, ctx.Err()
+}
+
+// lateCommitRepo фиксирует цитату и возвращается уже после дедлайна запроса
+type lateCommitRepo struct {
+	batchRepo
+}
+
+func (m *lateCommitRepo) Create(ctx context.Context, quote *domain.Quote) (*domain.Quote, error) {
+	created, err := m.batchRepo.Create(ctx, quote)
+	<-ctx.Done()
+	return created, err
+}
+
+func newDeadlineServer(t *testing.T, repo domain.QuoteRepository) *httptest.Server {
+	t.Helper()
+
+	log := logger.New("error")
+	executor, err := graph.NewExecutor(service.NewQuoteService(repo, log), graph.Config{MaxDepth: 10, MaxComplexity: 1000}, log)
+	if err != nil {
+		t.Fatalf("Failed to build schema: %v", err)
+	}
+	timeouts := handler.NewTimeouts(handler.TimeoutConfig{Default: 20 * time.Millisecond})
+
+	router := mux.NewRouter()
+	handler.Mount(router, "", []handler.RouteRegistrar{handler.NewGraphQLHandler(executor, nil, nil, log)}, timeouts.Middleware)
+	server := httptest.NewServer(router)
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestGraphQL_DeadlineReturns504(t *testing.T) {
+	server := newDeadlineServer(t, &slowRepo{})
+
+	status, resp := post(t, server, `{ quotes(first: 1) { nodes { id } } }`, nil)
+	if status != http.StatusGatewayTimeout || len(resp.Errors) == 0 || resp.Errors[0].Extensions["code"] != "TIMEOUT" {
+		t.Errorf("Expected 504 TIMEOUT, got %d %+v", status, resp.Errors)
+	}
+}
+
+func TestGraphQL_MutationCommittedBeforeDeadlineIsReturned(t *testing.T) {
+	repo := &lateCommitRepo{}
+	server := newDeadlineServer(t, repo)
+
+	status, resp := post(t, server, `mutation { createQuote(input: {author: "Seneca", quote: "Time heals"}) { id text } }`, nil)
+	if status != http.StatusOK || len(resp.Errors) != 0 {
+		t.Fatalf("Expected 200 without errors, got %d %+v", status, resp.Errors)
+	}
+
+	var data struct {
+		CreateQuote struct {
+			ID   string `json:"id"`
+			Text string `json:"text"`
+		} `json:"createQuote"`
+	}
+	if err := json.Unmarshal(resp.Data, &data); err != nil {
+		t.Fatalf("Failed to decode data: %v", err)
+	}
+	if data.CreateQuote.ID != "1" || data.CreateQuote.Text != "Time heals" {
+		t.Errorf("Expected committed quote in response, got %+v", data.CreateQuote)
+	}
+}
+
+func TestGraphQL_MutationsShareRESTRateLimits(t *testing.T) {
+	log := logger.New("error")
+	executor, err := graph.NewExecutor(service.NewQuoteService(&batchRepo{}, log), graph.Config{MaxDepth: 10, MaxComplexity: 1000}, log)
+	if err != nil {
+		t.Fatalf("Failed to build schema: %v", err)
+	}
+	rules, _ := ratelimit.ParseRules("POST /quotes=1/1m")
+	limiter := handler.NewRateLimiter(ratelimit.NewLimiter(100), handler.RateLimitConfig{Rules: rules}, log)
+
+	router := mux.NewRouter()
+	handler.NewGraphQLHandler(executor, nil, limiter, log).RegisterRoutes(router)
+	server := httptest.NewServer(router)
+	t.Cleanup(server.Close)
+
+	create := `mutation { createQuote(input: {author: "Seneca", quote: "Luck is preparation."}) { id } }`
+	if status, resp := post(t, server, create, nil); status != http.StatusOK || len(resp.Errors) > 0 {
+		t.Fatalf("Expected first mutation to pass, got %d %+v", status, resp.Errors)
+	}
+	status, resp := post(t, server, create, nil)
+	if status != http.StatusTooManyRequests || len(resp.Errors) == 0 || resp.Errors[0].Extensions["code"] != "RATE_LIMITED" {
+		t.Errorf("Expected POST /quotes rule to limit createQuote, got %d %+v", status, resp.Errors)
+	}
+
+	// Запросы на чтение правилу не подчиняются
+	if status, _ := post(t, server, `{ quotes(first: 1) { nodes { id } } }`, nil); status != http.StatusOK {
+		t.Errorf("Expected query to pass, got %d", status)
+	}
+}
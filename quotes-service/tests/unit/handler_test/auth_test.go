@@ -0,0 +1,414 @@
+package handler_test
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"quotes-service/internal/auth"
+	"quotes-service/internal/domain"
+	"quotes-service/internal/handler"
+	"quotes-service/internal/infrastructure/logger"
+	"quotes-service/internal/service"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/gorilla/mux"
+)
+
+// keyRepo хранит API-ключи в памяти
+type keyRepo struct {
+	keys []*domain.APIKey
+}
+
+func (r *keyRepo) Create(ctx context.Context, key *domain.APIKey) (*domain.APIKey, error) {
+	created := *key
+	created.ID = len(r.keys) + 1
+	r.keys = append(r.keys, &created)
+	return &created, nil
+}
+
+func (r *keyRepo) GetByPrefix(ctx context.Context, prefix string) (*domain.APIKey, error) {
+	for _, key := range r.keys {
+		if key.Prefix == prefix {
+			return key, nil
+		}
+	}
+	return nil, domain.ErrAPIKeyNotFound
+}
+
+func (r *keyRepo) List(ctx context.Context) ([]*domain.APIKey, error) {
+	return r.keys, nil
+}
+
+func (r *keyRepo) Revoke(ctx context.Context, id int, at time.Time) (*domain.APIKey, error) {
+	if id > len(r.keys) {
+		return nil, domain.ErrAPIKeyNotFound
+	}
+	r.keys[id-1].RevokedAt = &at
+	return r.keys[id-1], nil
+}
+
+func (r *keyRepo) TouchLastUsed(ctx context.Context, id int, at time.Time) error {
+	return nil
+}
+
+func (r *keyRepo) CountActive(ctx context.Context, scope domain.Scope) (int, error) {
+	return 0, nil
+}
+
+// writeRepo дополняет randomRepo изменениями, чтобы проверить изменяющие
+// запросы; owner - владелец цитаты, filter - последний фильтр списка
+type writeRepo struct {
+	randomRepo
+	owner  string
+	filter domain.QuoteFilter
+}
+
+func (r *writeRepo) Create(ctx context.Context, quote *domain.Quote) (*domain.Quote, error) {
+	created := *quote
+	created.ID = 2
+	return &created, nil
+}
+
+func (r *writeRepo) Update(ctx context.Context, quote *domain.Quote) (*domain.Quote, error) {
+	return quote, nil
+}
+
+func (r *writeRepo) GetByID(ctx context.Context, id int) (*domain.Quote, error) {
+	quote, _ := r.GetRandom(ctx)
+	quote.CreatedBy = r.owner
+	return quote, nil
+}
+
+func (r *writeRepo) GetAll(ctx context.Context, filter domain.QuoteFilter) ([]*domain.Quote, error) {
+	r.filter = filter
+	return nil, nil
+}
+
+func (r *writeRepo) Delete(ctx context.Context, id int) error {
+	return nil
+}
+
+// eventRecorder запоминает события сервиса, чтобы проверить автора изменения
+type eventRecorder struct {
+	events []*domain.Event
+}
+
+func (r *eventRecorder) OnEvent(event *domain.Event) {
+	r.events = append(r.events, event)
+}
+
+// webhookRepo - пустое хранилище подписок для проверки доступа
+type webhookRepo struct {
+	domain.WebhookRepository
+}
+
+func (r *webhookRepo) ListSubscriptions(ctx context.Context) ([]*domain.WebhookSubscription, error) {
+	return nil, nil
+}
+
+func (r *webhookRepo) GetSubscription(ctx context.Context, id int) (*domain.WebhookSubscription, error) {
+	return &domain.WebhookSubscription{ID: id}, nil
+}
+
+func (r *webhookRepo) ListDeliveries(ctx context.Context, filter domain.DeliveryFilter) ([]*domain.WebhookDelivery, error) {
+	return nil, nil
+}
+
+type authFixture struct {
+	router *mux.Router
+	keys   *service.APIKeyService
+	repo   *writeRepo
+	events *eventRecorder
+}
+
+func newAuthFixture(t *testing.T, config handler.AuthConfig, tokens ...*auth.TokenVerifier) *authFixture {
+	t.Helper()
+
+	log := logger.New("error")
+	keys := service.NewAPIKeyService(&keyRepo{}, log)
+	var verifier *auth.TokenVerifier
+	if len(tokens) > 0 {
+		verifier = tokens[0]
+	}
+	authenticator := handler.NewAuthenticator(auth.NewAuthenticator(keys, verifier), config, log)
+
+	repo := &writeRepo{}
+	events := &eventRecorder{}
+	quoteService := service.NewQuoteService(repo, log, service.WithEventListeners(events))
+	quoteHandler := handler.NewQuoteHandler(quoteService, log)
+	webhookHandler := handler.NewWebhookHandler(service.NewWebhookService(&webhookRepo{}, log), authenticator, log)
+
+	router := mux.NewRouter()
+	handler.MountVersions(router,
+		handler.APIVersion{Name: "v1", Handlers: []handler.RouteRegistrar{quoteHandler, webhookHandler}, Middleware: []mux.MiddlewareFunc{authenticator.Middleware}},
+		handler.APIVersion{Name: "v1", Handlers: []handler.RouteRegistrar{handler.NewAPIKeyHandler(keys, authenticator, log)}},
+	)
+	return &authFixture{router: router, keys: keys, repo: repo, events: events}
+}
+
+// tokenIssuer - локальный IdP: RSA-ключ, JWKS-файл и подпись токенов
+type tokenIssuer struct {
+	key  *rsa.PrivateKey
+	jwks string
+}
+
+func newTokenIssuer(t *testing.T) *tokenIssuer {
+	t.Helper()
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("Failed to generate key: %v", err)
+	}
+	jwks, _ := json.Marshal(map[string]interface{}{"keys": []map[string]string{{
+		"kty": "RSA",
+		"kid": "test",
+		"use": "sig",
+		"alg": "RS256",
+		"n":   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+		"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+	}}})
+	path := filepath.Join(t.TempDir(), "jwks.json")
+	if err := os.WriteFile(path, jwks, 0o600); err != nil {
+		t.Fatalf("Failed to write JWKS: %v", err)
+	}
+	return &tokenIssuer{key: key, jwks: path}
+}
+
+func (i *tokenIssuer) verifier(t *testing.T) *auth.TokenVerifier {
+	t.Helper()
+
+	keys, err := auth.LoadKeySet(context.Background(), i.jwks, logger.New("error"))
+	if err != nil {
+		t.Fatalf("Failed to load JWKS: %v", err)
+	}
+	return auth.NewTokenVerifier(keys, auth.Config{Issuer: "https://sso.example.com"})
+}
+
+func (i *tokenIssuer) sign(t *testing.T, subject string, roles ...string) string {
+	t.Helper()
+
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, jwt.MapClaims{
+		"iss":   "https://sso.example.com",
+		"sub":   subject,
+		"roles": roles,
+		"exp":   time.Now().Add(time.Hour).Unix(),
+	})
+	token.Header["kid"] = "test"
+	signed, err := token.SignedString(i.key)
+	if err != nil {
+		t.Fatalf("Failed to sign token: %v", err)
+	}
+	return signed
+}
+
+func (f *authFixture) issue(t *testing.T, scopes ...domain.Scope) string {
+	t.Helper()
+
+	_, raw, err := f.keys.IssueKey(context.Background(), domain.APIKeyRequest{Name: "test", Scopes: scopes})
+	if err != nil {
+		t.Fatalf("Failed to issue key: %v", err)
+	}
+	return raw
+}
+
+func (f *authFixture) do(method, target, body string, header ...string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, target, strings.NewReader(body))
+	for i := 0; i+1 < len(header); i += 2 {
+		req.Header.Set(header[i], header[i+1])
+	}
+	rec := httptest.NewRecorder()
+	f.router.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestAuth_WritesRequireWriteScope(t *testing.T) {
+	f := newAuthFixture(t, handler.AuthConfig{Enabled: true})
+	reader := f.issue(t, domain.ScopeRead)
+	writer := f.issue(t, domain.ScopeWrite)
+
+	if rec := f.do(http.MethodGet, "/v1/quotes/random", ""); rec.Code != http.StatusOK {
+		t.Errorf("Expected public read, got %d", rec.Code)
+	}
+
+	rec := f.do(http.MethodDelete, "/v1/quotes/1", "")
+	if rec.Code != http.StatusUnauthorized || rec.Header().Get("WWW-Authenticate") == "" {
+		t.Errorf("Expected 401 with challenge, got %d %v", rec.Code, rec.Header())
+	}
+	if rec := f.do(http.MethodDelete, "/v1/quotes/1", "", "X-API-Key", "qs_bogus_key"); rec.Code != http.StatusUnauthorized {
+		t.Errorf("Expected 401 for unknown key, got %d", rec.Code)
+	}
+	if rec := f.do(http.MethodDelete, "/v1/quotes/1", "", "X-API-Key", reader); rec.Code != http.StatusForbidden {
+		t.Errorf("Expected 403 for read-only key, got %d", rec.Code)
+	}
+	f.repo.owner = "api-key:2"
+	if rec := f.do(http.MethodDelete, "/v1/quotes/1", "", "Authorization", "Bearer "+writer); rec.Code != http.StatusOK {
+		t.Errorf("Expected 200 for write key, got %d: %s", rec.Code, rec.Body.String())
+	}
+}
+
+func TestAuth_ProtectReads(t *testing.T) {
+	f := newAuthFixture(t, handler.AuthConfig{Enabled: true, ProtectReads: true})
+
+	if rec := f.do(http.MethodGet, "/v1/quotes/random", ""); rec.Code != http.StatusUnauthorized {
+		t.Errorf("Expected 401 for anonymous read, got %d", rec.Code)
+	}
+	if rec := f.do(http.MethodGet, "/v1/quotes/random", "", "X-API-Key", f.issue(t, domain.ScopeRead)); rec.Code != http.StatusOK {
+		t.Errorf("Expected 200 for read key, got %d", rec.Code)
+	}
+}
+
+func TestAuth_AdminKeyManagement(t *testing.T) {
+	// Управление ключами закрыто даже при выключенной аутентификации
+	f := newAuthFixture(t, handler.AuthConfig{Enabled: false})
+	admin := f.issue(t, domain.ScopeAdmin)
+
+	if rec := f.do(http.MethodDelete, "/v1/quotes/1", ""); rec.Code != http.StatusOK {
+		t.Errorf("Expected writes to be open with auth disabled, got %d", rec.Code)
+	}
+	if rec := f.do(http.MethodGet, "/v1/admin/api-keys", ""); rec.Code != http.StatusUnauthorized {
+		t.Errorf("Expected 401 for anonymous admin call, got %d", rec.Code)
+	}
+	if rec := f.do(http.MethodGet, "/v1/admin/api-keys", "", "X-API-Key", f.issue(t, domain.ScopeWrite)); rec.Code != http.StatusForbidden {
+		t.Errorf("Expected 403 for write key, got %d", rec.Code)
+	}
+
+	rec := f.do(http.MethodPost, "/v1/admin/api-keys", `{"name":"deploy","scopes":["write"]}`, "X-API-Key", admin)
+	if rec.Code != http.StatusCreated {
+		t.Fatalf("Expected 201, got %d: %s", rec.Code, rec.Body.String())
+	}
+
+	var issued struct {
+		Data struct {
+			ID     int      `json:"id"`
+			Key    string   `json:"key"`
+			Scopes []string `json:"scopes"`
+		} `json:"data"`
+	}
+	json.NewDecoder(rec.Body).Decode(&issued)
+	if issued.Data.Key == "" || len(issued.Data.Scopes) != 1 {
+		t.Fatalf("Expected issued key in response, got %+v", issued.Data)
+	}
+
+	if rec := f.do(http.MethodDelete, "/v1/admin/api-keys/3", "", "X-API-Key", admin); rec.Code != http.StatusOK {
+		t.Errorf("Expected 200 on revoke, got %d", rec.Code)
+	}
+	if _, err := f.keys.Authenticate(context.Background(), issued.Data.Key); err == nil {
+		t.Errorf("Expected revoked key to be rejected")
+	}
+
+	rec = f.do(http.MethodGet, "/v1/admin/api-keys", "", "X-API-Key", admin)
+	if strings.Contains(rec.Body.String(), issued.Data.Key) || strings.Contains(rec.Body.String(), "hash") {
+		t.Errorf("Key list must not expose secrets: %s", rec.Body.String())
+	}
+}
+
+func TestAuth_WebhooksRequireAdmin(t *testing.T) {
+	// Чтение открыто (AUTH_PROTECT_READS=false), но не для вебхуков
+	f := newAuthFixture(t, handler.AuthConfig{Enabled: true})
+	writer := f.issue(t, domain.ScopeWrite)
+	admin := f.issue(t, domain.ScopeAdmin)
+
+	for _, target := range []string{"/v1/webhooks", "/v1/webhooks/1/deliveries", "/v1/webhooks/dead-letters"} {
+		if rec := f.do(http.MethodGet, target, ""); rec.Code != http.StatusUnauthorized {
+			t.Errorf("Expected 401 for anonymous GET %s, got %d", target, rec.Code)
+		}
+		if rec := f.do(http.MethodGet, target, "", "X-API-Key", writer); rec.Code != http.StatusForbidden {
+			t.Errorf("Expected 403 for write key on GET %s, got %d", target, rec.Code)
+		}
+		if rec := f.do(http.MethodGet, target, "", "X-API-Key", admin); rec.Code != http.StatusOK {
+			t.Errorf("Expected 200 for admin key on GET %s, got %d: %s", target, rec.Code, rec.Body.String())
+		}
+	}
+
+	body := `{"url": "http://169.254.169.254/latest", "events": []}`
+	if rec := f.do(http.MethodPost, "/v1/webhooks", body, "X-API-Key", writer); rec.Code != http.StatusForbidden {
+		t.Errorf("Expected 403 for write key creating subscription, got %d", rec.Code)
+	}
+	if rec := f.do(http.MethodPut, "/v1/webhooks/1", body, "X-API-Key", writer); rec.Code != http.StatusForbidden {
+		t.Errorf("Expected 403 for write key repointing subscription, got %d", rec.Code)
+	}
+	if rec := f.do(http.MethodDelete, "/v1/webhooks/1", "", "X-API-Key", writer); rec.Code != http.StatusForbidden {
+		t.Errorf("Expected 403 for write key deleting subscription, got %d", rec.Code)
+	}
+	if rec := f.do(http.MethodPost, "/v1/webhooks/deliveries/1/redeliver", "", "X-API-Key", writer); rec.Code != http.StatusForbidden {
+		t.Errorf("Expected 403 for write key redelivering, got %d", rec.Code)
+	}
+}
+
+func TestAuth_JWTRoles(t *testing.T) {
+	issuer := newTokenIssuer(t)
+	f := newAuthFixture(t, handler.AuthConfig{Enabled: true, ProtectReads: true}, issuer.verifier(t))
+	body := `{"author":"Seneca","quote":"We suffer more often in imagination than in reality."}`
+
+	f.repo.owner = "jwt:user-editor"
+
+	cases := []struct {
+		subject string
+		role    string
+		method  string
+		target  string
+		status  int
+	}{
+		{"user-viewer", "viewer", http.MethodGet, "/v1/quotes/random", http.StatusOK},
+		{"user-viewer", "viewer", http.MethodPost, "/v1/quotes", http.StatusForbidden},
+		{"user-editor", "editor", http.MethodPost, "/v1/quotes", http.StatusCreated},
+		{"user-editor", "editor", http.MethodPut, "/v1/quotes/1", http.StatusOK},
+		{"user-other", "editor", http.MethodPut, "/v1/quotes/1", http.StatusForbidden},
+		{"user-other", "editor", http.MethodDelete, "/v1/quotes/1", http.StatusForbidden},
+		{"user-editor", "editor", http.MethodDelete, "/v1/quotes/1", http.StatusOK},
+		{"user-admin", "admin", http.MethodDelete, "/v1/quotes/1", http.StatusOK},
+		{"user-unknown", "unknown", http.MethodGet, "/v1/quotes/random", http.StatusForbidden},
+	}
+	for _, tc := range cases {
+		token := issuer.sign(t, tc.subject, tc.role)
+		rec := f.do(tc.method, tc.target, body, "Authorization", "Bearer "+token)
+		if rec.Code != tc.status {
+			t.Errorf("%s %s as %s: expected %d, got %d: %s", tc.method, tc.target, tc.subject, tc.status, rec.Code, rec.Body.String())
+		}
+	}
+
+	// Изменения записываются от имени субъекта токена
+	if len(f.events.events) != 4 {
+		t.Fatalf("Expected 4 events, got %d", len(f.events.events))
+	}
+	if created := f.events.events[0].Quote; created.CreatedBy != "jwt:user-editor" {
+		t.Errorf("Expected quote owned by jwt:user-editor, got %q", created.CreatedBy)
+	}
+	if actor := f.events.events[3].Actor; actor != "jwt:user-admin" {
+		t.Errorf("Expected delete by jwt:user-admin, got %q", actor)
+	}
+
+	// Без настроенного JWKS токен не принимается
+	f = newAuthFixture(t, handler.AuthConfig{Enabled: true})
+	if rec := f.do(http.MethodDelete, "/v1/quotes/1", "", "Authorization", "Bearer "+issuer.sign(t, "root", "admin")); rec.Code != http.StatusUnauthorized {
+		t.Errorf("Expected 401 without JWKS, got %d", rec.Code)
+	}
+}
+
+func TestAuth_CreatedByMe(t *testing.T) {
+	issuer := newTokenIssuer(t)
+	f := newAuthFixture(t, handler.AuthConfig{Enabled: true}, issuer.verifier(t))
+
+	if rec := f.do(http.MethodGet, "/v1/quotes?created_by=me", ""); rec.Code != http.StatusUnauthorized {
+		t.Errorf("Expected 401 for anonymous created_by=me, got %d", rec.Code)
+	}
+	if rec := f.do(http.MethodGet, "/v1/quotes?created_by=me", "", "Authorization", "Bearer invalid.jwt.token"); rec.Code != http.StatusUnauthorized {
+		t.Errorf("Expected 401 for invalid token on open read, got %d", rec.Code)
+	}
+
+	rec := f.do(http.MethodGet, "/v1/quotes?created_by=me", "", "Authorization", "Bearer "+issuer.sign(t, "user-viewer", "viewer"))
+	if rec.Code != http.StatusOK || f.repo.filter.CreatedBy != "jwt:user-viewer" {
+		t.Errorf("Expected filter by caller, got %d %q", rec.Code, f.repo.filter.CreatedBy)
+	}
+}
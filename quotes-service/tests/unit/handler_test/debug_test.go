@@ -0,0 +1,55 @@
+package handler_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"quotes-service/internal/buildinfo"
+	"quotes-service/internal/handler"
+	"quotes-service/internal/infrastructure/logger"
+
+	"github.com/gorilla/mux"
+)
+
+func TestDebugHandler_Endpoints(t *testing.T) {
+	router := mux.NewRouter()
+	config := func() interface{} {
+		return map[string]string{"database_url": "postgres://user:xxxxx@db/quotes"}
+	}
+	handler.NewDebugHandler(nil, config, logger.New("error")).RegisterRoutes(router)
+
+	get := func(path string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, path, nil))
+		return rec
+	}
+
+	for _, path := range []string{"/debug/pprof/", "/debug/pprof/heap", "/debug/vars", "/debug/runtime", "/debug/db", "/debug/config"} {
+		if rec := get(path); rec.Code != http.StatusOK {
+			t.Errorf("Expected 200 for %s, got %d", path, rec.Code)
+		}
+	}
+
+	var vars map[string]json.RawMessage
+	if err := json.NewDecoder(get("/debug/vars").Body).Decode(&vars); err != nil {
+		t.Fatalf("Failed to decode expvar output: %v", err)
+	}
+	for _, name := range []string{"memstats", "runtime", "build"} {
+		if _, ok := vars[name]; !ok {
+			t.Errorf("Expected %q in /debug/vars", name)
+		}
+	}
+
+	var build struct {
+		Data buildinfo.Info `json:"data"`
+	}
+	if err := json.NewDecoder(get("/debug/build").Body).Decode(&build); err != nil {
+		t.Fatalf("Failed to decode build info: %v", err)
+	}
+	if build.Data.Version != buildinfo.Version || !strings.HasPrefix(build.Data.GoVersion, "go") {
+		t.Errorf("Unexpected build info: %+v", build.Data)
+	}
+}
@@ -0,0 +1,243 @@
+package handler_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"quotes-service/internal/domain"
+	"quotes-service/internal/handler"
+	"quotes-service/internal/infrastructure/logger"
+	"quotes-service/internal/service"
+
+	"github.com/gorilla/mux"
+)
+
+// idempotencyRepo хранит ключи в памяти с той же семантикой Claim, что и PostgreSQL
+type idempotencyRepo struct {
+	mu      sync.Mutex
+	records map[string]domain.IdempotencyRecord
+}
+
+func (r *idempotencyRepo) Claim(ctx context.Context, record *domain.IdempotencyRecord) (*domain.IdempotencyRecord, bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if stored, ok := r.records[record.Client+"|"+record.Key]; ok && time.Now().Before(stored.ExpiresAt) {
+		return &stored, false, nil
+	}
+	r.records[record.Client+"|"+record.Key] = *record
+	return record, true, nil
+}
+
+func (r *idempotencyRepo) Complete(ctx context.Context, record *domain.IdempotencyRecord) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.records[record.Client+"|"+record.Key] = *record
+	return nil
+}
+
+func (r *idempotencyRepo) Extend(ctx context.Context, client, key, fingerprint string, expiresAt time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if stored, ok := r.records[client+"|"+key]; ok && stored.Fingerprint == fingerprint && !stored.Completed() {
+		stored.ExpiresAt = expiresAt
+		r.records[client+"|"+key] = stored
+	}
+	return nil
+}
+
+func (r *idempotencyRepo) Release(ctx context.Context, client, key string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if stored, ok := r.records[client+"|"+key]; ok && !stored.Completed() {
+		delete(r.records, client+"|"+key)
+	}
+	return nil
+}
+
+func (r *idempotencyRepo) DeleteExpired(ctx context.Context) (int64, error) {
+	return 0, nil
+}
+
+// createRepo считает созданные цитаты; если задан block, создание сообщает
+// в started и ждет block. failures - число первых попыток с ошибкой
+type createRepo struct {
+	randomRepo
+	mu       sync.Mutex
+	created  int
+	failures int
+	started  chan struct{}
+	block    chan struct{}
+}
+
+func (r *createRepo) Create(ctx context.Context, quote *domain.Quote) (*domain.Quote, error) {
+	if r.block != nil {
+		r.started <- struct{}{}
+		<-r.block
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.failures > 0 {
+		r.failures--
+		return nil, errors.New("connection reset")
+	}
+	r.created++
+	created := *quote
+	created.ID = r.created
+	return &created, nil
+}
+
+func newIdempotentRouter(repo *createRepo) *mux.Router {
+	return newIdempotentRouterWithLock(repo, time.Minute)
+}
+
+func newIdempotentRouterWithLock(repo *createRepo, lockTimeout time.Duration) *mux.Router {
+	log := logger.New("error")
+	idempotency := handler.NewIdempotency(service.NewIdempotencyService(
+		&idempotencyRepo{records: make(map[string]domain.IdempotencyRecord)},
+		service.IdempotencyConfig{Window: time.Hour, LockTimeout: lockTimeout},
+		log,
+	), nil, log)
+
+	router := mux.NewRouter()
+	handler.MountVersions(router, handler.APIVersion{
+		Name:       "v1",
+		Handlers:   []handler.RouteRegistrar{handler.NewQuoteHandler(service.NewQuoteService(repo, log), log)},
+		Middleware: []mux.MiddlewareFunc{idempotency.Middleware},
+	})
+	return router
+}
+
+func postQuote(router http.Handler, key, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/v1/quotes", strings.NewReader(body))
+	req.Header.Set("Idempotency-Key", key)
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	return rec
+}
+
+const quoteBody = `{"author":"Seneca","quote":"Luck is what happens when preparation meets opportunity."}`
+
+func TestIdempotency_ReplaysStoredResponse(t *testing.T) {
+	repo := &createRepo{}
+	router := newIdempotentRouter(repo)
+
+	first := postQuote(router, "req-1", quoteBody)
+	if first.Code != http.StatusCreated {
+		t.Fatalf("Expected 201, got %d: %s", first.Code, first.Body.String())
+	}
+
+	retry := postQuote(router, "req-1", quoteBody)
+	if retry.Code != http.StatusCreated || retry.Body.String() != first.Body.String() {
+		t.Errorf("Expected identical replay, got %d: %s", retry.Code, retry.Body.String())
+	}
+	if retry.Header().Get("Idempotent-Replayed") != "true" || retry.Header().Get("Content-Type") != "application/json" {
+		t.Errorf("Expected replay headers, got %v", retry.Header())
+	}
+	if repo.created != 1 {
+		t.Errorf("Expected one quote, got %d", repo.created)
+	}
+
+	if rec := postQuote(router, "req-1", strings.Replace(quoteBody, "Seneca", "Cato", 1)); rec.Code != http.StatusUnprocessableEntity {
+		t.Errorf("Expected 422 for reused key, got %d", rec.Code)
+	}
+	if rec := postQuote(router, "req-2", quoteBody); rec.Code != http.StatusCreated || repo.created != 2 {
+		t.Errorf("Expected new key to create a quote, got %d", rec.Code)
+	}
+	if rec := postQuote(router, strings.Repeat("k", 256), quoteBody); rec.Code != http.StatusBadRequest {
+		t.Errorf("Expected 400 for oversized key, got %d", rec.Code)
+	}
+}
+
+func TestIdempotency_ServerErrorsAreNotStored(t *testing.T) {
+	repo := &createRepo{failures: 1}
+	router := newIdempotentRouter(repo)
+
+	if rec := postQuote(router, "req-1", quoteBody); rec.Code != http.StatusInternalServerError {
+		t.Fatalf("Expected 500, got %d", rec.Code)
+	}
+	if rec := postQuote(router, "req-1", quoteBody); rec.Code != http.StatusCreated || rec.Header().Get("Idempotent-Replayed") != "" {
+		t.Errorf("Expected retry to execute after a server error, got %d", rec.Code)
+	}
+}
+
+func TestIdempotency_ConcurrentDuplicates(t *testing.T) {
+	repo := &createRepo{started: make(chan struct{}), block: make(chan struct{})}
+	router := newIdempotentRouter(repo)
+
+	done := make(chan *httptest.ResponseRecorder)
+	go func() { done <- postQuote(router, "req-1", quoteBody) }()
+	<-repo.started
+
+	// Пока первый запрос выполняется, дубликаты получают 409
+	var wg sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if rec := postQuote(router, "req-1", quoteBody); rec.Code != http.StatusConflict || rec.Header().Get("Retry-After") == "" {
+				t.Errorf("Expected 409 with Retry-After for in-flight duplicate, got %d", rec.Code)
+			}
+		}()
+	}
+	wg.Wait()
+
+	close(repo.block)
+	if rec := <-done; rec.Code != http.StatusCreated {
+		t.Fatalf("Expected original request to succeed, got %d", rec.Code)
+	}
+	if rec := postQuote(router, "req-1", quoteBody); rec.Code != http.StatusCreated || repo.created != 1 {
+		t.Errorf("Expected replay after completion, got %d with %d quotes", rec.Code, repo.created)
+	}
+}
+
+func TestIdempotency_LongRequestKeepsKey(t *testing.T) {
+	repo := &createRepo{started: make(chan struct{}), block: make(chan struct{})}
+	router := newIdempotentRouterWithLock(repo, 30*time.Millisecond)
+
+	done := make(chan *httptest.ResponseRecorder)
+	go func() { done <- postQuote(router, "req-1", quoteBody) }()
+	<-repo.started
+
+	// Запрос идет дольше LockTimeout, но ключ продлевается и не занимается повтором
+	time.Sleep(100 * time.Millisecond)
+	if rec := postQuote(router, "req-1", quoteBody); rec.Code != http.StatusConflict {
+		t.Errorf("Expected 409 while the first request is still running, got %d", rec.Code)
+	}
+
+	close(repo.block)
+	if rec := <-done; rec.Code != http.StatusCreated || repo.created != 1 {
+		t.Errorf("Expected single creation, got %d with %d quotes", rec.Code, repo.created)
+	}
+}
+
+func TestIdempotency_AnonymousKeysScopedByIP(t *testing.T) {
+	repo := &createRepo{}
+	router := newIdempotentRouter(repo)
+
+	for _, addr := range []string{"192.0.2.1:1234", "198.51.100.7:1234"} {
+		req := httptest.NewRequest(http.MethodPost, "/v1/quotes", strings.NewReader(quoteBody))
+		req.RemoteAddr = addr
+		req.Header.Set("Idempotency-Key", "shared-key")
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+
+		if rec.Code != http.StatusCreated || rec.Header().Get("Idempotent-Replayed") != "" {
+			t.Errorf("Expected fresh response for %s, got %d (replayed %q)", addr, rec.Code, rec.Header().Get("Idempotent-Replayed"))
+		}
+	}
+	if repo.created != 2 {
+		t.Errorf("Expected anonymous clients from different IPs not to share keys, got %d quotes", repo.created)
+	}
+}
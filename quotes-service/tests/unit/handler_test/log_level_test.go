@@ -0,0 +1,67 @@
+package handler_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"quotes-service/internal/auth"
+	"quotes-service/internal/domain"
+	"quotes-service/internal/handler"
+	"quotes-service/internal/infrastructure/logger"
+	"quotes-service/internal/service"
+
+	"github.com/gorilla/mux"
+)
+
+func TestLogLevel_ChangedByAdminAtRuntime(t *testing.T) {
+	log := logger.New("info")
+	keys := service.NewAPIKeyService(&keyRepo{}, log)
+	authenticator := handler.NewAuthenticator(auth.NewAuthenticator(keys, nil), handler.AuthConfig{Enabled: true}, log)
+
+	router := mux.NewRouter()
+	handler.MountVersions(router, handler.APIVersion{
+		Name:     "v1",
+		Handlers: []handler.RouteRegistrar{handler.NewLogLevelHandler(authenticator, log)},
+	})
+
+	issue := func(scope domain.Scope) string {
+		_, raw, err := keys.IssueKey(context.Background(), domain.APIKeyRequest{Name: "test", Scopes: []domain.Scope{scope}})
+		if err != nil {
+			t.Fatalf("Failed to issue key: %v", err)
+		}
+		return raw
+	}
+	admin, writer := issue(domain.ScopeAdmin), issue(domain.ScopeWrite)
+
+	put := func(key, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPut, "/v1/admin/log-level", strings.NewReader(body))
+		req.Header.Set("X-API-Key", key)
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		return rec
+	}
+
+	if rec := put(writer, `{"level":"debug"}`); rec.Code != http.StatusForbidden {
+		t.Errorf("Expected 403 for write key, got %d", rec.Code)
+	}
+	if rec := put(admin, `{"level":"verbose"}`); rec.Code != http.StatusBadRequest {
+		t.Errorf("Expected 400 for unknown level, got %d", rec.Code)
+	}
+
+	rec := put(admin, `{"level":"debug"}`)
+	var body struct {
+		Data struct {
+			Level string `json:"level"`
+		} `json:"data"`
+	}
+	if err := json.NewDecoder(rec.Body).Decode(&body); err != nil || rec.Code != http.StatusOK || body.Data.Level != "debug" {
+		t.Fatalf("Expected level debug, got %d %+v (%v)", rec.Code, body, err)
+	}
+	if log.Level() != "debug" {
+		t.Errorf("Expected logger level to change, got %s", log.Level())
+	}
+}
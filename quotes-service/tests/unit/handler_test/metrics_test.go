@@ -0,0 +1,103 @@
+package handler_test
+
+import (
+	"context"
+	"database/sql"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"quotes-service/internal/domain"
+	"quotes-service/internal/handler"
+	"quotes-service/internal/infrastructure/logger"
+	"quotes-service/internal/metrics"
+	"quotes-service/internal/service"
+
+	"github.com/gorilla/mux"
+	_ "github.com/lib/pq"
+)
+
+// missingRepo не находит цитату 99
+type missingRepo struct {
+	writeRepo
+}
+
+func (r *missingRepo) GetByID(ctx context.Context, id int) (*domain.Quote, error) {
+	if id == 99 {
+		return nil, domain.ErrQuoteNotFound
+	}
+	return r.writeRepo.GetByID(ctx, id)
+}
+
+func TestMetrics_ExposesRequestServiceAndPoolMetrics(t *testing.T) {
+	log := logger.New("error")
+	registry := metrics.NewRegistry()
+
+	// Пул открывается без подключения, статистика доступна сразу
+	db, err := sql.Open("postgres", "host=127.0.0.1 port=1 sslmode=disable")
+	if err != nil {
+		t.Fatalf("Failed to open pool: %v", err)
+	}
+	defer db.Close()
+	db.SetMaxOpenConns(7)
+	if err := registry.RegisterDBPools(map[string]*sql.DB{"primary": db}); err != nil {
+		t.Fatalf("Failed to register pool: %v", err)
+	}
+
+	quoteService := service.NewQuoteService(&missingRepo{}, log, service.WithMetrics(registry))
+	router := mux.NewRouter()
+	handler.MountVersions(router, handler.APIVersion{
+		Name:     "v1",
+		Handlers: []handler.RouteRegistrar{handler.NewQuoteHandler(quoteService, log)},
+	})
+	router.Handle("/metrics", registry.Handler()).Methods("GET")
+	server := handler.InstrumentRouter(router, registry)
+
+	requests := []struct {
+		method string
+		target string
+		body   string
+		status int
+	}{
+		{http.MethodPost, "/v1/quotes", `{"author":"Seneca","quote":"Luck is preparation."}`, http.StatusCreated},
+		{http.MethodGet, "/v1/quotes/random", "", http.StatusOK},
+		{http.MethodGet, "/v1/quotes/random", "", http.StatusOK},
+		{http.MethodDelete, "/v1/quotes/2", "", http.StatusOK},
+		{http.MethodDelete, "/v1/quotes/99", "", http.StatusNotFound},
+		{http.MethodGet, "/v1/unknown/path", "", http.StatusNotFound},
+	}
+	for _, r := range requests {
+		rec := httptest.NewRecorder()
+		server.ServeHTTP(rec, httptest.NewRequest(r.method, r.target, strings.NewReader(r.body)))
+		if rec.Code != r.status {
+			t.Fatalf("%s %s: expected %d, got %d", r.method, r.target, r.status, rec.Code)
+		}
+	}
+
+	rec := httptest.NewRecorder()
+	server.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	body, _ := io.ReadAll(rec.Body)
+	exposition := string(body)
+
+	for _, line := range []string{
+		`quotes_http_requests_total{method="GET",route="/v1/quotes/random",status="200"} 2`,
+		`quotes_http_requests_total{method="DELETE",route="/v1/quotes/{id}",status="200"} 1`,
+		`quotes_http_requests_total{method="DELETE",route="/v1/quotes/{id}",status="404"} 1`,
+		`quotes_http_requests_total{method="GET",route="unmatched",status="404"} 1`,
+		`quotes_http_request_duration_seconds_count{method="POST",route="/v1/quotes",status="201"} 1`,
+		`quotes_created_total 1`,
+		`quotes_deleted_total 1`,
+		`quotes_random_served_total 2`,
+		`quotes_not_found_total{operation="delete"} 1`,
+		`go_sql_max_open_connections{db_name="primary"} 7`,
+	} {
+		if !strings.Contains(exposition, line+"\n") {
+			t.Errorf("Expected metric %q in exposition", line)
+		}
+	}
+	if strings.Contains(exposition, "/v1/quotes/99") || strings.Contains(exposition, "/v1/unknown/path") {
+		t.Errorf("Raw paths must not be used as labels")
+	}
+}
@@ -0,0 +1,225 @@
+package handler_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"testing"
+	"time"
+
+	"quotes-service/internal/auth"
+	"quotes-service/internal/domain"
+	"quotes-service/internal/handler"
+	"quotes-service/internal/infrastructure/logger"
+	"quotes-service/internal/ratelimit"
+	"quotes-service/internal/service"
+
+	"github.com/gorilla/mux"
+)
+
+func newRateLimitedRouter(t *testing.T) (*mux.Router, *service.APIKeyService) {
+	t.Helper()
+
+	log := logger.New("error")
+	keys := service.NewAPIKeyService(&keyRepo{}, log)
+	authenticator := handler.NewAuthenticator(auth.NewAuthenticator(keys, nil), handler.AuthConfig{Enabled: true}, log)
+	limiter := handler.NewRateLimiter(ratelimit.NewLimiter(100), handler.RateLimitConfig{
+		Rules:          []ratelimit.Rule{{Method: "GET", Path: "/quotes/random", Requests: 2, Period: time.Minute}},
+		TrustedProxies: []netip.Prefix{netip.MustParsePrefix("10.0.0.0/8")},
+	}, log)
+
+	v1 := handler.APIVersion{
+		Name:       "v1",
+		Handlers:   []handler.RouteRegistrar{handler.NewQuoteHandler(service.NewQuoteService(&writeRepo{}, log), log)},
+		Middleware: []mux.MiddlewareFunc{authenticator.Middleware, limiter.Middleware},
+	}
+	router := mux.NewRouter()
+	handler.MountVersions(router, v1)
+	handler.MountDeprecatedAliases(router, v1, handler.Deprecation{})
+	return router, keys
+}
+
+func getFrom(router http.Handler, target, remoteAddr string, header ...string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, target, nil)
+	req.RemoteAddr = remoteAddr
+	for i := 0; i+1 < len(header); i += 2 {
+		req.Header.Set(header[i], header[i+1])
+	}
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+	return rec
+}
+
+func TestRateLimit_LimitsPerClient(t *testing.T) {
+	router, _ := newRateLimitedRouter(t)
+
+	rec := getFrom(router, "/v1/quotes/random", "192.0.2.1:5000")
+	if rec.Code != http.StatusOK || rec.Header().Get("RateLimit-Limit") != "2" || rec.Header().Get("RateLimit-Remaining") != "1" {
+		t.Fatalf("Expected limit headers, got %d %v", rec.Code, rec.Header())
+	}
+	if rec.Header().Get("RateLimit-Policy") != "2;w=60" {
+		t.Errorf("Expected policy 2;w=60, got %q", rec.Header().Get("RateLimit-Policy"))
+	}
+
+	// Устаревший алиас расходует ту же квоту
+	getFrom(router, "/quotes/random", "192.0.2.1:5001")
+	rec = getFrom(router, "/v1/quotes/random", "192.0.2.1:5002")
+	if rec.Code != http.StatusTooManyRequests {
+		t.Fatalf("Expected 429, got %d", rec.Code)
+	}
+	if rec.Header().Get("Retry-After") != "30" || rec.Header().Get("RateLimit-Remaining") != "0" {
+		t.Errorf("Expected Retry-After 30, got %v", rec.Header())
+	}
+
+	if rec := getFrom(router, "/v1/quotes/random", "192.0.2.2:5000"); rec.Code != http.StatusOK {
+		t.Errorf("Expected another IP to have its own quota, got %d", rec.Code)
+	}
+	if rec := getFrom(router, "/v1/quotes", "192.0.2.1:5000"); rec.Code != http.StatusOK || rec.Header().Get("RateLimit-Limit") != "" {
+		t.Errorf("Expected routes without rules to be unlimited, got %d %v", rec.Code, rec.Header())
+	}
+}
+
+func TestRateLimit_ClientIdentity(t *testing.T) {
+	router, keys := newRateLimitedRouter(t)
+	exhaust := func(remoteAddr string, header ...string) {
+		for i := 0; i < 2; i++ {
+			getFrom(router, "/v1/quotes/random", remoteAddr, header...)
+		}
+	}
+
+	// X-Forwarded-For от недоверенного адреса игнорируется
+	exhaust("192.0.2.1:5000", "X-Forwarded-For", "198.51.100.1")
+	if rec := getFrom(router, "/v1/quotes/random", "192.0.2.1:5000", "X-Forwarded-For", "198.51.100.2"); rec.Code != http.StatusTooManyRequests {
+		t.Errorf("Expected spoofed X-Forwarded-For to be ignored, got %d", rec.Code)
+	}
+
+	// За доверенным прокси клиент - ближайший недоверенный адрес справа
+	exhaust("10.0.0.5:5000", "X-Forwarded-For", "203.0.113.9, 198.51.100.7, 10.0.0.4")
+	if rec := getFrom(router, "/v1/quotes/random", "10.0.0.6:5000", "X-Forwarded-For", "1.2.3.4, 198.51.100.7"); rec.Code != http.StatusTooManyRequests {
+		t.Errorf("Expected client behind trusted proxy to be limited by its address, got %d", rec.Code)
+	}
+	if rec := getFrom(router, "/v1/quotes/random", "10.0.0.6:5000", "X-Real-IP", "198.51.100.8"); rec.Code != http.StatusOK {
+		t.Errorf("Expected X-Real-IP from trusted proxy to identify client, got %d", rec.Code)
+	}
+
+	// API-ключ получает свою квоту независимо от адреса
+	_, key, _ := keys.IssueKey(context.Background(), domain.APIKeyRequest{Name: "ci", Scopes: []domain.Scope{domain.ScopeRead}})
+	if rec := getFrom(router, "/v1/quotes/random", "192.0.2.1:5000", "X-API-Key", key); rec.Code != http.StatusOK {
+		t.Errorf("Expected API key to have its own quota, got %d", rec.Code)
+	}
+}
+
+func TestRateLimit_UpdateAppliesWithoutRestart(t *testing.T) {
+	log := logger.New("error")
+	limiter := handler.NewRateLimiter(ratelimit.NewLimiter(100), handler.RateLimitConfig{}, log)
+	router := mux.NewRouter()
+	handler.MountVersions(router, handler.APIVersion{
+		Name:       "v1",
+		Handlers:   []handler.RouteRegistrar{handler.NewQuoteHandler(service.NewQuoteService(&writeRepo{}, log), log)},
+		Middleware: []mux.MiddlewareFunc{limiter.Middleware},
+	})
+
+	if rec := getFrom(router, "/v1/quotes/random", "192.0.2.1:5000"); rec.Header().Get("RateLimit-Limit") != "" {
+		t.Fatalf("Expected no limits without rules, got %v", rec.Header())
+	}
+
+	limiter.Update(handler.RateLimitConfig{
+		Rules: []ratelimit.Rule{{Method: "GET", Path: "/quotes/random", Requests: 1, Period: time.Minute}},
+	})
+	getFrom(router, "/v1/quotes/random", "192.0.2.1:5000")
+	if rec := getFrom(router, "/v1/quotes/random", "192.0.2.1:5000"); rec.Code != http.StatusTooManyRequests {
+		t.Fatalf("Expected updated rule to apply, got %d", rec.Code)
+	}
+
+	// Выключение лимитов - пустой набор правил
+	limiter.Update(handler.RateLimitConfig{})
+	if rec := getFrom(router, "/v1/quotes/random", "192.0.2.1:5000"); rec.Code != http.StatusOK {
+		t.Errorf("Expected limits to be removed, got %d", rec.Code)
+	}
+}
+
+func TestRateLimit_MatchesRouteVariables(t *testing.T) {
+	rules, err := ratelimit.ParseRules("DELETE /quotes/{id}=1/1h")
+	if err != nil {
+		t.Fatalf("Failed to parse rules: %v", err)
+	}
+	log := logger.New("error")
+	limiter := handler.NewRateLimiter(ratelimit.NewLimiter(100), handler.RateLimitConfig{Rules: rules}, log)
+	v1 := handler.APIVersion{
+		Name:       "v1",
+		Handlers:   []handler.RouteRegistrar{handler.NewQuoteHandler(service.NewQuoteService(&writeRepo{}, log), log)},
+		Middleware: []mux.MiddlewareFunc{limiter.Middleware},
+	}
+	router := mux.NewRouter()
+	handler.MountVersions(router, v1)
+	handler.MountDeprecatedAliases(router, v1, handler.Deprecation{})
+
+	deleteFrom := func(target string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodDelete, target, nil)
+		req.RemoteAddr = "192.0.2.1:5000"
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		return rec
+	}
+
+	if rec := deleteFrom("/v1/quotes/1"); rec.Header().Get("RateLimit-Limit") != "1" {
+		t.Fatalf("Expected {id} rule to match /v1/quotes/1, got %d %v", rec.Code, rec.Header())
+	}
+	// Другой id и устаревший алиас расходуют ту же квоту маршрута
+	if rec := deleteFrom("/quotes/2"); rec.Code != http.StatusTooManyRequests {
+		t.Errorf("Expected 429 for the same route template, got %d", rec.Code)
+	}
+}
+
+// countingKeyRepo считает обращения к "базе" при проверке ключа
+type countingKeyRepo struct {
+	keyRepo
+	lookups int
+}
+
+func (r *countingKeyRepo) GetByPrefix(ctx context.Context, prefix string) (*domain.APIKey, error) {
+	r.lookups++
+	return r.keyRepo.GetByPrefix(ctx, prefix)
+}
+
+func TestRateLimit_ThrottlesFailedAuthentication(t *testing.T) {
+	log := logger.New("error")
+	repo := &countingKeyRepo{}
+	keys := service.NewAPIKeyService(repo, log)
+	limiter := handler.NewRateLimiter(ratelimit.NewLimiter(100), handler.RateLimitConfig{
+		AuthFailures: ratelimit.Rule{Requests: 2, Period: time.Minute},
+	}, log)
+	authenticator := handler.NewAuthenticator(auth.NewAuthenticator(keys, nil), handler.AuthConfig{Enabled: true, ProtectReads: true}, log,
+		handler.WithFailureLimit(limiter))
+	router := mux.NewRouter()
+	handler.MountVersions(router, handler.APIVersion{
+		Name:       "v1",
+		Handlers:   []handler.RouteRegistrar{handler.NewQuoteHandler(service.NewQuoteService(&writeRepo{}, log), log)},
+		Middleware: []mux.MiddlewareFunc{authenticator.Middleware, limiter.Middleware},
+	})
+
+	_, key, _ := keys.IssueKey(context.Background(), domain.APIKeyRequest{Name: "ci", Scopes: []domain.Scope{domain.ScopeRead}})
+	bogus := key[:len(key)-1] + "x"
+	if bogus == key {
+		bogus = key[:len(key)-1] + "y"
+	}
+
+	for i := 0; i < 2; i++ {
+		if rec := getFrom(router, "/v1/quotes", "192.0.2.1:5000", "X-API-Key", bogus); rec.Code != http.StatusUnauthorized {
+			t.Fatalf("Expected 401 for bogus key, got %d", rec.Code)
+		}
+	}
+	lookups := repo.lookups
+	rec := getFrom(router, "/v1/quotes", "192.0.2.1:5000", "X-API-Key", bogus)
+	if rec.Code != http.StatusTooManyRequests || rec.Header().Get("Retry-After") != "30" {
+		t.Fatalf("Expected 429 with Retry-After after failed attempts, got %d %v", rec.Code, rec.Header())
+	}
+	if repo.lookups != lookups {
+		t.Errorf("Expected throttled request not to look up the key, got %d lookups", repo.lookups-lookups)
+	}
+
+	if rec := getFrom(router, "/v1/quotes", "192.0.2.2:5000", "X-API-Key", key); rec.Code != http.StatusOK {
+		t.Errorf("Expected another IP to authenticate, got %d", rec.Code)
+	}
+}
@@ -0,0 +1,32 @@
+package handler_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"quotes-service/internal/handler"
+)
+
+func TestHTTPSRedirect(t *testing.T) {
+	cases := []struct {
+		address, host, target, location string
+	}{
+		{":8443", "quotes.example.com:8080", "/v1/quotes?author=Seneca", "https://quotes.example.com:8443/v1/quotes?author=Seneca"},
+		{":443", "quotes.example.com", "/v1/quotes/random", "https://quotes.example.com/v1/quotes/random"},
+		{":443", "[::1]:8080", "/health", "https://[::1]/health"},
+	}
+	for _, c := range cases {
+		req := httptest.NewRequest(http.MethodPost, c.target, nil)
+		req.Host = c.host
+		rec := httptest.NewRecorder()
+		handler.HTTPSRedirect(c.address).ServeHTTP(rec, req)
+
+		if rec.Code != http.StatusPermanentRedirect {
+			t.Errorf("%s: expected 308, got %d", c.host, rec.Code)
+		}
+		if location := rec.Header().Get("Location"); location != c.location {
+			t.Errorf("%s: expected %s, got %s", c.host, c.location, location)
+		}
+	}
+}
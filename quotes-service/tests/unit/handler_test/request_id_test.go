@@ -0,0 +1,92 @@
+package handler_test
+
+import (
+	"bufio"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"quotes-service/internal/domain"
+	"quotes-service/internal/handler"
+	"quotes-service/internal/infrastructure/logger"
+)
+
+// captureLogs перенаправляет stdout, куда пишет logger.New, во временный файл;
+// логгер нужно создать после вызова
+func captureLogs(t *testing.T) func() []map[string]interface{} {
+	t.Helper()
+
+	file, err := os.CreateTemp(t.TempDir(), "log")
+	if err != nil {
+		t.Fatalf("Failed to create log file: %v", err)
+	}
+	stdout := os.Stdout
+	os.Stdout = file
+	t.Cleanup(func() { os.Stdout = stdout })
+
+	return func() []map[string]interface{} {
+		if _, err := file.Seek(0, 0); err != nil {
+			t.Fatalf("Failed to read logs: %v", err)
+		}
+		var records []map[string]interface{}
+		scanner := bufio.NewScanner(file)
+		for scanner.Scan() {
+			var record map[string]interface{}
+			if err := json.Unmarshal(scanner.Bytes(), &record); err == nil {
+				records = append(records, record)
+			}
+		}
+		return records
+	}
+}
+
+func TestRequestID_EchoedInResponsesAndLogs(t *testing.T) {
+	logs := captureLogs(t)
+	f := newAuthFixture(t, handler.AuthConfig{Enabled: true})
+	writer := f.issue(t, domain.ScopeWrite)
+	f.repo.owner = "someone-else"
+	// Поле route заполняет middleware логирования запросов
+	handler.NewQuoteHandler(nil, logger.New("info")).RegisterMiddleware(f.router)
+	server := handler.RequestID(f.router)
+
+	req := httptest.NewRequest(http.MethodDelete, "/v1/quotes/1", nil)
+	req.Header.Set("X-Request-ID", "req-42")
+	req.Header.Set("X-API-Key", writer)
+	rec := httptest.NewRecorder()
+	server.ServeHTTP(rec, req)
+
+	var body handler.Response
+	if err := json.NewDecoder(rec.Body).Decode(&body); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if rec.Code != http.StatusForbidden || rec.Header().Get("X-Request-ID") != "req-42" || body.RequestID != "req-42" {
+		t.Fatalf("Expected 403 with request ID in header and body, got %d %q %+v", rec.Code, rec.Header().Get("X-Request-ID"), body)
+	}
+
+	messages := map[string]bool{"Failed to delete quote": false, "HTTP request": false}
+	for _, record := range logs() {
+		if _, ok := messages[record["msg"].(string)]; !ok {
+			continue
+		}
+		messages[record["msg"].(string)] = true
+		if record["request_id"] != "req-42" || record["route"] != "/v1/quotes/{id}" || record["principal"] != "api_key:api-key:1" {
+			t.Errorf("Expected request fields in log, got %v", record)
+		}
+	}
+	for msg, found := range messages {
+		if !found {
+			t.Errorf("Expected %q log record", msg)
+		}
+	}
+
+	// Небезопасный идентификатор заменяется сгенерированным
+	req = httptest.NewRequest(http.MethodGet, "/v1/quotes/random", nil)
+	req.Header.Set("X-Request-ID", "bad id")
+	rec = httptest.NewRecorder()
+	server.ServeHTTP(rec, req)
+	if id := rec.Header().Get("X-Request-ID"); len(id) != 32 {
+		t.Errorf("Expected generated request ID, got %q", id)
+	}
+}
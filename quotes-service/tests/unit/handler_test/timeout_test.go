@@ -0,0 +1,100 @@
+package handler_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"quotes-service/internal/domain"
+	"quotes-service/internal/handler"
+	"quotes-service/internal/infrastructure/logger"
+	"quotes-service/internal/service"
+
+	"github.com/gorilla/mux"
+)
+
+// slowRepo ждет отмены контекста и, как pq, возвращает собственную ошибку
+type slowRepo struct {
+	domain.QuoteRepository
+}
+
+func (r *slowRepo) GetRandom(ctx context.Context) (*domain.Quote, error) {
+	<-ctx.Done()
+	return nil, errors.New("pq: canceling statement due to user request")
+}
+
+func newTimeoutRouter(timeouts *handler.Timeouts, options ...service.Option) *mux.Router {
+	log := logger.New("error")
+	quoteHandler := handler.NewQuoteHandler(service.NewQuoteService(&slowRepo{}, log, options...), log)
+
+	router := mux.NewRouter()
+	handler.MountVersions(router, handler.APIVersion{
+		Name:       "v1",
+		Handlers:   []handler.RouteRegistrar{quoteHandler},
+		Middleware: []mux.MiddlewareFunc{timeouts.Middleware},
+	})
+	return router
+}
+
+func assertTimedOut(t *testing.T, router http.Handler) {
+	t.Helper()
+	rec := get(router, "/v1/quotes/random")
+	if rec.Code != http.StatusGatewayTimeout {
+		t.Fatalf("Expected 504, got %d: %s", rec.Code, rec.Body.String())
+	}
+	var response handler.Response
+	if err := json.NewDecoder(rec.Body).Decode(&response); err != nil {
+		t.Fatalf("Failed to decode response: %v", err)
+	}
+	if !strings.Contains(response.Error, "request timed out") {
+		t.Errorf("Expected timeout error, got %q", response.Error)
+	}
+}
+
+func TestTimeouts_RouteDeadlineReturns504(t *testing.T) {
+	timeouts := handler.NewTimeouts(handler.TimeoutConfig{
+		Default: time.Minute,
+		Routes:  map[string]time.Duration{"GET /quotes/random": 20 * time.Millisecond},
+	})
+	assertTimedOut(t, newTimeoutRouter(timeouts))
+}
+
+func TestTimeouts_OperationDeadlineReturns504(t *testing.T) {
+	operations := service.DefaultTimeouts()
+	operations.Random = 20 * time.Millisecond
+	timeouts := handler.NewTimeouts(handler.TimeoutConfig{})
+
+	assertTimedOut(t, newTimeoutRouter(timeouts, service.WithTimeouts(operations)))
+}
+
+func TestTimeouts_UpdateAppliesToNextRequest(t *testing.T) {
+	timeouts := handler.NewTimeouts(handler.TimeoutConfig{Default: time.Minute})
+	router := newTimeoutRouter(timeouts)
+
+	timeouts.Update(handler.TimeoutConfig{Default: 20 * time.Millisecond})
+	start := time.Now()
+	assertTimedOut(t, router)
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("Updated timeout was not applied, request took %s", elapsed)
+	}
+}
+
+func TestParseRouteTimeouts(t *testing.T) {
+	routes, err := handler.ParseRouteTimeouts("get /quotes=15s, GET /webhooks/{id}/deliveries=0s")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if routes["GET /quotes"] != 15*time.Second || routes["GET /webhooks/{id}/deliveries"] != 0 || len(routes) != 2 {
+		t.Errorf("Unexpected routes: %v", routes)
+	}
+
+	for _, spec := range []string{"GET /quotes", "/quotes=5s", "GET /quotes=fast", "GET /quotes=-1s"} {
+		if _, err := handler.ParseRouteTimeouts(spec); err == nil {
+			t.Errorf("Expected error for %q", spec)
+		}
+	}
+}
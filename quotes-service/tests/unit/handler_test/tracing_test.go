@@ -0,0 +1,90 @@
+package handler_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"quotes-service/internal/handler"
+	"quotes-service/internal/infrastructure/logger"
+	"quotes-service/internal/service"
+
+	"github.com/gorilla/mux"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func spanAttribute(span sdktrace.ReadOnlySpan, key attribute.Key) attribute.Value {
+	for _, attr := range span.Attributes() {
+		if attr.Key == key {
+			return attr.Value
+		}
+	}
+	return attribute.Value{}
+}
+
+// Провайдер задается только здесь: tracer сервиса привязывается к первому
+// глобальному провайдеру
+func TestTracing_SpansFollowTraceparent(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+
+	log := logger.New("error")
+	quoteService := service.NewQuoteService(&writeRepo{}, log)
+	router := mux.NewRouter()
+	handler.MountVersions(router, handler.APIVersion{
+		Name:     "v1",
+		Handlers: []handler.RouteRegistrar{handler.NewQuoteHandler(quoteService, log)},
+	})
+	tracing := handler.NewTracing()
+	router.Use(tracing.RouteMiddleware)
+	server := tracing.Handler(router)
+
+	const traceID = "4bf92f3577b34da6a3ce929d0e0e4736"
+	req := httptest.NewRequest(http.MethodDelete, "/v1/quotes/2", nil)
+	req.Header.Set("traceparent", "00-"+traceID+"-00f067aa0ba902b7-01")
+	rec := httptest.NewRecorder()
+	server.ServeHTTP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d", rec.Code)
+	}
+
+	rec = httptest.NewRecorder()
+	server.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/v1/missing", nil))
+
+	spans := make(map[string]sdktrace.ReadOnlySpan)
+	for _, span := range recorder.Ended() {
+		spans[span.Name()] = span
+	}
+
+	serverSpan, ok := spans["DELETE /v1/quotes/{id}"]
+	if !ok {
+		t.Fatalf("Expected server span named by route template, got %v", spans)
+	}
+	if serverSpan.SpanContext().TraceID().String() != traceID {
+		t.Errorf("Expected trace %s from traceparent, got %s", traceID, serverSpan.SpanContext().TraceID())
+	}
+	if serverSpan.Parent().SpanID().String() != "00f067aa0ba902b7" {
+		t.Errorf("Expected remote parent span, got %s", serverSpan.Parent().SpanID())
+	}
+	if route := spanAttribute(serverSpan, "http.route").AsString(); route != "/v1/quotes/{id}" {
+		t.Errorf("Expected http.route /v1/quotes/{id}, got %q", route)
+	}
+	if status := spanAttribute(serverSpan, "http.response.status_code").AsInt64(); status != http.StatusOK {
+		t.Errorf("Expected status attribute 200, got %d", status)
+	}
+
+	serviceSpan, ok := spans["QuoteService.DeleteQuote"]
+	if !ok || serviceSpan.Parent().SpanID() != serverSpan.SpanContext().SpanID() {
+		t.Errorf("Expected service span as child of the server span")
+	}
+
+	unmatched, ok := spans[http.MethodGet]
+	if !ok || spanAttribute(unmatched, "http.response.status_code").AsInt64() != http.StatusNotFound {
+		t.Errorf("Expected unmatched request span without route, got %v", spans)
+	}
+}
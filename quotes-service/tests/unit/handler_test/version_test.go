@@ -0,0 +1,131 @@
+package handler_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"quotes-service/internal/domain"
+	"quotes-service/internal/handler"
+	"quotes-service/internal/infrastructure/logger"
+	"quotes-service/internal/service"
+
+	"github.com/gorilla/mux"
+)
+
+// randomRepo отдает одну и ту же цитату на GetRandom и всегда здоров
+type randomRepo struct {
+	domain.QuoteRepository
+}
+
+func (r *randomRepo) GetRandom(ctx context.Context) (*domain.Quote, error) {
+	return &domain.Quote{ID: 1, Author: "Seneca", Text: "Luck is what happens when preparation meets opportunity."}, nil
+}
+
+func (r *randomRepo) HealthCheck(ctx context.Context) error {
+	return nil
+}
+
+// shapeV2 - обработчик другой версии поверх того же сервиса
+type shapeV2 struct {
+	service *service.QuoteService
+}
+
+func (h *shapeV2) RegisterRoutes(router *mux.Router) {
+	router.HandleFunc("/quotes/random", func(w http.ResponseWriter, r *http.Request) {
+		quote, _ := h.service.GetRandomQuote(r.Context())
+		json.NewEncoder(w).Encode(map[string]string{"by": quote.Author})
+	}).Methods("GET")
+}
+
+func newVersionedRouter() *mux.Router {
+	log := logger.New("error")
+	svc := service.NewQuoteService(&randomRepo{}, log)
+	quoteHandler := handler.NewQuoteHandler(svc, log)
+
+	router := mux.NewRouter()
+	quoteHandler.RegisterMiddleware(router)
+	quoteHandler.RegisterHealthRoutes(router)
+
+	v1 := handler.APIVersion{Name: "v1", Handlers: []handler.RouteRegistrar{quoteHandler}}
+	handler.MountVersions(router, v1, handler.APIVersion{Name: "v2", Handlers: []handler.RouteRegistrar{&shapeV2{service: svc}}})
+	handler.MountDeprecatedAliases(router, v1, handler.Deprecation{
+		Since:  time.Date(2026, time.October, 18, 0, 0, 0, 0, time.UTC),
+		Sunset: time.Date(2027, time.April, 18, 0, 0, 0, 0, time.UTC),
+	})
+	return router
+}
+
+func get(router http.Handler, target string) *httptest.ResponseRecorder {
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, target, nil))
+	return rec
+}
+
+func TestVersions_V1HasNoDeprecationHeaders(t *testing.T) {
+	rec := get(newVersionedRouter(), "/v1/quotes/random")
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d", rec.Code)
+	}
+	if rec.Header().Get("Deprecation") != "" || rec.Header().Get("Sunset") != "" {
+		t.Errorf("Versioned route must not be deprecated, got %v", rec.Header())
+	}
+}
+
+func TestVersions_LegacyAliasIsDeprecated(t *testing.T) {
+	rec := get(newVersionedRouter(), "/quotes/random")
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected 200, got %d", rec.Code)
+	}
+	if got := rec.Header().Get("Deprecation"); got != "@1792281600" {
+		t.Errorf("Unexpected Deprecation header %q", got)
+	}
+	if got := rec.Header().Get("Sunset"); got != "Sun, 18 Apr 2027 00:00:00 GMT" {
+		t.Errorf("Unexpected Sunset header %q", got)
+	}
+	if got := rec.Header().Get("Link"); got != `</v1/quotes/random>; rel="successor-version"` {
+		t.Errorf("Unexpected Link header %q", got)
+	}
+
+	var response handler.Response
+	if err := json.NewDecoder(rec.Body).Decode(&response); err != nil || response.Data == nil {
+		t.Errorf("Alias must serve the v1 response, got %v (%v)", response, err)
+	}
+}
+
+func TestVersions_CoexistOnSameService(t *testing.T) {
+	rec := get(newVersionedRouter(), "/v2/quotes/random")
+
+	var body map[string]string
+	if err := json.NewDecoder(rec.Body).Decode(&body); err != nil || body["by"] != "Seneca" {
+		t.Errorf("Expected v2 response shape, got %v (%v)", body, err)
+	}
+}
+
+func TestVersions_HealthIsUnversioned(t *testing.T) {
+	router := newVersionedRouter()
+
+	if rec := get(router, "/health"); rec.Code != http.StatusOK || rec.Header().Get("Deprecation") != "" {
+		t.Errorf("Expected undeprecated health check, got %d %v", rec.Code, rec.Header())
+	}
+	if rec := get(router, "/v1/health"); rec.Code != http.StatusNotFound {
+		t.Errorf("Expected 404 for versioned health, got %d", rec.Code)
+	}
+}
+
+func TestVersions_MethodNotAllowed(t *testing.T) {
+	router := newVersionedRouter()
+
+	for _, target := range []string{"/v1/quotes", "/quotes"} {
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest(http.MethodPatch, target, nil))
+		if rec.Code != http.StatusMethodNotAllowed {
+			t.Errorf("Expected 405 for PATCH %s, got %d", target, rec.Code)
+		}
+	}
+}
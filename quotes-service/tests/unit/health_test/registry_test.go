@@ -0,0 +1,94 @@
+package health_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"quotes-service/internal/health"
+)
+
+func TestReadinessFailsDuringShutdown(t *testing.T) {
+	registry := health.NewRegistry(time.Second)
+	registry.Register("ok", func(ctx context.Context) (interface{}, error) { return nil, nil }, health.Readiness)
+
+	if report := registry.Run(context.Background(), health.Readiness); report.Status != health.StatusOK {
+		t.Fatalf("expected ok, got %+v", report)
+	}
+
+	registry.SetShuttingDown()
+	report := registry.Run(context.Background(), health.Readiness)
+	if report.Status != health.StatusFail || report.Reason != "shutting down" {
+		t.Fatalf("expected readiness to fail during shutdown, got %+v", report)
+	}
+	if report := registry.Run(context.Background(), health.Liveness); report.Status != health.StatusOK {
+		t.Fatalf("liveness must stay ok during shutdown, got %+v", report)
+	}
+}
+
+func TestLastErrorIsKeptAfterRecovery(t *testing.T) {
+	registry := health.NewRegistry(time.Second)
+	fail := true
+	registry.Register("db", func(ctx context.Context) (interface{}, error) {
+		if fail {
+			return nil, errors.New("connection refused")
+		}
+		return nil, nil
+	}, health.Readiness)
+
+	report := registry.Run(context.Background(), health.Readiness)
+	if report.Status != health.StatusFail || report.Checks[0].Error != "connection refused" {
+		t.Fatalf("expected failing check, got %+v", report)
+	}
+
+	fail = false
+	report = registry.Run(context.Background(), health.Readiness)
+	check := report.Checks[0]
+	if report.Status != health.StatusOK || check.Error != "" {
+		t.Fatalf("expected recovered check, got %+v", report)
+	}
+	if check.LastError != "connection refused" || check.LastErrorAt == nil {
+		t.Fatalf("expected last error to be kept, got %+v", check)
+	}
+}
+
+func TestCheckTimeout(t *testing.T) {
+	registry := health.NewRegistry(10 * time.Millisecond)
+	registry.Register("slow", func(ctx context.Context) (interface{}, error) {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}, health.Readiness)
+
+	report := registry.Run(context.Background(), health.Readiness)
+	if report.Status != health.StatusFail {
+		t.Fatalf("expected timeout to fail the probe, got %+v", report)
+	}
+}
+
+func TestOptionalCheckDoesNotFailProbe(t *testing.T) {
+	registry := health.NewRegistry(time.Second)
+	registry.RegisterOptional("outbox", func(ctx context.Context) (interface{}, error) {
+		return nil, errors.New("lag too high")
+	}, health.Readiness)
+
+	report := registry.Run(context.Background(), health.Readiness)
+	if report.Status != health.StatusOK || report.Checks[0].Status != health.StatusWarn {
+		t.Fatalf("expected warn without failing probe, got %+v", report)
+	}
+}
+
+func TestStartupIsCachedAfterSuccess(t *testing.T) {
+	registry := health.NewRegistry(time.Second)
+	calls := 0
+	registry.Register("migrations", func(ctx context.Context) (interface{}, error) {
+		calls++
+		return nil, nil
+	}, health.Startup)
+
+	registry.Run(context.Background(), health.Startup)
+	registry.Run(context.Background(), health.Startup)
+	if calls != 1 {
+		t.Fatalf("expected startup checks to run once, ran %d times", calls)
+	}
+}
@@ -0,0 +1,147 @@
+package logger_test
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"quotes-service/internal/infrastructure/logger"
+)
+
+func newFileLogger(t *testing.T, config logger.Config) (*logger.Logger, string) {
+	t.Helper()
+
+	config.Output = filepath.Join(t.TempDir(), "service.log")
+	log, err := logger.NewWithConfig(config)
+	if err != nil {
+		t.Fatalf("Failed to create logger: %v", err)
+	}
+	t.Cleanup(func() { log.Close() })
+	return log, config.Output
+}
+
+func readRecords(t *testing.T, path string) []map[string]interface{} {
+	t.Helper()
+
+	file, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("Failed to open log: %v", err)
+	}
+	defer file.Close()
+
+	var records []map[string]interface{}
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var record map[string]interface{}
+		if err := json.Unmarshal(scanner.Bytes(), &record); err != nil {
+			t.Fatalf("Invalid JSON log line %q: %v", scanner.Text(), err)
+		}
+		records = append(records, record)
+	}
+	return records
+}
+
+func TestLogger_RedactsKeysAndReportsCaller(t *testing.T) {
+	log, path := newFileLogger(t, logger.Config{Level: "info", AddSource: true, RedactKeys: []string{"secret", "Authorization"}})
+
+	log.With("component", "webhooks").Info("Subscription created", "secret", "s3cr3t", "authorization", "Bearer abc", "id", 7)
+
+	records := readRecords(t, path)
+	if len(records) != 1 {
+		t.Fatalf("Expected one record, got %d", len(records))
+	}
+	record := records[0]
+	if record["secret"] != "[REDACTED]" || record["authorization"] != "[REDACTED]" || record["id"] != float64(7) || record["component"] != "webhooks" {
+		t.Errorf("Unexpected record %v", record)
+	}
+	source, _ := record["source"].(map[string]interface{})
+	if file, _ := source["file"].(string); !strings.HasSuffix(file, "logger_test.go") {
+		t.Errorf("Expected source to point at the caller, got %v", source)
+	}
+}
+
+func TestLogger_SetLevelAffectsDerivedLoggers(t *testing.T) {
+	log, path := newFileLogger(t, logger.Config{Level: "info"})
+	derived := log.With("component", "relay")
+
+	derived.Debug("Hidden")
+	if err := log.SetLevel("debug"); err != nil {
+		t.Fatalf("Failed to set level: %v", err)
+	}
+	derived.DebugContext(context.Background(), "Visible")
+	if err := log.SetLevel("verbose"); err == nil {
+		t.Errorf("Expected unknown level to be rejected")
+	}
+
+	records := readRecords(t, path)
+	if len(records) != 1 || records[0]["msg"] != "Visible" || log.Level() != "debug" {
+		t.Errorf("Expected only the record after the level change, got %v (level %s)", records, log.Level())
+	}
+}
+
+func TestLogger_SamplesRepetitiveDebug(t *testing.T) {
+	log, path := newFileLogger(t, logger.Config{Level: "debug", SampleFirst: 2, SampleThereafter: 3, SamplePeriod: time.Hour})
+
+	for i := 0; i < 10; i++ {
+		log.Debug("Polling outbox", "i", i)
+		log.Info("Important", "i", i)
+	}
+	log.Debug("Another message")
+
+	counts := make(map[string]int)
+	for _, record := range readRecords(t, path) {
+		counts[record["msg"].(string)]++
+	}
+	// Первые 2, затем 5-я и 8-я записи
+	if counts["Polling outbox"] != 4 || counts["Important"] != 10 || counts["Another message"] != 1 {
+		t.Errorf("Unexpected sampling result %v", counts)
+	}
+}
+
+func TestLogger_RotatesBySize(t *testing.T) {
+	log, path := newFileLogger(t, logger.Config{Level: "info", MaxSizeMB: 1, MaxBackups: 2})
+
+	line := strings.Repeat("x", 64<<10)
+	for i := 0; i < 40; i++ {
+		log.Info("Filler", "payload", line)
+	}
+
+	for _, name := range []string{path, path + ".1", path + ".2"} {
+		info, err := os.Stat(name)
+		if err != nil {
+			t.Fatalf("Expected %s to exist: %v", filepath.Base(name), err)
+		}
+		if info.Size() > 1<<20 {
+			t.Errorf("Expected %s to stay within the limit, got %d bytes", filepath.Base(name), info.Size())
+		}
+	}
+	if _, err := os.Stat(path + ".3"); !os.IsNotExist(err) {
+		t.Errorf("Expected at most 2 backups")
+	}
+}
+
+func TestLogger_Formats(t *testing.T) {
+	for _, format := range []string{logger.FormatText, logger.FormatPretty} {
+		log, path := newFileLogger(t, logger.Config{Level: "info", Format: format, RedactKeys: []string{"token"}})
+		log.With("component", "api").Warn("Slow query", "token", "abc", "duration", "2s")
+
+		data, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatalf("Failed to read log: %v", err)
+		}
+		output := string(data)
+		if !strings.Contains(output, "Slow query") || !strings.Contains(output, "component=api") ||
+			!strings.Contains(output, "token=[REDACTED]") || strings.Contains(output, "abc") {
+			t.Errorf("Unexpected %s output %q", format, output)
+		}
+	}
+
+	if _, err := logger.NewWithConfig(logger.Config{Format: "xml"}); err == nil {
+		t.Errorf("Expected unknown format to be rejected")
+	}
+}
@@ -0,0 +1,278 @@
+package openapi_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"quotes-service/api/openapi"
+	"quotes-service/internal/domain"
+	"quotes-service/internal/handler"
+	"quotes-service/internal/infrastructure/logger"
+	"quotes-service/internal/service"
+
+	"github.com/gorilla/mux"
+	"github.com/santhosh-tekuri/jsonschema/v6"
+)
+
+const specURL = "file:///openapi.json"
+
+// memoryRepo - репозиторий в памяти с поведением, близким к PostgreSQL
+type memoryRepo struct {
+	quotes []*domain.Quote
+	nextID int
+}
+
+func (m *memoryRepo) Create(ctx context.Context, quote *domain.Quote) (*domain.Quote, error) {
+	m.nextID++
+	now := time.Now()
+	created := &domain.Quote{ID: m.nextID, Author: quote.Author, Text: quote.Text, Tags: tagsOrEmpty(quote.Tags), CreatedAt: now, UpdatedAt: now}
+	m.quotes = append(m.quotes, created)
+	return created, nil
+}
+
+func (m *memoryRepo) GetAll(ctx context.Context, filter domain.QuoteFilter) ([]*domain.Quote, error) {
+	var result []*domain.Quote
+	for _, quote := range m.quotes {
+		if filter.Author == "" || strings.Contains(quote.Author, filter.Author) {
+			result = append(result, quote)
+		}
+	}
+	return result, nil
+}
+
+func (m *memoryRepo) GetByID(ctx context.Context, id int) (*domain.Quote, error) {
+	for _, quote := range m.quotes {
+		if quote.ID == id {
+			return quote, nil
+		}
+	}
+	return nil, domain.ErrQuoteNotFound
+}
+
+func (m *memoryRepo) GetRandom(ctx context.Context) (*domain.Quote, error) {
+	if len(m.quotes) == 0 {
+		return nil, domain.ErrQuoteNotFound
+	}
+	return m.quotes[0], nil
+}
+
+func (m *memoryRepo) Update(ctx context.Context, quote *domain.Quote) (*domain.Quote, error) {
+	existing, err := m.GetByID(ctx, quote.ID)
+	if err != nil {
+		return nil, err
+	}
+	existing.Author, existing.Text, existing.Tags = quote.Author, quote.Text, tagsOrEmpty(quote.Tags)
+	existing.UpdatedAt = time.Now()
+	return existing, nil
+}
+
+func (m *memoryRepo) Delete(ctx context.Context, id int) error {
+	for i, quote := range m.quotes {
+		if quote.ID == id {
+			m.quotes = append(m.quotes[:i], m.quotes[i+1:]...)
+			return nil
+		}
+	}
+	return domain.ErrQuoteNotFound
+}
+
+func (m *memoryRepo) Count(ctx context.Context, filter domain.QuoteFilter) (int, error) {
+	return len(m.quotes), nil
+}
+
+func (m *memoryRepo) ListAuthors(ctx context.Context, filter domain.AuthorFilter) ([]*domain.Author, error) {
+	return nil, nil
+}
+
+func (m *memoryRepo) GetByAuthors(ctx context.Context, authors []string, limit int) ([]*domain.Quote, error) {
+	return nil, nil
+}
+
+func (m *memoryRepo) HealthCheck(ctx context.Context) error {
+	return nil
+}
+
+func tagsOrEmpty(tags []string) []string {
+	if tags == nil {
+		return []string{}
+	}
+	return tags
+}
+
+type spec struct {
+	doc   map[string]interface{}
+	paths map[string]map[string]interface{}
+	comp  *jsonschema.Compiler
+}
+
+func loadSpec(t *testing.T) *spec {
+	t.Helper()
+
+	var raw struct {
+		OpenAPI string                            `json:"openapi"`
+		Paths   map[string]map[string]interface{} `json:"paths"`
+	}
+	if err := json.Unmarshal(openapi.Spec, &raw); err != nil {
+		t.Fatalf("Spec is not valid JSON: %v", err)
+	}
+	if !strings.HasPrefix(raw.OpenAPI, "3.1") {
+		t.Fatalf("Expected OpenAPI 3.1, got %q", raw.OpenAPI)
+	}
+
+	doc, err := jsonschema.UnmarshalJSON(bytes.NewReader(openapi.Spec))
+	if err != nil {
+		t.Fatalf("Failed to parse spec: %v", err)
+	}
+
+	comp := jsonschema.NewCompiler()
+	comp.DefaultDraft(jsonschema.Draft2020)
+	comp.AssertFormat()
+	if err := comp.AddResource(specURL, doc); err != nil {
+		t.Fatalf("Failed to load spec: %v", err)
+	}
+
+	return &spec{doc: doc.(map[string]interface{}), paths: raw.Paths, comp: comp}
+}
+
+// responseSchema находит схему тела ответа, раскрывая $ref на components/responses
+func (s *spec) responseSchema(t *testing.T, path, method string, status int) *jsonschema.Schema {
+	t.Helper()
+
+	pointer := "/paths/" + escape(path) + "/" + method + "/responses/" + strconv.Itoa(status)
+
+	response := lookup(s.doc, pointer)
+	if response == nil {
+		t.Fatalf("Spec has no %d response for %s %s", status, strings.ToUpper(method), path)
+	}
+	if ref, ok := response.(map[string]interface{})["$ref"].(string); ok {
+		pointer = strings.TrimPrefix(ref, "#")
+	}
+
+	schema, err := s.comp.Compile(specURL + "#" + pointer + "/content/application~1json/schema")
+	if err != nil {
+		t.Fatalf("Failed to compile schema for %s %s %d: %v", method, path, status, err)
+	}
+	return schema
+}
+
+func escape(s string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(s, "~", "~0"), "/", "~1")
+}
+
+func lookup(doc interface{}, pointer string) interface{} {
+	for _, part := range strings.Split(strings.TrimPrefix(pointer, "/"), "/") {
+		part = strings.ReplaceAll(strings.ReplaceAll(part, "~1", "/"), "~0", "~")
+		obj, ok := doc.(map[string]interface{})
+		if !ok {
+			return nil
+		}
+		if doc, ok = obj[part]; !ok {
+			return nil
+		}
+	}
+	return doc
+}
+
+var routeParam = regexp.MustCompile(`\{(\w+):[^}]+\}`)
+
+func newRouter() *mux.Router {
+	log := logger.New("error")
+	quoteHandler := handler.NewQuoteHandler(service.NewQuoteService(&memoryRepo{}, log), log)
+
+	router := mux.NewRouter()
+	quoteHandler.RegisterHealthRoutes(router)
+	handler.MountVersions(router, handler.APIVersion{Name: "v1", Handlers: []handler.RouteRegistrar{quoteHandler}})
+	return router
+}
+
+func TestSpec_CoversEveryRoute(t *testing.T) {
+	s := loadSpec(t)
+	router := newRouter()
+
+	seen := make(map[string]bool)
+	router.Walk(func(route *mux.Route, _ *mux.Router, _ []*mux.Route) error {
+		tmpl, err := route.GetPathTemplate()
+		if err != nil {
+			return nil
+		}
+		methods, _ := route.GetMethods()
+		path := routeParam.ReplaceAllString(tmpl, "{$1}")
+
+		for _, method := range methods {
+			key := strings.ToLower(method) + " " + path
+			seen[key] = true
+			if _, ok := s.paths[path][strings.ToLower(method)]; !ok {
+				t.Errorf("Route %s %s is missing from the spec", method, path)
+			}
+		}
+		return nil
+	})
+
+	for path, item := range s.paths {
+		for method := range item {
+			if method == "parameters" {
+				continue
+			}
+			if !seen[method+" "+path] {
+				t.Errorf("Spec documents %s %s, but there is no such route", strings.ToUpper(method), path)
+			}
+		}
+	}
+}
+
+func TestSpec_MatchesHandlerResponses(t *testing.T) {
+	s := loadSpec(t)
+	router := newRouter()
+
+	steps := []struct {
+		method string
+		target string
+		path   string
+		body   string
+		status int
+	}{
+		{http.MethodGet, "/v1/quotes", "/v1/quotes", "", http.StatusOK},
+		{http.MethodGet, "/v1/quotes/random", "/v1/quotes/random", "", http.StatusNotFound},
+		{http.MethodPost, "/v1/quotes", "/v1/quotes", `{"author":"Seneca","quote":"Difficulties strengthen the mind.","tags":["Stoicism"]}`, http.StatusCreated},
+		{http.MethodPost, "/v1/quotes", "/v1/quotes", `{"author":"Confucius","quote":"Real knowledge is to know the extent of one's ignorance."}`, http.StatusCreated},
+		{http.MethodPost, "/v1/quotes", "/v1/quotes", `{"author":""}`, http.StatusBadRequest},
+		{http.MethodPost, "/v1/quotes", "/v1/quotes", `not json`, http.StatusBadRequest},
+		{http.MethodGet, "/v1/quotes?author=Sen&limit=10", "/v1/quotes", "", http.StatusOK},
+		{http.MethodGet, "/v1/quotes/random", "/v1/quotes/random", "", http.StatusOK},
+		{http.MethodPut, "/v1/quotes/1", "/v1/quotes/{id}", `{"author":"Seneca","quote":"Luck is preparation.","tags":["luck"]}`, http.StatusOK},
+		{http.MethodPut, "/v1/quotes/99", "/v1/quotes/{id}", `{"author":"Seneca","quote":"text"}`, http.StatusNotFound},
+		{http.MethodDelete, "/v1/quotes/2", "/v1/quotes/{id}", "", http.StatusOK},
+		{http.MethodDelete, "/v1/quotes/2", "/v1/quotes/{id}", "", http.StatusNotFound},
+		{http.MethodGet, "/health", "/health", "", http.StatusOK},
+	}
+
+	for _, step := range steps {
+		t.Run(step.method+" "+step.target, func(t *testing.T) {
+			req := httptest.NewRequest(step.method, step.target, strings.NewReader(step.body))
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, req)
+
+			if rec.Code != step.status {
+				t.Fatalf("Expected status %d, got %d: %s", step.status, rec.Code, rec.Body.String())
+			}
+
+			body, err := jsonschema.UnmarshalJSON(rec.Body)
+			if err != nil {
+				t.Fatalf("Response is not JSON: %v", err)
+			}
+
+			schema := s.responseSchema(t, step.path, strings.ToLower(step.method), step.status)
+			if err := schema.Validate(body); err != nil {
+				t.Errorf("Response does not match the spec: %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,94 @@
+package outbox_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"quotes-service/internal/domain"
+	"quotes-service/internal/infrastructure/logger"
+	"quotes-service/internal/outbox"
+)
+
+type listenerFunc func(event *domain.Event)
+
+func (f listenerFunc) OnEvent(event *domain.Event) {
+	f(event)
+}
+
+func newFollower(repo domain.OutboxRepository, gapTimeout time.Duration) (*outbox.Follower, *[]int64) {
+	var received []int64
+	listener := listenerFunc(func(event *domain.Event) {
+		received = append(received, event.ID)
+	})
+	follower := outbox.NewFollower(repo, []domain.EventListener{listener}, outbox.FollowerConfig{
+		BatchSize:  2,
+		GapTimeout: gapTimeout,
+	}, logger.New("error"))
+	return follower, &received
+}
+
+func TestFollower_DeliversEventsAfterStart(t *testing.T) {
+	repo := newMemoryOutbox(domain.NewQuoteEvent(domain.EventQuoteCreated, 1, &domain.Quote{ID: 1}))
+	follower, received := newFollower(repo, time.Minute)
+	ctx := context.Background()
+
+	if err := follower.RunOnce(ctx); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(*received) != 0 {
+		t.Fatalf("Expected history before start to be skipped, got %v", *received)
+	}
+
+	// События других инстансов приходят через общую таблицу, в том числе
+	// уже опубликованные релеем
+	for id := int64(2); id <= 4; id++ {
+		event := domain.NewQuoteEvent(domain.EventQuoteUpdated, 1, &domain.Quote{ID: 1})
+		event.ID = id
+		repo.events = append(repo.events, event)
+	}
+	repo.published[2] = true
+
+	if err := follower.RunOnce(ctx); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if got := *received; len(got) != 3 || got[0] != 2 || got[2] != 4 {
+		t.Errorf("Expected events 2..4 in order, got %v", got)
+	}
+}
+
+func TestFollower_WaitsForGap(t *testing.T) {
+	repo := newMemoryOutbox()
+	follower, received := newFollower(repo, 30*time.Millisecond)
+	ctx := context.Background()
+	follower.RunOnce(ctx)
+
+	// Событие 1 еще в незакоммиченной транзакции
+	late := domain.NewQuoteEvent(domain.EventQuoteCreated, 1, &domain.Quote{ID: 1})
+	late.ID = 1
+	next := domain.NewQuoteEvent(domain.EventQuoteCreated, 2, &domain.Quote{ID: 2})
+	next.ID = 2
+	repo.events = append(repo.events, next)
+
+	follower.RunOnce(ctx)
+	if len(*received) != 0 {
+		t.Fatalf("Expected follower to wait for the gap, got %v", *received)
+	}
+
+	repo.events = []*domain.Event{late, next}
+	follower.RunOnce(ctx)
+	if got := *received; len(got) != 2 || got[0] != 1 || got[1] != 2 {
+		t.Fatalf("Expected events in id order once the gap is filled, got %v", got)
+	}
+
+	// Откатившаяся транзакция: пропуск принимается после GapTimeout
+	skipped := domain.NewQuoteEvent(domain.EventQuoteDeleted, 2, nil)
+	skipped.ID = 4
+	repo.events = append(repo.events, skipped)
+	follower.RunOnce(ctx)
+	time.Sleep(40 * time.Millisecond)
+	follower.RunOnce(ctx)
+	if got := *received; len(got) != 3 || got[2] != 4 {
+		t.Errorf("Expected gap to be skipped after timeout, got %v", got)
+	}
+}
@@ -0,0 +1,251 @@
+package outbox_test
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"quotes-service/internal/domain"
+	"quotes-service/internal/infrastructure/logger"
+	"quotes-service/internal/outbox"
+)
+
+// In-memory outbox
+type memoryOutbox struct {
+	events    []*domain.Event
+	published map[int64]bool
+	retryAt   map[int64]time.Time
+}
+
+func newMemoryOutbox(events ...*domain.Event) *memoryOutbox {
+	for i, event := range events {
+		event.ID = int64(i + 1)
+	}
+	return &memoryOutbox{
+		events:    events,
+		published: make(map[int64]bool),
+		retryAt:   make(map[int64]time.Time),
+	}
+}
+
+func (m *memoryOutbox) Append(ctx context.Context, events ...*domain.Event) error {
+	return nil
+}
+
+func (m *memoryOutbox) ProcessPending(ctx context.Context, limit int, lease time.Duration, process func(ctx context.Context, events []*domain.Event) []domain.EventResult) error {
+	var pending []*domain.Event
+	for _, event := range m.events {
+		if !m.published[event.ID] {
+			pending = append(pending, event)
+		}
+	}
+
+	for _, result := range process(ctx, pending) {
+		for _, event := range m.events {
+			if event.ID == result.EventID {
+				event.Attempts++
+			}
+		}
+		if result.Err == nil {
+			m.published[result.EventID] = true
+		} else {
+			m.retryAt[result.EventID] = result.RetryAt
+		}
+	}
+	return nil
+}
+
+func (m *memoryOutbox) PendingLag(ctx context.Context) (time.Duration, error) {
+	var lag time.Duration
+	for _, event := range m.events {
+		if age := time.Since(event.OccurredAt); !m.published[event.ID] && age > lag {
+			lag = age
+		}
+	}
+	return lag, nil
+}
+
+func (m *memoryOutbox) ListAfter(ctx context.Context, afterID int64, limit int) ([]*domain.Event, error) {
+	var events []*domain.Event
+	for _, event := range m.events {
+		if event.ID > afterID && len(events) < limit {
+			events = append(events, event)
+		}
+	}
+	return events, nil
+}
+
+func (m *memoryOutbox) LastEventID(ctx context.Context) (int64, error) {
+	var last int64
+	for _, event := range m.events {
+		last = max(last, event.ID)
+	}
+	return last, nil
+}
+
+// Sink, отказывающий для выбранных цитат
+type recordingSink struct {
+	failFor   map[int]bool
+	delivered []int64
+}
+
+func (s *recordingSink) Name() string {
+	return "recording"
+}
+
+func (s *recordingSink) Publish(ctx context.Context, event *domain.Event) error {
+	if s.failFor[event.QuoteID] {
+		return errors.New("receiver unavailable")
+	}
+	s.delivered = append(s.delivered, event.ID)
+	return nil
+}
+
+func TestRelay_PreservesOrderPerQuote(t *testing.T) {
+	repo := newMemoryOutbox(
+		domain.NewQuoteEvent(domain.EventQuoteCreated, 1, &domain.Quote{ID: 1}),
+		domain.NewQuoteEvent(domain.EventQuoteCreated, 2, &domain.Quote{ID: 2}),
+		domain.NewQuoteEvent(domain.EventQuoteDeleted, 1, nil),
+	)
+	sink := &recordingSink{failFor: map[int]bool{1: true}}
+	relay := outbox.NewRelay(repo, []outbox.Sink{sink}, outbox.RelayConfig{MinBackoff: time.Second}, logger.New("error"))
+
+	if err := relay.RunOnce(context.Background()); err != nil {
+		t.Fatalf("Expected no error but got: %v", err)
+	}
+
+	if fmt.Sprint(sink.delivered) != "[2]" {
+		t.Fatalf("Expected only event 2 delivered, got %v", sink.delivered)
+	}
+	if repo.published[3] {
+		t.Errorf("Expected event 3 to wait for failed event 1")
+	}
+	if retry, ok := repo.retryAt[1]; !ok || time.Until(retry) <= 0 {
+		t.Errorf("Expected failed event to be scheduled for retry")
+	}
+
+	sink.failFor = nil
+	if err := relay.RunOnce(context.Background()); err != nil {
+		t.Fatalf("Expected no error but got: %v", err)
+	}
+
+	if fmt.Sprint(sink.delivered) != "[2 1 3]" {
+		t.Errorf("Expected events 1 and 3 delivered in order, got %v", sink.delivered)
+	}
+}
+
+func TestRelay_BackoffGrows(t *testing.T) {
+	event := domain.NewQuoteEvent(domain.EventQuoteCreated, 1, &domain.Quote{ID: 1})
+	repo := newMemoryOutbox(event)
+	sink := &recordingSink{failFor: map[int]bool{1: true}}
+	relay := outbox.NewRelay(repo, []outbox.Sink{sink}, outbox.RelayConfig{
+		MinBackoff: time.Second,
+		MaxBackoff: 3 * time.Second,
+	}, logger.New("error"))
+
+	var delays []time.Duration
+	for i := 0; i < 4; i++ {
+		relay.RunOnce(context.Background())
+		delays = append(delays, time.Until(repo.retryAt[1]).Round(time.Second))
+	}
+
+	if fmt.Sprint(delays) != "[1s 2s 3s 3s]" {
+		t.Errorf("Expected capped exponential backoff, got %v", delays)
+	}
+}
+
+func TestWebhookSink_Publish(t *testing.T) {
+	var received domain.Event
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Event-Type") != string(domain.EventQuoteCreated) {
+			t.Errorf("Expected X-Event-Type header, got %q", r.Header.Get("X-Event-Type"))
+		}
+		json.NewDecoder(r.Body).Decode(&received)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	sink := outbox.NewWebhookSink(server.URL, time.Second)
+	event := domain.NewQuoteEvent(domain.EventQuoteCreated, 7, &domain.Quote{ID: 7, Author: "Author"})
+	event.ID = 42
+
+	if err := sink.Publish(context.Background(), event); err != nil {
+		t.Fatalf("Expected no error but got: %v", err)
+	}
+	if received.ID != 42 || received.Quote == nil || received.Quote.Author != "Author" {
+		t.Errorf("Expected event payload to be delivered, got %+v", received)
+	}
+}
+
+func TestWebhookSink_RejectsNon2xx(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer server.Close()
+
+	sink := outbox.NewWebhookSink(server.URL, time.Second)
+	if err := sink.Publish(context.Background(), domain.NewQuoteEvent(domain.EventQuoteDeleted, 1, nil)); err == nil {
+		t.Errorf("Expected error for 502 response")
+	}
+}
+
+func TestNATSSink_Publish(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	defer listener.Close()
+
+	published := make(chan string, 1)
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		reader := bufio.NewReader(conn)
+		fmt.Fprint(conn, "INFO {\"server_id\":\"test\"}\r\n")
+		for {
+			line, err := reader.ReadString('\n')
+			if err != nil {
+				return
+			}
+			switch {
+			case strings.HasPrefix(line, "PING"):
+				fmt.Fprint(conn, "PONG\r\n")
+			case strings.HasPrefix(line, "PUB "):
+				payload, _ := reader.ReadString('\n')
+				published <- strings.TrimSpace(line) + " " + strings.TrimSpace(payload)
+			}
+		}
+	}()
+
+	sink, err := outbox.NewNATSSink("nats://"+listener.Addr().String(), "quotes", time.Second)
+	if err != nil {
+		t.Fatalf("Expected no error but got: %v", err)
+	}
+	defer sink.Close()
+
+	event := domain.NewQuoteEvent(domain.EventQuoteCreated, 3, &domain.Quote{ID: 3})
+	if err := sink.Publish(context.Background(), event); err != nil {
+		t.Fatalf("Expected no error but got: %v", err)
+	}
+
+	select {
+	case msg := <-published:
+		if !strings.HasPrefix(msg, "PUB quotes.quote.created ") || !strings.Contains(msg, `"quote_id":3`) {
+			t.Errorf("Unexpected NATS message: %s", msg)
+		}
+	case <-time.After(time.Second):
+		t.Fatalf("Expected message to be published")
+	}
+}
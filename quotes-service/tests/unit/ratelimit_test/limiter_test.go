@@ -0,0 +1,117 @@
+package ratelimit_test
+
+import (
+	"testing"
+	"time"
+
+	"quotes-service/internal/ratelimit"
+)
+
+func TestParseRules(t *testing.T) {
+	rules, err := ratelimit.ParseRules(" get /quotes/random=60/m, POST /quotes=20/10s ,")
+	if err != nil {
+		t.Fatalf("Expected valid rules, got %v", err)
+	}
+	if len(rules) != 2 {
+		t.Fatalf("Expected 2 rules, got %d", len(rules))
+	}
+	if want := (ratelimit.Rule{Method: "GET", Path: "/quotes/random", Requests: 60, Period: time.Minute}); rules[0] != want {
+		t.Errorf("Expected %v, got %v", want, rules[0])
+	}
+	if rules[1].Requests != 20 || rules[1].Period != 10*time.Second {
+		t.Errorf("Unexpected second rule: %v", rules[1])
+	}
+
+	for _, spec := range []string{"GET /quotes", "GET=1/m", "GET /quotes=0/m", "GET /quotes=5/week"} {
+		if _, err := ratelimit.ParseRules(spec); err == nil {
+			t.Errorf("Expected error for %q", spec)
+		}
+	}
+}
+
+func TestLimiter_TokenBucket(t *testing.T) {
+	limiter := ratelimit.NewLimiter(100)
+	rule := ratelimit.Rule{Method: "GET", Path: "/quotes/random", Requests: 3, Period: time.Hour}
+
+	for i := 3; i > 0; i-- {
+		decision := limiter.Allow("alice", rule)
+		if !decision.Allowed || decision.Remaining != i-1 || decision.Limit != 3 {
+			t.Fatalf("Expected request allowed with %d remaining, got %+v", i-1, decision)
+		}
+	}
+
+	decision := limiter.Allow("alice", rule)
+	if decision.Allowed {
+		t.Fatalf("Expected fourth request to be limited")
+	}
+	// Одна порция восполняется за час / 3
+	if decision.RetryAfter < 19*time.Minute || decision.RetryAfter > 20*time.Minute {
+		t.Errorf("Expected retry after ~20m, got %v", decision.RetryAfter)
+	}
+	if decision.Reset < 59*time.Minute || decision.Reset > time.Hour {
+		t.Errorf("Expected reset in ~1h, got %v", decision.Reset)
+	}
+
+	if !limiter.Allow("bob", rule).Allowed {
+		t.Errorf("Expected separate bucket per client")
+	}
+	other := ratelimit.Rule{Method: "POST", Path: "/quotes", Requests: 1, Period: time.Hour}
+	if !limiter.Allow("alice", other).Allowed {
+		t.Errorf("Expected separate bucket per rule")
+	}
+}
+
+func TestParseLimit(t *testing.T) {
+	rule, err := ratelimit.ParseLimit("20/m")
+	if err != nil || rule.Requests != 20 || rule.Period != time.Minute {
+		t.Errorf("Expected 20/1m, got %v %v", rule, err)
+	}
+	if rule, err := ratelimit.ParseLimit("0"); err != nil || rule.Requests != 0 {
+		t.Errorf("Expected 0 to disable the limit, got %v %v", rule, err)
+	}
+	for _, spec := range []string{"20", "x/m", "5/week"} {
+		if _, err := ratelimit.ParseLimit(spec); err == nil {
+			t.Errorf("Expected error for %q", spec)
+		}
+	}
+}
+
+func TestLimiter_CheckDoesNotConsume(t *testing.T) {
+	limiter := ratelimit.NewLimiter(100)
+	rule := ratelimit.Rule{Method: "AUTH", Path: "failed", Requests: 1, Period: time.Hour}
+
+	if !limiter.Check("ip:192.0.2.1", rule).Allowed || limiter.Len() != 0 {
+		t.Fatalf("Expected check of a new client to pass without creating a bucket")
+	}
+	limiter.Allow("ip:192.0.2.1", rule)
+	for i := 0; i < 2; i++ {
+		if decision := limiter.Check("ip:192.0.2.1", rule); decision.Allowed || decision.RetryAfter <= 0 {
+			t.Errorf("Expected exhausted bucket to stay exhausted, got %+v", decision)
+		}
+	}
+}
+
+func TestLimiter_BoundsMemory(t *testing.T) {
+	limiter := ratelimit.NewLimiter(2)
+	rule := ratelimit.Rule{Method: "GET", Path: "/quotes/random", Requests: 1, Period: time.Hour}
+
+	for _, client := range []string{"a", "b", "c"} {
+		limiter.Allow(client, rule)
+	}
+	if limiter.Len() != 2 {
+		t.Errorf("Expected 2 buckets, got %d", limiter.Len())
+	}
+	if limiter.Allow("c", rule).Allowed {
+		t.Errorf("Expected recent client to keep its bucket")
+	}
+
+	// Наполнившиеся корзины не отличаются от новых и вытесняются первыми
+	fast := ratelimit.Rule{Method: "GET", Path: "/quotes", Requests: 100, Period: time.Millisecond}
+	limiter = ratelimit.NewLimiter(10)
+	limiter.Allow("a", fast)
+	time.Sleep(5 * time.Millisecond)
+	limiter.Allow("b", rule)
+	if limiter.Len() != 1 {
+		t.Errorf("Expected refilled bucket to be evicted, got %d buckets", limiter.Len())
+	}
+}
@@ -0,0 +1,248 @@
+package rpc_test
+
+import (
+	"context"
+	"net"
+	"sort"
+	"testing"
+	"time"
+
+	quotesv1 "quotes-service/api/proto/quotes/v1"
+	"quotes-service/internal/domain"
+	"quotes-service/internal/infrastructure/logger"
+	"quotes-service/internal/rpc"
+	"quotes-service/internal/service"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// memoryRepo - минимальный репозиторий в памяти
+type memoryRepo struct {
+	quotes map[int]*domain.Quote
+	nextID int
+	panic  bool
+}
+
+func newMemoryRepo() *memoryRepo {
+	return &memoryRepo{quotes: make(map[int]*domain.Quote), nextID: 1}
+}
+
+func (m *memoryRepo) Create(ctx context.Context, quote *domain.Quote) (*domain.Quote, error) {
+	created := *quote
+	created.ID = m.nextID
+	created.CreatedAt = time.Now()
+	created.UpdatedAt = created.CreatedAt
+	m.quotes[created.ID] = &created
+	m.nextID++
+	return &created, nil
+}
+
+func (m *memoryRepo) filtered(filter domain.QuoteFilter) []*domain.Quote {
+	var result []*domain.Quote
+	for _, quote := range m.quotes {
+		if filter.Author == "" || quote.Author == filter.Author {
+			result = append(result, quote)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].ID > result[j].ID })
+	return result
+}
+
+func (m *memoryRepo) GetAll(ctx context.Context, filter domain.QuoteFilter) ([]*domain.Quote, error) {
+	result := m.filtered(filter)
+	if filter.Offset >= len(result) {
+		return nil, nil
+	}
+	result = result[filter.Offset:]
+	if filter.Limit < len(result) {
+		result = result[:filter.Limit]
+	}
+	return result, nil
+}
+
+func (m *memoryRepo) GetByID(ctx context.Context, id int) (*domain.Quote, error) {
+	if m.panic {
+		panic("boom")
+	}
+	if quote, ok := m.quotes[id]; ok {
+		return quote, nil
+	}
+	return nil, domain.ErrQuoteNotFound
+}
+
+func (m *memoryRepo) GetRandom(ctx context.Context) (*domain.Quote, error) {
+	for _, quote := range m.quotes {
+		return quote, nil
+	}
+	return nil, domain.ErrQuoteNotFound
+}
+
+func (m *memoryRepo) Update(ctx context.Context, quote *domain.Quote) (*domain.Quote, error) {
+	if _, ok := m.quotes[quote.ID]; !ok {
+		return nil, domain.ErrQuoteNotFound
+	}
+	m.quotes[quote.ID] = quote
+	return quote, nil
+}
+
+func (m *memoryRepo) Delete(ctx context.Context, id int) error {
+	if _, ok := m.quotes[id]; !ok {
+		return domain.ErrQuoteNotFound
+	}
+	delete(m.quotes, id)
+	return nil
+}
+
+func (m *memoryRepo) Count(ctx context.Context, filter domain.QuoteFilter) (int, error) {
+	return len(m.filtered(filter)), nil
+}
+
+func (m *memoryRepo) ListAuthors(ctx context.Context, filter domain.AuthorFilter) ([]*domain.Author, error) {
+	return nil, nil
+}
+
+func (m *memoryRepo) GetByAuthors(ctx context.Context, authors []string, limit int) ([]*domain.Quote, error) {
+	return nil, nil
+}
+
+func (m *memoryRepo) HealthCheck(ctx context.Context) error {
+	return nil
+}
+
+func newClient(t *testing.T, repo *memoryRepo) (quotesv1.QuoteServiceClient, *grpc.ClientConn) {
+	t.Helper()
+
+	log := logger.New("error")
+	server := rpc.NewServer(service.NewQuoteService(repo, log), log)
+	server.CheckHealth(context.Background())
+
+	listener := bufconn.Listen(1 << 20)
+	go server.Serve(listener)
+	t.Cleanup(func() { server.Shutdown(context.Background()) })
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatalf("Failed to dial: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	return quotesv1.NewQuoteServiceClient(conn), conn
+}
+
+func TestQuoteServer_CRUD(t *testing.T) {
+	client, _ := newClient(t, newMemoryRepo())
+	ctx := context.Background()
+
+	created, err := client.CreateQuote(ctx, &quotesv1.CreateQuoteRequest{Author: "Seneca", Quote: "We suffer more in imagination than in reality.", Tags: []string{"Stoicism"}})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if created.GetId() != 1 || len(created.GetTags()) != 1 || created.GetTags()[0] != "stoicism" {
+		t.Errorf("Unexpected quote: %v", created)
+	}
+
+	got, err := client.GetQuote(ctx, &quotesv1.GetQuoteRequest{Id: created.GetId()})
+	if err != nil || got.GetAuthor() != "Seneca" {
+		t.Errorf("Expected created quote, got %v (%v)", got, err)
+	}
+
+	if _, err := client.DeleteQuote(ctx, &quotesv1.DeleteQuoteRequest{Id: created.GetId()}); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+
+	_, err = client.GetQuote(ctx, &quotesv1.GetQuoteRequest{Id: created.GetId()})
+	if status.Code(err) != codes.NotFound {
+		t.Errorf("Expected NotFound, got %v", err)
+	}
+}
+
+func TestQuoteServer_ErrorCodes(t *testing.T) {
+	repo := newMemoryRepo()
+	client, _ := newClient(t, repo)
+	ctx := context.Background()
+
+	_, err := client.CreateQuote(ctx, &quotesv1.CreateQuoteRequest{Author: "", Quote: "text"})
+	if status.Code(err) != codes.InvalidArgument {
+		t.Errorf("Expected InvalidArgument, got %v", err)
+	}
+
+	_, err = client.GetRandomQuote(ctx, &quotesv1.GetRandomQuoteRequest{})
+	if status.Code(err) != codes.NotFound {
+		t.Errorf("Expected NotFound for empty repository, got %v", err)
+	}
+
+	_, err = client.ListQuotes(ctx, &quotesv1.ListQuotesRequest{PageToken: "bogus"})
+	if status.Code(err) != codes.InvalidArgument {
+		t.Errorf("Expected InvalidArgument for bad page token, got %v", err)
+	}
+
+	// Паника в обработчике превращается в Internal, сервер продолжает работать
+	repo.panic = true
+	_, err = client.GetQuote(ctx, &quotesv1.GetQuoteRequest{Id: 1})
+	if status.Code(err) != codes.Internal {
+		t.Errorf("Expected Internal after panic, got %v", err)
+	}
+	repo.panic = false
+
+	if _, err := client.ListQuotes(ctx, &quotesv1.ListQuotesRequest{}); err != nil {
+		t.Errorf("Expected server to survive panic, got %v", err)
+	}
+}
+
+func TestQuoteServer_ListPagination(t *testing.T) {
+	repo := newMemoryRepo()
+	client, _ := newClient(t, repo)
+	ctx := context.Background()
+
+	for i := 0; i < 5; i++ {
+		repo.Create(ctx, &domain.Quote{Author: "Seneca", Text: "text"})
+	}
+	repo.Create(ctx, &domain.Quote{Author: "Confucius", Text: "text"})
+
+	var ids []int64
+	token := ""
+	for page := 0; ; page++ {
+		resp, err := client.ListQuotes(ctx, &quotesv1.ListQuotesRequest{Author: "Seneca", PageSize: 2, PageToken: token})
+		if err != nil {
+			t.Fatalf("Expected no error, got %v", err)
+		}
+		if resp.GetTotalSize() != 5 {
+			t.Errorf("Expected total 5, got %d", resp.GetTotalSize())
+		}
+		for _, quote := range resp.GetQuotes() {
+			ids = append(ids, quote.GetId())
+		}
+		if token = resp.GetNextPageToken(); token == "" {
+			break
+		}
+		if page > 5 {
+			t.Fatalf("Pagination did not terminate")
+		}
+	}
+
+	if len(ids) != 5 || ids[0] != 5 || ids[4] != 1 {
+		t.Errorf("Expected quotes 5..1, got %v", ids)
+	}
+}
+
+func TestServer_Health(t *testing.T) {
+	_, conn := newClient(t, newMemoryRepo())
+
+	resp, err := healthpb.NewHealthClient(conn).Check(context.Background(), &healthpb.HealthCheckRequest{Service: "quotes.v1.QuoteService"})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if resp.GetStatus() != healthpb.HealthCheckResponse_SERVING {
+		t.Errorf("Expected SERVING, got %v", resp.GetStatus())
+	}
+}
@@ -0,0 +1,149 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"quotes-service/internal/domain"
+	"quotes-service/internal/infrastructure/logger"
+	"quotes-service/internal/service"
+)
+
+// Mock repository для API-ключей
+type mockAPIKeyRepo struct {
+	keys    []*domain.APIKey
+	touches int
+}
+
+func (m *mockAPIKeyRepo) Create(ctx context.Context, key *domain.APIKey) (*domain.APIKey, error) {
+	created := *key
+	created.ID = len(m.keys) + 1
+	created.CreatedAt = time.Now()
+	m.keys = append(m.keys, &created)
+	return &created, nil
+}
+
+func (m *mockAPIKeyRepo) GetByPrefix(ctx context.Context, prefix string) (*domain.APIKey, error) {
+	for _, key := range m.keys {
+		if key.Prefix == prefix {
+			copied := *key
+			return &copied, nil
+		}
+	}
+	return nil, domain.ErrAPIKeyNotFound
+}
+
+func (m *mockAPIKeyRepo) List(ctx context.Context) ([]*domain.APIKey, error) {
+	return m.keys, nil
+}
+
+func (m *mockAPIKeyRepo) Revoke(ctx context.Context, id int, at time.Time) (*domain.APIKey, error) {
+	for _, key := range m.keys {
+		if key.ID == id {
+			key.RevokedAt = &at
+			return key, nil
+		}
+	}
+	return nil, domain.ErrAPIKeyNotFound
+}
+
+func (m *mockAPIKeyRepo) TouchLastUsed(ctx context.Context, id int, at time.Time) error {
+	m.touches++
+	m.keys[id-1].LastUsedAt = &at
+	return nil
+}
+
+func (m *mockAPIKeyRepo) CountActive(ctx context.Context, scope domain.Scope) (int, error) {
+	count := 0
+	for _, key := range m.keys {
+		if key.Active(time.Now()) && key.Allows(scope) {
+			count++
+		}
+	}
+	return count, nil
+}
+
+func TestAPIKeyService_IssueAndAuthenticate(t *testing.T) {
+	repo := &mockAPIKeyRepo{}
+	svc := service.NewAPIKeyService(repo, logger.New("error"))
+	ctx := context.Background()
+
+	key, raw, err := svc.IssueKey(ctx, domain.APIKeyRequest{Name: "ci", Scopes: []domain.Scope{domain.ScopeWrite}})
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if !strings.HasPrefix(raw, key.Prefix+"_") {
+		t.Errorf("Expected key %q to start with prefix %q", raw, key.Prefix)
+	}
+	if strings.Contains(repo.keys[0].Hash, raw[len(key.Prefix)+1:]) {
+		t.Errorf("Secret must not be stored in plain text")
+	}
+
+	authenticated, err := svc.Authenticate(ctx, raw)
+	if err != nil {
+		t.Fatalf("Expected key to authenticate, got %v", err)
+	}
+	if !authenticated.Allows(domain.ScopeRead) || authenticated.Allows(domain.ScopeAdmin) {
+		t.Errorf("Expected write key to allow read but not admin, got %v", authenticated.Scopes)
+	}
+
+	// Повторное использование в пределах минуты не пишет last_used_at
+	if _, err := svc.Authenticate(ctx, raw); err != nil {
+		t.Fatalf("Expected key to authenticate, got %v", err)
+	}
+	if repo.touches != 1 {
+		t.Errorf("Expected last_used_at to be written once, got %d", repo.touches)
+	}
+
+	for _, bad := range []string{"", "qs_", raw[:len(raw)-1] + "x", "qs_000000000000_" + strings.Repeat("0", 48)} {
+		if _, err := svc.Authenticate(ctx, bad); !errors.Is(err, domain.ErrUnauthenticated) {
+			t.Errorf("Expected ErrUnauthenticated for %q, got %v", bad, err)
+		}
+	}
+}
+
+func TestAPIKeyService_RevokedAndExpiredKeys(t *testing.T) {
+	repo := &mockAPIKeyRepo{}
+	svc := service.NewAPIKeyService(repo, logger.New("error"))
+	ctx := context.Background()
+
+	key, raw, _ := svc.IssueKey(ctx, domain.APIKeyRequest{Name: "revoked", Scopes: []domain.Scope{domain.ScopeRead}})
+	if _, err := svc.RevokeKey(ctx, key.ID); err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if _, err := svc.Authenticate(ctx, raw); !errors.Is(err, domain.ErrUnauthenticated) {
+		t.Errorf("Expected revoked key to be rejected, got %v", err)
+	}
+
+	expiresAt := time.Now().Add(time.Hour)
+	_, raw, _ = svc.IssueKey(ctx, domain.APIKeyRequest{Name: "expiring", Scopes: []domain.Scope{domain.ScopeRead}, ExpiresAt: &expiresAt})
+	past := time.Now().Add(-time.Second)
+	repo.keys[1].ExpiresAt = &past
+	if _, err := svc.Authenticate(ctx, raw); !errors.Is(err, domain.ErrUnauthenticated) {
+		t.Errorf("Expected expired key to be rejected, got %v", err)
+	}
+
+	if _, _, err := svc.IssueKey(ctx, domain.APIKeyRequest{Name: "bad", Scopes: []domain.Scope{"root"}}); !errors.Is(err, domain.ErrInvalidAPIKey) {
+		t.Errorf("Expected ErrInvalidAPIKey for unknown scope, got %v", err)
+	}
+}
+
+func TestAPIKeyService_BootstrapAdminKeyOnlyOnce(t *testing.T) {
+	svc := service.NewAPIKeyService(&mockAPIKeyRepo{}, logger.New("error"))
+	ctx := context.Background()
+
+	key, _, err := svc.BootstrapAdminKey(ctx, "bootstrap-admin")
+	if err != nil {
+		t.Fatalf("Expected no error, got %v", err)
+	}
+	if !key.Allows(domain.ScopeAdmin) {
+		t.Errorf("Expected admin scope, got %v", key.Scopes)
+	}
+
+	if _, _, err := svc.BootstrapAdminKey(ctx, "second"); !errors.Is(err, domain.ErrAdminKeyExists) {
+		t.Errorf("Expected ErrAdminKeyExists, got %v", err)
+	}
+}
@@ -0,0 +1,168 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"quotes-service/internal/domain"
+	"quotes-service/internal/infrastructure/logger"
+	"quotes-service/internal/service"
+)
+
+// Mock transactor: события из отмененной транзакции отбрасываются
+type mockTransactor struct {
+	outbox *mockOutbox
+}
+
+func (m *mockTransactor) WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	pending := len(m.outbox.events)
+	if err := fn(ctx); err != nil {
+		m.outbox.events = m.outbox.events[:pending]
+		return err
+	}
+	return nil
+}
+
+type mockOutbox struct {
+	events []*domain.Event
+	err    error
+}
+
+func (m *mockOutbox) Append(ctx context.Context, events ...*domain.Event) error {
+	if m.err != nil {
+		return m.err
+	}
+	m.events = append(m.events, events...)
+	return nil
+}
+
+func (m *mockOutbox) ProcessPending(ctx context.Context, limit int, lease time.Duration, process func(ctx context.Context, events []*domain.Event) []domain.EventResult) error {
+	return nil
+}
+
+func (m *mockOutbox) PendingLag(ctx context.Context) (time.Duration, error) {
+	return 0, nil
+}
+
+func (m *mockOutbox) ListAfter(ctx context.Context, afterID int64, limit int) ([]*domain.Event, error) {
+	return nil, nil
+}
+
+func (m *mockOutbox) LastEventID(ctx context.Context) (int64, error) {
+	return 0, nil
+}
+
+func newServiceWithOutbox() (*service.QuoteService, *mockQuoteRepository, *mockOutbox) {
+	mockRepo := newMockQuoteRepository()
+	outbox := &mockOutbox{}
+	svc := service.NewQuoteService(mockRepo, logger.New("debug"),
+		service.WithOutbox(&mockTransactor{outbox: outbox}, outbox),
+	)
+	return svc, mockRepo, outbox
+}
+
+func TestQuoteService_EmitsEvents(t *testing.T) {
+	svc, _, outbox := newServiceWithOutbox()
+	ctx := context.Background()
+
+	created, err := svc.CreateQuote(ctx, domain.CreateQuoteRequest{Author: "Author", Quote: "Quote"})
+	if err != nil {
+		t.Fatalf("Expected no error but got: %v", err)
+	}
+	if _, err := svc.UpdateQuote(ctx, created.ID, domain.UpdateQuoteRequest{Author: "Author", Quote: "Updated"}); err != nil {
+		t.Fatalf("Expected no error but got: %v", err)
+	}
+	if err := svc.DeleteQuote(ctx, created.ID); err != nil {
+		t.Fatalf("Expected no error but got: %v", err)
+	}
+
+	want := []domain.EventType{domain.EventQuoteCreated, domain.EventQuoteUpdated, domain.EventQuoteDeleted}
+	if len(outbox.events) != len(want) {
+		t.Fatalf("Expected %d events, got %d", len(want), len(outbox.events))
+	}
+	for i, event := range outbox.events {
+		if event.Type != want[i] {
+			t.Errorf("Expected event %d to be %s, got %s", i, want[i], event.Type)
+		}
+		if event.QuoteID != created.ID {
+			t.Errorf("Expected event for quote %d, got %d", created.ID, event.QuoteID)
+		}
+	}
+	if outbox.events[1].Quote == nil || outbox.events[1].Quote.Text != "Updated" {
+		t.Errorf("Expected updated quote in event payload")
+	}
+}
+
+func TestQuoteService_NoEventOnFailedWrite(t *testing.T) {
+	svc, mockRepo, outbox := newServiceWithOutbox()
+	mockRepo.errOnOp["create"] = errors.New("database error")
+
+	if _, err := svc.CreateQuote(context.Background(), domain.CreateQuoteRequest{Author: "Author", Quote: "Quote"}); err == nil {
+		t.Fatalf("Expected error but got none")
+	}
+	if len(outbox.events) != 0 {
+		t.Errorf("Expected no events, got %d", len(outbox.events))
+	}
+}
+
+func TestQuoteService_OutboxFailureFailsWrite(t *testing.T) {
+	svc, _, outbox := newServiceWithOutbox()
+	outbox.err = errors.New("outbox unavailable")
+
+	if _, err := svc.CreateQuote(context.Background(), domain.CreateQuoteRequest{Author: "Author", Quote: "Quote"}); err == nil {
+		t.Errorf("Expected error when event cannot be stored")
+	}
+}
+
+// invalidatingRepo фиксирует, был ли сброс кэша внутри транзакции
+type invalidatingRepo struct {
+	*mockQuoteRepository
+	inTransaction bool
+	invalidated   [][]int
+	early         bool
+}
+
+func (r *invalidatingRepo) Invalidate(_ context.Context, ids ...int) {
+	r.early = r.early || r.inTransaction
+	r.invalidated = append(r.invalidated, ids)
+}
+
+type flagTransactor struct {
+	repo *invalidatingRepo
+}
+
+func (m *flagTransactor) WithinTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	m.repo.inTransaction = true
+	defer func() { m.repo.inTransaction = false }()
+	return fn(ctx)
+}
+
+func TestQuoteService_InvalidatesCacheAfterCommit(t *testing.T) {
+	repo := &invalidatingRepo{mockQuoteRepository: newMockQuoteRepository()}
+	outbox := &mockOutbox{}
+	svc := service.NewQuoteService(repo, logger.New("error"), service.WithOutbox(&flagTransactor{repo: repo}, outbox))
+	ctx := context.Background()
+
+	quote, err := svc.CreateQuote(ctx, domain.CreateQuoteRequest{Author: "Author", Quote: "Quote"})
+	if err != nil {
+		t.Fatalf("Failed to create quote: %v", err)
+	}
+	if _, err := svc.UpdateQuote(ctx, quote.ID, domain.UpdateQuoteRequest{Author: "Author", Quote: "Updated"}); err != nil {
+		t.Fatalf("Failed to update quote: %v", err)
+	}
+	if err := svc.DeleteQuote(ctx, quote.ID); err != nil {
+		t.Fatalf("Failed to delete quote: %v", err)
+	}
+
+	if len(repo.invalidated) != 3 || repo.early {
+		t.Errorf("Expected 3 invalidations after commit, got %v (inside transaction: %v)", repo.invalidated, repo.early)
+	}
+
+	outbox.err = errors.New("outbox unavailable")
+	svc.CreateQuote(ctx, domain.CreateQuoteRequest{Author: "Author", Quote: "Quote"})
+	if len(repo.invalidated) != 3 {
+		t.Errorf("Failed write must not invalidate cache, got %v", repo.invalidated)
+	}
+}
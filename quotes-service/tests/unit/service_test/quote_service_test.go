@@ -36,6 +36,7 @@ func (m *mockQuoteRepository) Create(ctx context.Context, quote *domain.Quote) (
 		ID:        m.nextID,
 		Author:    quote.Author,
 		Text:      quote.Text,
+		CreatedBy: quote.CreatedBy,
 		CreatedAt: now,
 		UpdatedAt: now,
 	}
@@ -95,6 +96,22 @@ func (m *mockQuoteRepository) GetRandom(ctx context.Context) (*domain.Quote, err
 	return m.quotes[0], nil // Just return first for simplicity
 }
 
+func (m *mockQuoteRepository) Update(ctx context.Context, quote *domain.Quote) (*domain.Quote, error) {
+	if err := m.errOnOp["update"]; err != nil {
+		return nil, err
+	}
+
+	for _, existing := range m.quotes {
+		if existing.ID == quote.ID {
+			existing.Author = quote.Author
+			existing.Text = quote.Text
+			existing.UpdatedAt = time.Now()
+			return existing, nil
+		}
+	}
+	return nil, domain.ErrQuoteNotFound
+}
+
 func (m *mockQuoteRepository) Delete(ctx context.Context, id int) error {
 	if err := m.errOnOp["delete"]; err != nil {
 		return err
@@ -124,6 +141,33 @@ func (m *mockQuoteRepository) Count(ctx context.Context, filter domain.QuoteFilt
 	return count, nil
 }
 
+func (m *mockQuoteRepository) ListAuthors(ctx context.Context, filter domain.AuthorFilter) ([]*domain.Author, error) {
+	counts := make(map[string]int)
+	var authors []*domain.Author
+	for _, quote := range m.quotes {
+		if counts[quote.Author] == 0 {
+			authors = append(authors, &domain.Author{Name: quote.Author})
+		}
+		counts[quote.Author]++
+	}
+	for _, author := range authors {
+		author.QuoteCount = counts[author.Name]
+	}
+	return authors, nil
+}
+
+func (m *mockQuoteRepository) GetByAuthors(ctx context.Context, authors []string, limit int) ([]*domain.Quote, error) {
+	result := make([]*domain.Quote, 0)
+	for _, quote := range m.quotes {
+		for _, author := range authors {
+			if quote.Author == author {
+				result = append(result, quote)
+			}
+		}
+	}
+	return result, nil
+}
+
 func (m *mockQuoteRepository) HealthCheck(ctx context.Context) error {
 	return m.errOnOp["healthcheck"]
 }
@@ -345,6 +389,76 @@ func TestQuoteService_GetRandomQuote(t *testing.T) {
 	}
 }
 
+func TestQuoteService_UpdateQuote(t *testing.T) {
+	mockRepo := newMockQuoteRepository()
+	logger := logger.New("debug")
+	service := service.NewQuoteService(mockRepo, logger)
+
+	tests := []struct {
+		name    string
+		id      int
+		req     domain.UpdateQuoteRequest
+		setup   func()
+		wantErr error
+	}{
+		{
+			name: "successful update",
+			id:   1,
+			req:  domain.UpdateQuoteRequest{Author: "New Author", Quote: "New quote"},
+			setup: func() {
+				mockRepo.quotes = []*domain.Quote{
+					{ID: 1, Author: "Test Author", Text: "Test Quote"},
+				}
+			},
+		},
+		{
+			name:    "invalid ID",
+			id:      0,
+			req:     domain.UpdateQuoteRequest{Author: "New Author", Quote: "New quote"},
+			wantErr: domain.ErrInvalidQuote,
+		},
+		{
+			name:    "invalid quote - empty text",
+			id:      1,
+			req:     domain.UpdateQuoteRequest{Author: "New Author", Quote: " "},
+			wantErr: domain.ErrInvalidQuote,
+		},
+		{
+			name:    "quote not found",
+			id:      999,
+			req:     domain.UpdateQuoteRequest{Author: "New Author", Quote: "New quote"},
+			wantErr: domain.ErrQuoteNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.setup != nil {
+				tt.setup()
+			}
+
+			quote, err := service.UpdateQuote(context.Background(), tt.id, tt.req)
+
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Errorf("Expected error %v, got %v", tt.wantErr, err)
+				}
+			} else {
+				if err != nil {
+					t.Errorf("Expected no error but got: %v", err)
+				}
+				if quote == nil || quote.Author != tt.req.Author {
+					t.Errorf("Expected updated quote with author '%s'", tt.req.Author)
+				}
+			}
+
+			// Reset mock for next test
+			mockRepo.errOnOp = make(map[string]error)
+			mockRepo.quotes = []*domain.Quote{}
+		})
+	}
+}
+
 func TestQuoteService_DeleteQuote(t *testing.T) {
 	mockRepo := newMockQuoteRepository()
 	logger := logger.New("debug")
@@ -420,6 +534,42 @@ func TestQuoteService_DeleteQuote(t *testing.T) {
 	}
 }
 
+func TestQuoteService_Ownership(t *testing.T) {
+	mockRepo := newMockQuoteRepository()
+	service := service.NewQuoteService(mockRepo, logger.New("error"))
+
+	as := func(subject string, roles ...domain.Role) context.Context {
+		return domain.ContextWithPrincipal(context.Background(), &domain.Principal{Subject: subject, Roles: roles})
+	}
+	req := domain.UpdateQuoteRequest{Author: "Author", Quote: "Quote"}
+
+	quote, err := service.CreateQuote(as("alice", domain.RoleEditor), domain.CreateQuoteRequest{Author: "Author", Quote: "Quote"})
+	if err != nil || quote.CreatedBy != "alice" {
+		t.Fatalf("Expected quote owned by alice, got %+v, %v", quote, err)
+	}
+
+	if _, err := service.UpdateQuote(as("bob", domain.RoleEditor), quote.ID, req); !errors.Is(err, domain.ErrQuoteForbidden) {
+		t.Errorf("Expected ErrQuoteForbidden for another editor, got %v", err)
+	}
+	if err := service.DeleteQuote(as("bob", domain.RoleEditor), quote.ID); !errors.Is(err, domain.ErrQuoteForbidden) {
+		t.Errorf("Expected ErrQuoteForbidden on delete, got %v", err)
+	}
+	if _, err := service.UpdateQuote(as("alice", domain.RoleEditor), quote.ID, req); err != nil {
+		t.Errorf("Expected owner to update, got %v", err)
+	}
+	// Без автора в контексте (аутентификация выключена) владелец не проверяется
+	if _, err := service.UpdateQuote(context.Background(), quote.ID, req); err != nil {
+		t.Errorf("Expected anonymous update without auth, got %v", err)
+	}
+	if err := service.DeleteQuote(as("root", domain.RoleAdmin), quote.ID); err != nil {
+		t.Errorf("Expected admin to delete any quote, got %v", err)
+	}
+
+	if _, err := service.GetAllQuotes(context.Background(), domain.QuoteFilter{CreatedBy: domain.CreatedByMe}); !errors.Is(err, domain.ErrUnauthenticated) {
+		t.Errorf("Expected created_by=me to require a principal, got %v", err)
+	}
+}
+
 func TestQuoteService_HealthCheck(t *testing.T) {
 	mockRepo := newMockQuoteRepository()
 	logger := logger.New("debug")
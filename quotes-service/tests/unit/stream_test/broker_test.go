@@ -0,0 +1,182 @@
+package stream_test
+
+import (
+	"bufio"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"quotes-service/internal/domain"
+	"quotes-service/internal/handler"
+	"quotes-service/internal/infrastructure/logger"
+	"quotes-service/internal/stream"
+
+	"github.com/gorilla/mux"
+)
+
+func quoteEvent(eventType domain.EventType, id int, author string, tags ...string) *domain.Event {
+	return domain.NewQuoteEvent(eventType, id, &domain.Quote{ID: id, Author: author, Tags: tags})
+}
+
+func TestFilter_Matches(t *testing.T) {
+	event := quoteEvent(domain.EventQuoteCreated, 1, "Confucius", "wisdom", "life")
+
+	tests := []struct {
+		name   string
+		filter stream.Filter
+		want   bool
+	}{
+		{name: "empty filter", filter: stream.Filter{}, want: true},
+		{name: "author substring", filter: stream.Filter{Author: "confu"}, want: true},
+		{name: "other author", filter: stream.Filter{Author: "Seneca"}, want: false},
+		{name: "tag", filter: stream.Filter{Tag: "Life"}, want: true},
+		{name: "missing tag", filter: stream.Filter{Tag: "stoicism"}, want: false},
+		{name: "type", filter: stream.Filter{Types: []domain.EventType{domain.EventQuoteDeleted}}, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.filter.Matches(event); got != tt.want {
+				t.Errorf("Expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestBroker_ReplayAfterLastID(t *testing.T) {
+	broker := stream.NewBroker(3, 10)
+	for i := 1; i <= 5; i++ {
+		broker.Publish(quoteEvent(domain.EventQuoteCreated, i, "Author"))
+	}
+
+	sub, missed, complete := broker.Subscribe(3, stream.Filter{})
+	defer broker.Unsubscribe(sub)
+	if !complete || len(missed) != 2 || missed[0].ID != 4 || missed[1].ID != 5 {
+		t.Errorf("Expected complete replay of 4 and 5, got %v (complete %v)", missed, complete)
+	}
+
+	sub2, missed, complete := broker.Subscribe(1, stream.Filter{})
+	defer broker.Unsubscribe(sub2)
+	if complete || len(missed) != 3 {
+		t.Errorf("Expected partial replay of 3 events, got %d (complete %v)", len(missed), complete)
+	}
+}
+
+func TestBroker_DropsSlowSubscriber(t *testing.T) {
+	broker := stream.NewBroker(10, 1)
+	sub, _, _ := broker.Subscribe(0, stream.Filter{})
+
+	broker.Publish(quoteEvent(domain.EventQuoteCreated, 1, "Author"))
+	broker.Publish(quoteEvent(domain.EventQuoteCreated, 2, "Author"))
+
+	<-sub.C
+	if _, ok := <-sub.C; ok {
+		t.Errorf("Expected channel to be closed after overflow")
+	}
+	if broker.Subscribers() != 0 {
+		t.Errorf("Expected slow subscriber to be removed")
+	}
+}
+
+func TestStreamHandler_OutlivesWriteTimeout(t *testing.T) {
+	broker := stream.NewBroker(10, 10)
+	router := mux.NewRouter()
+	handler.NewStreamHandler(broker, 50*time.Millisecond, logger.New("error")).RegisterRoutes(router)
+
+	server := httptest.NewUnstartedServer(router)
+	server.Config.WriteTimeout = 200 * time.Millisecond
+	server.Start()
+	defer server.Close()
+
+	broker.Publish(quoteEvent(domain.EventQuoteCreated, 1, "Seneca", "stoicism"))
+
+	req, _ := http.NewRequest(http.MethodGet, server.URL+"/quotes/stream?tag=stoicism", nil)
+	req.Header.Set("Last-Event-ID", "0")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("Failed to connect: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if ct := resp.Header.Get("Content-Type"); ct != "text/event-stream" {
+		t.Fatalf("Expected text/event-stream, got %q", ct)
+	}
+
+	// Событие после истечения WriteTimeout все еще доходит до клиента
+	go func() {
+		time.Sleep(400 * time.Millisecond)
+		broker.Publish(quoteEvent(domain.EventQuoteCreated, 2, "Confucius", "wisdom"))
+		broker.Publish(quoteEvent(domain.EventQuoteCreated, 3, "Seneca", "stoicism"))
+	}()
+
+	lines := make(chan string)
+	go func() {
+		scanner := bufio.NewScanner(resp.Body)
+		for scanner.Scan() {
+			lines <- scanner.Text()
+		}
+		close(lines)
+	}()
+
+	var heartbeats int
+	timeout := time.After(2 * time.Second)
+	for {
+		select {
+		case line, ok := <-lines:
+			if !ok {
+				t.Fatalf("Stream closed early")
+			}
+			if line == ": heartbeat" {
+				heartbeats++
+			}
+			if strings.HasPrefix(line, "id: 2") {
+				t.Fatalf("Expected filtered event to be skipped")
+			}
+			if strings.HasPrefix(line, "id: 3") {
+				if heartbeats == 0 {
+					t.Errorf("Expected heartbeats before event")
+				}
+				return
+			}
+		case <-timeout:
+			t.Fatalf("Expected event after write timeout")
+		}
+	}
+}
+
+func TestStreamHandler_ResumesFromLastEventID(t *testing.T) {
+	broker := stream.NewBroker(10, 10)
+	broker.Publish(quoteEvent(domain.EventQuoteCreated, 1, "Author"))
+	broker.Publish(quoteEvent(domain.EventQuoteDeleted, 1, "Author"))
+
+	router := mux.NewRouter()
+	handler.NewStreamHandler(broker, time.Second, logger.New("error")).RegisterRoutes(router)
+	server := httptest.NewServer(router)
+	defer server.Close()
+
+	req, _ := http.NewRequest(http.MethodGet, server.URL+"/quotes/stream", nil)
+	req.Header.Set("Last-Event-ID", "1")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("Failed to connect: %v", err)
+	}
+	defer resp.Body.Close()
+
+	scanner := bufio.NewScanner(resp.Body)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if strings.HasPrefix(line, "id: ") {
+			if line != "id: 2" {
+				t.Errorf("Expected replay to start at id 2, got %q", line)
+			}
+			scanner.Scan()
+			if scanner.Text() != "event: quote.deleted" {
+				t.Errorf("Expected quote.deleted event, got %q", scanner.Text())
+			}
+			return
+		}
+	}
+	t.Fatalf("Expected replayed event")
+}
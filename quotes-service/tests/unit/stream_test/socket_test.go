@@ -0,0 +1,167 @@
+package stream_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"quotes-service/internal/domain"
+	"quotes-service/internal/handler"
+	"quotes-service/internal/infrastructure/logger"
+	"quotes-service/internal/service"
+	"quotes-service/internal/stream"
+
+	"github.com/gorilla/mux"
+	"github.com/gorilla/websocket"
+)
+
+// randomRepo отдает одну и ту же цитату на GetRandom
+type randomRepo struct {
+	domain.QuoteRepository
+}
+
+func (r *randomRepo) GetRandom(ctx context.Context) (*domain.Quote, error) {
+	return &domain.Quote{ID: 7, Author: "Seneca", Text: "Luck is what happens when preparation meets opportunity."}, nil
+}
+
+type socketMessage struct {
+	ID    string        `json:"id"`
+	Type  string        `json:"type"`
+	Topic string        `json:"topic"`
+	Event *domain.Event `json:"event"`
+	Quote *domain.Quote `json:"quote"`
+	Error string        `json:"error"`
+}
+
+func newSocketServer(t *testing.T, broker *stream.Broker, config handler.SocketConfig) string {
+	t.Helper()
+
+	log := logger.New("error")
+	svc := service.NewQuoteService(&randomRepo{}, log)
+
+	router := mux.NewRouter()
+	handler.NewSocketHandler(svc, broker, config, log).RegisterRoutes(router)
+	server := httptest.NewServer(router)
+	t.Cleanup(server.Close)
+
+	return "ws" + strings.TrimPrefix(server.URL, "http") + "/ws"
+}
+
+func readMessage(t *testing.T, conn *websocket.Conn) socketMessage {
+	t.Helper()
+
+	var msg socketMessage
+	conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	if err := conn.ReadJSON(&msg); err != nil {
+		t.Fatalf("Failed to read message: %v", err)
+	}
+	return msg
+}
+
+func TestSocketHandler_TopicSubscriptions(t *testing.T) {
+	broker := stream.NewBroker(10, 10)
+	url := newSocketServer(t, broker, handler.SocketConfig{})
+
+	conn, _, err := websocket.DefaultDialer.Dial(url, nil)
+	if err != nil {
+		t.Fatalf("Failed to connect: %v", err)
+	}
+	defer conn.Close()
+
+	conn.WriteJSON(map[string]string{"id": "1", "type": "subscribe", "topic": "tag:Stoicism"})
+	if msg := readMessage(t, conn); msg.Type != "subscribed" || msg.Topic != "tag:stoicism" || msg.ID != "1" {
+		t.Fatalf("Expected subscription ack, got %+v", msg)
+	}
+
+	broker.Publish(quoteEvent(domain.EventQuoteCreated, 1, "Confucius", "wisdom"))
+	broker.Publish(quoteEvent(domain.EventQuoteCreated, 2, "Seneca", "stoicism"))
+
+	msg := readMessage(t, conn)
+	if msg.Type != "event" || msg.Topic != "tag:stoicism" || msg.Event == nil || msg.Event.QuoteID != 2 {
+		t.Errorf("Expected only the stoicism event, got %+v", msg)
+	}
+
+	conn.WriteJSON(map[string]string{"type": "unsubscribe", "topic": "tag:stoicism"})
+	if msg := readMessage(t, conn); msg.Type != "unsubscribed" {
+		t.Fatalf("Expected unsubscribe ack, got %+v", msg)
+	}
+
+	conn.WriteJSON(map[string]string{"type": "subscribe", "topic": "unknown"})
+	if msg := readMessage(t, conn); msg.Type != "error" {
+		t.Errorf("Expected error for unknown topic, got %+v", msg)
+	}
+}
+
+func TestSocketHandler_RandomInterval(t *testing.T) {
+	broker := stream.NewBroker(10, 10)
+	url := newSocketServer(t, broker, handler.SocketConfig{MinRandomInterval: 20 * time.Millisecond})
+
+	conn, _, err := websocket.DefaultDialer.Dial(url, nil)
+	if err != nil {
+		t.Fatalf("Failed to connect: %v", err)
+	}
+	defer conn.Close()
+
+	conn.WriteJSON(map[string]string{"type": "random", "interval": "1ms"})
+	if msg := readMessage(t, conn); msg.Type != "error" {
+		t.Fatalf("Expected error for too short interval, got %+v", msg)
+	}
+
+	conn.WriteJSON(map[string]string{"type": "random", "interval": "20ms"})
+	for i := 0; i < 3; i++ {
+		if msg := readMessage(t, conn); msg.Type != "random_quote" || msg.Quote == nil || msg.Quote.ID != 7 {
+			t.Fatalf("Expected random quote, got %+v", msg)
+		}
+	}
+}
+
+func TestSocketHandler_ConnectionLimit(t *testing.T) {
+	broker := stream.NewBroker(10, 10)
+	url := newSocketServer(t, broker, handler.SocketConfig{MaxConnections: 1})
+
+	conn, _, err := websocket.DefaultDialer.Dial(url, nil)
+	if err != nil {
+		t.Fatalf("Failed to connect: %v", err)
+	}
+	defer conn.Close()
+
+	_, resp, err := websocket.DefaultDialer.Dial(url, nil)
+	if err == nil {
+		t.Fatalf("Expected second connection to be rejected")
+	}
+	if resp == nil || resp.StatusCode != http.StatusServiceUnavailable {
+		t.Errorf("Expected 503, got %v", resp)
+	}
+}
+
+func TestSocketHandler_DropsSlowClient(t *testing.T) {
+	broker := stream.NewBroker(10, 1)
+	url := newSocketServer(t, broker, handler.SocketConfig{SendBuffer: 1})
+
+	conn, _, err := websocket.DefaultDialer.Dial(url, nil)
+	if err != nil {
+		t.Fatalf("Failed to connect: %v", err)
+	}
+	defer conn.Close()
+
+	conn.WriteJSON(map[string]string{"type": "subscribe", "topic": "quotes"})
+	readMessage(t, conn)
+
+	// Клиент не читает, пока сервер не переполнит буферы
+	for i := 1; i <= 10000 && broker.Subscribers() > 0; i++ {
+		broker.Publish(quoteEvent(domain.EventQuoteCreated, i, strings.Repeat("a", 1024)))
+	}
+
+	conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	for {
+		if _, _, err := conn.ReadMessage(); err != nil {
+			if !websocket.IsCloseError(err, websocket.CloseTryAgainLater) {
+				t.Errorf("Expected try-again-later close, got %v", err)
+			}
+			return
+		}
+	}
+}
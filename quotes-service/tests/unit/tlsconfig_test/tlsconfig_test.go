@@ -0,0 +1,230 @@
+package tlsconfig_test
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io"
+	"math/big"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"quotes-service/internal/auth"
+	"quotes-service/internal/domain"
+	"quotes-service/internal/handler"
+	"quotes-service/internal/infrastructure/logger"
+	"quotes-service/internal/tlsconfig"
+)
+
+// testCA выпускает сертификаты сервера и клиентов для тестов
+type testCA struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+	pem  []byte
+}
+
+func newCA(t *testing.T) *testCA {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("Failed to generate CA key: %v", err)
+	}
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "Test CA"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("Failed to create CA: %v", err)
+	}
+	cert, _ := x509.ParseCertificate(der)
+	return &testCA{cert: cert, key: key, pem: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})}
+}
+
+func (ca *testCA) issue(t *testing.T, commonName string, usage x509.ExtKeyUsage) ([]byte, []byte) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("Failed to generate key: %v", err)
+	}
+	serial, _ := rand.Int(rand.Reader, big.NewInt(1<<62))
+	template := &x509.Certificate{
+		SerialNumber: serial,
+		Subject:      pkix.Name{CommonName: commonName},
+		DNSNames:     []string{"localhost"},
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{usage},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, ca.cert, &key.PublicKey, ca.key)
+	if err != nil {
+		t.Fatalf("Failed to issue certificate: %v", err)
+	}
+	keyDER, _ := x509.MarshalECPrivateKey(key)
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+}
+
+func writeFile(t *testing.T, path string, data []byte) {
+	t.Helper()
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		t.Fatalf("Failed to write %s: %v", path, err)
+	}
+}
+
+// serve запускает http.Server с TLS, как main: ListenAndServeTLS без файлов
+func serve(t *testing.T, certificates *tlsconfig.Certificates, h http.Handler) string {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	server := &http.Server{Handler: h, TLSConfig: certificates.TLSConfig()}
+	go server.ServeTLS(listener, "", "")
+	t.Cleanup(func() { server.Close() })
+	return "https://" + listener.Addr().String()
+}
+
+func client(ca *testCA, certificates ...tls.Certificate) *http.Client {
+	roots := x509.NewCertPool()
+	roots.AddCert(ca.cert)
+	return &http.Client{Transport: &http.Transport{
+		TLSClientConfig:   &tls.Config{RootCAs: roots, Certificates: certificates},
+		ForceAttemptHTTP2: true,
+	}}
+}
+
+func TestCertificates_ReloadsRotatedFiles(t *testing.T) {
+	ca := newCA(t)
+	dir := t.TempDir()
+	config := tlsconfig.Config{
+		CertFile:   filepath.Join(dir, "tls.crt"),
+		KeyFile:    filepath.Join(dir, "tls.key"),
+		ClientAuth: tlsconfig.ClientAuthNone,
+		MinVersion: "1.2",
+	}
+	cert, key := ca.issue(t, "first", x509.ExtKeyUsageServerAuth)
+	writeFile(t, config.CertFile, cert)
+	writeFile(t, config.KeyFile, key)
+
+	certificates, err := tlsconfig.Load(config, logger.New("error"))
+	if err != nil {
+		t.Fatalf("Failed to load certificates: %v", err)
+	}
+	url := serve(t, certificates, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	served := func() (string, int) {
+		t.Helper()
+		// Новый клиент - новое соединение и новое рукопожатие
+		resp, err := client(ca).Get(url)
+		if err != nil {
+			t.Fatalf("Request failed: %v", err)
+		}
+		defer resp.Body.Close()
+		return resp.TLS.PeerCertificates[0].Subject.CommonName, resp.ProtoMajor
+	}
+	if name, proto := served(); name != "first" || proto != 2 {
+		t.Fatalf("Expected first certificate over HTTP/2, got %s over HTTP/%d", name, proto)
+	}
+
+	// Ключ еще не заменен: пара не сходится, остается прежний сертификат
+	cert, key = ca.issue(t, "second", x509.ExtKeyUsageServerAuth)
+	writeFile(t, config.CertFile, cert)
+	if _, err := certificates.Refresh(); err == nil {
+		t.Fatal("Expected error for mismatched key pair")
+	}
+	if name, _ := served(); name != "first" {
+		t.Fatalf("Expected previous certificate after failed reload, got %s", name)
+	}
+
+	writeFile(t, config.KeyFile, key)
+	if changed, err := certificates.Refresh(); err != nil || !changed {
+		t.Fatalf("Expected reload, got changed=%v err=%v", changed, err)
+	}
+	if name, _ := served(); name != "second" {
+		t.Errorf("Expected rotated certificate, got %s", name)
+	}
+	if changed, _ := certificates.Refresh(); changed {
+		t.Error("Unchanged files must not be reloaded")
+	}
+}
+
+func TestCertificates_ClientIdentityReachesAuth(t *testing.T) {
+	ca := newCA(t)
+	dir := t.TempDir()
+	config := tlsconfig.Config{
+		CertFile:     filepath.Join(dir, "tls.crt"),
+		KeyFile:      filepath.Join(dir, "tls.key"),
+		ClientCAFile: filepath.Join(dir, "ca.crt"),
+		ClientAuth:   tlsconfig.ClientAuthOptional,
+		MinVersion:   "1.2",
+	}
+	cert, key := ca.issue(t, "localhost", x509.ExtKeyUsageServerAuth)
+	writeFile(t, config.CertFile, cert)
+	writeFile(t, config.KeyFile, key)
+	writeFile(t, config.ClientCAFile, ca.pem)
+
+	log := logger.New("error")
+	certificates, err := tlsconfig.Load(config, log)
+	if err != nil {
+		t.Fatalf("Failed to load certificates: %v", err)
+	}
+
+	authenticator := handler.NewAuthenticator(auth.NewAuthenticator(nil, nil, auth.WithCertificates(auth.CertificateConfig{
+		RoleMapping: map[string]domain.Role{"billing": domain.RoleEditor},
+		DefaultRole: domain.RoleViewer,
+	})), handler.AuthConfig{Enabled: true}, log)
+	url := serve(t, certificates, authenticator.Require(domain.PermissionCreate, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		principal, _ := domain.PrincipalFromContext(r.Context())
+		io.WriteString(w, principal.Subject)
+	})))
+
+	post := func(c *http.Client) (int, string) {
+		t.Helper()
+		resp, err := c.Post(url, "text/plain", nil)
+		if err != nil {
+			t.Fatalf("Request failed: %v", err)
+		}
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(resp.Body)
+		return resp.StatusCode, string(body)
+	}
+	pair := func(ca *testCA, commonName string) tls.Certificate {
+		cert, key := ca.issue(t, commonName, x509.ExtKeyUsageClientAuth)
+		pair, err := tls.X509KeyPair(cert, key)
+		if err != nil {
+			t.Fatalf("Failed to build client key pair: %v", err)
+		}
+		return pair
+	}
+
+	if status, body := post(client(ca, pair(ca, "billing"))); status != http.StatusOK || body != "cert:billing" {
+		t.Errorf("Expected mapped client to pass, got %d %q", status, body)
+	}
+	if status, _ := post(client(ca, pair(ca, "reports"))); status != http.StatusForbidden {
+		t.Errorf("Expected viewer client to be forbidden, got %d", status)
+	}
+	if status, _ := post(client(ca)); status != http.StatusUnauthorized {
+		t.Errorf("Expected anonymous client to be rejected, got %d", status)
+	}
+
+	// Сертификат чужого CA не проходит рукопожатие
+	if _, err := client(ca, pair(newCA(t), "billing")).Post(url, "text/plain", nil); err == nil {
+		t.Error("Expected handshake to fail for certificate from unknown CA")
+	}
+}
@@ -0,0 +1,282 @@
+package webhook_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"quotes-service/internal/domain"
+	"quotes-service/internal/infrastructure/logger"
+	"quotes-service/internal/webhook"
+)
+
+// In-memory webhook repository
+type memoryRepository struct {
+	mu         sync.Mutex
+	subs       []*domain.WebhookSubscription
+	deliveries []*domain.WebhookDelivery
+	lease      time.Duration
+}
+
+func (m *memoryRepository) CreateSubscription(ctx context.Context, sub *domain.WebhookSubscription) (*domain.WebhookSubscription, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	sub.ID = len(m.subs) + 1
+	m.subs = append(m.subs, sub)
+	return sub, nil
+}
+
+func (m *memoryRepository) GetSubscription(ctx context.Context, id int) (*domain.WebhookSubscription, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, sub := range m.subs {
+		if sub.ID == id {
+			return sub, nil
+		}
+	}
+	return nil, domain.ErrWebhookNotFound
+}
+
+func (m *memoryRepository) ListSubscriptions(ctx context.Context) ([]*domain.WebhookSubscription, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]*domain.WebhookSubscription(nil), m.subs...), nil
+}
+
+func (m *memoryRepository) UpdateSubscription(ctx context.Context, sub *domain.WebhookSubscription) (*domain.WebhookSubscription, error) {
+	return sub, nil
+}
+
+func (m *memoryRepository) DeleteSubscription(ctx context.Context, id int) error {
+	return nil
+}
+
+func (m *memoryRepository) EnqueueDeliveries(ctx context.Context, deliveries ...*domain.WebhookDelivery) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, d := range deliveries {
+		duplicate := false
+		for _, existing := range m.deliveries {
+			if existing.SubscriptionID == d.SubscriptionID && existing.EventID == d.EventID {
+				duplicate = true
+			}
+		}
+		if !duplicate {
+			d.ID = int64(len(m.deliveries) + 1)
+			d.Status = domain.DeliveryPending
+			d.NextAttemptAt = time.Now()
+			m.deliveries = append(m.deliveries, d)
+		}
+	}
+	return nil
+}
+
+// Claim ignores next_attempt_at so tests can drive retries without waiting
+func (m *memoryRepository) ClaimDueDeliveries(ctx context.Context, limit int, lease time.Duration) ([]*domain.WebhookDelivery, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.lease = lease
+	var due []*domain.WebhookDelivery
+	for _, d := range m.deliveries {
+		if d.Status == domain.DeliveryPending && len(due) < limit {
+			due = append(due, d)
+		}
+	}
+	return due, nil
+}
+
+func (m *memoryRepository) SaveDeliveryResult(ctx context.Context, delivery *domain.WebhookDelivery) error {
+	return nil
+}
+
+func (m *memoryRepository) ListDeliveries(ctx context.Context, filter domain.DeliveryFilter) ([]*domain.WebhookDelivery, error) {
+	return m.deliveries, nil
+}
+
+func (m *memoryRepository) RequeueDelivery(ctx context.Context, id int64) (*domain.WebhookDelivery, error) {
+	return nil, domain.ErrDeliveryNotFound
+}
+
+type received struct {
+	body      []byte
+	signature string
+	timestamp string
+	eventType string
+}
+
+func newReceiver(t *testing.T, statuses ...int) (*httptest.Server, chan received) {
+	t.Helper()
+	requests := make(chan received, 10)
+	var mu sync.Mutex
+	call := 0
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		requests <- received{
+			body:      body,
+			signature: r.Header.Get(webhook.SignatureHeader),
+			timestamp: r.Header.Get(webhook.TimestampHeader),
+			eventType: r.Header.Get("X-Event-Type"),
+		}
+
+		mu.Lock()
+		status := http.StatusOK
+		if call < len(statuses) {
+			status = statuses[call]
+		}
+		call++
+		mu.Unlock()
+
+		w.WriteHeader(status)
+	}))
+	t.Cleanup(server.Close)
+	return server, requests
+}
+
+func newDispatcher(repo domain.WebhookRepository) *webhook.Dispatcher {
+	return webhook.NewDispatcher(repo, webhook.Config{
+		Timeout:     time.Second,
+		MaxAttempts: 3,
+		MinBackoff:  time.Second,
+		MaxBackoff:  time.Minute,
+	}, logger.New("error"))
+}
+
+func TestDispatcher_DeliversSignedPayload(t *testing.T) {
+	server, requests := newReceiver(t)
+	repo := &memoryRepository{}
+	secret := "0123456789abcdef0123"
+	repo.CreateSubscription(context.Background(), &domain.WebhookSubscription{URL: server.URL, Secret: secret, Active: true})
+	dispatcher := newDispatcher(repo)
+
+	event := domain.NewQuoteEvent(domain.EventQuoteCreated, 1, &domain.Quote{ID: 1, Author: "Author"})
+	event.ID = 10
+	if err := dispatcher.Publish(context.Background(), event); err != nil {
+		t.Fatalf("Expected no error but got: %v", err)
+	}
+	// Повторная публикация того же события не дублирует доставку
+	dispatcher.Publish(context.Background(), event)
+	if len(repo.deliveries) != 1 {
+		t.Fatalf("Expected 1 delivery, got %d", len(repo.deliveries))
+	}
+
+	if err := dispatcher.RunOnce(context.Background()); err != nil {
+		t.Fatalf("Expected no error but got: %v", err)
+	}
+
+	req := <-requests
+	if req.eventType != string(domain.EventQuoteCreated) {
+		t.Errorf("Expected event type header, got %q", req.eventType)
+	}
+	if !webhook.Verify(secret, req.signature, req.timestamp, req.body, time.Minute) {
+		t.Errorf("Expected valid signature, got %q", req.signature)
+	}
+	if webhook.Verify("another-secret-value", req.signature, req.timestamp, req.body, time.Minute) {
+		t.Errorf("Expected signature check to fail with wrong secret")
+	}
+
+	delivery := repo.deliveries[0]
+	if delivery.Status != domain.DeliveryDelivered || delivery.ResponseStatus != http.StatusOK || delivery.DeliveredAt == nil {
+		t.Errorf("Expected delivered status, got %+v", delivery)
+	}
+}
+
+func TestDispatcher_RetriesThenDeadLetters(t *testing.T) {
+	server, requests := newReceiver(t, http.StatusInternalServerError, http.StatusBadGateway, http.StatusServiceUnavailable)
+	repo := &memoryRepository{}
+	repo.CreateSubscription(context.Background(), &domain.WebhookSubscription{URL: server.URL, Secret: "0123456789abcdef", Active: true})
+	dispatcher := newDispatcher(repo)
+
+	event := domain.NewQuoteEvent(domain.EventQuoteDeleted, 1, nil)
+	event.ID = 11
+	dispatcher.Publish(context.Background(), event)
+	delivery := repo.deliveries[0]
+
+	dispatcher.RunOnce(context.Background())
+	<-requests
+	if delivery.Status != domain.DeliveryPending || delivery.Attempts != 1 {
+		t.Fatalf("Expected pending retry after first failure, got %s (%d attempts)", delivery.Status, delivery.Attempts)
+	}
+	if delay := time.Until(delivery.NextAttemptAt); delay <= 0 || delay > time.Second {
+		t.Errorf("Expected retry within min backoff, got %v", delay)
+	}
+
+	dispatcher.RunOnce(context.Background())
+	<-requests
+	if delay := time.Until(delivery.NextAttemptAt); delay <= time.Second {
+		t.Errorf("Expected backoff to grow, got %v", delay)
+	}
+
+	dispatcher.RunOnce(context.Background())
+	<-requests
+	if delivery.Status != domain.DeliveryDead {
+		t.Errorf("Expected dead letter after max attempts, got %s", delivery.Status)
+	}
+	if delivery.ResponseStatus != http.StatusServiceUnavailable || delivery.LastError == "" {
+		t.Errorf("Expected last response to be recorded, got %d %q", delivery.ResponseStatus, delivery.LastError)
+	}
+}
+
+func TestDispatcher_FiltersByEventType(t *testing.T) {
+	repo := &memoryRepository{}
+	repo.CreateSubscription(context.Background(), &domain.WebhookSubscription{
+		URL:        "http://example.invalid",
+		Secret:     "0123456789abcdef",
+		EventTypes: []domain.EventType{domain.EventQuoteDeleted},
+		Active:     true,
+	})
+	repo.CreateSubscription(context.Background(), &domain.WebhookSubscription{
+		URL:    "http://example.invalid",
+		Secret: "0123456789abcdef",
+		Active: false,
+	})
+	dispatcher := newDispatcher(repo)
+
+	event := domain.NewQuoteEvent(domain.EventQuoteCreated, 1, &domain.Quote{ID: 1})
+	event.ID = 12
+	dispatcher.Publish(context.Background(), event)
+
+	if len(repo.deliveries) != 0 {
+		t.Errorf("Expected no deliveries, got %d", len(repo.deliveries))
+	}
+}
+
+func TestDispatcher_LeaseCoversWholeBatch(t *testing.T) {
+	repo := &memoryRepository{}
+	dispatcher := webhook.NewDispatcher(repo, webhook.Config{BatchSize: 50, Timeout: 10 * time.Second}, logger.New("error"))
+
+	if err := dispatcher.RunOnce(context.Background()); err != nil {
+		t.Fatalf("Expected no error but got: %v", err)
+	}
+	// 50 последовательных отправок по 10s не должны пережить аренду
+	if repo.lease < 500*time.Second {
+		t.Errorf("Expected lease to cover the batch, got %s", repo.lease)
+	}
+}
+
+func TestWebhookRequest_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		req     domain.WebhookRequest
+		wantErr bool
+	}{
+		{name: "valid", req: domain.WebhookRequest{URL: "https://hooks.example.com/quotes"}},
+		{name: "relative url", req: domain.WebhookRequest{URL: "/hooks"}, wantErr: true},
+		{name: "unsupported scheme", req: domain.WebhookRequest{URL: "ftp://example.com"}, wantErr: true},
+		{name: "short secret", req: domain.WebhookRequest{URL: "https://example.com", Secret: "short"}, wantErr: true},
+		{name: "unknown event", req: domain.WebhookRequest{URL: "https://example.com", Events: []domain.EventType{"quote.liked"}}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.req.Validate()
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Expected error: %v, got: %v", tt.wantErr, err)
+			}
+		})
+	}
+}